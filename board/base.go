@@ -0,0 +1,274 @@
+package board
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// RectBoard holds what every rectangular board has: its size, the dirty
+// flag that rules use to report progress, an optional Trace, the Trail
+// behind Checkpoint and Rollback and the Meta of the puzzle.
+type RectBoard struct {
+	W      int
+	H      int
+	Dirty  bool
+	Inited bool
+	Trace  *Trace
+	Trail  *Trail
+	Meta   Meta
+}
+
+// Meta says where a puzzle came from. The rules never look at it; it is
+// carried along through cloning and searching so that it can be written
+// out again with the solved board.
+type Meta struct {
+	Author string
+	Source string
+}
+
+// Metadata returns the board's Meta, for callers that only know the board
+// through an interface.
+func (b *RectBoard) Metadata() *Meta {
+	return &b.Meta
+}
+
+// Rect returns b itself, for callers that hold a board embedding it
+// through an interface.
+func (b *RectBoard) Rect() *RectBoard {
+	return b
+}
+
+// ContradictionError reports that a rule found the board in a state that no
+// solution can have: a cell that would need two values, a wing with no
+// possible length, a cell with no candidates left, and so on. On a puzzle
+// loaded from a file it means the puzzle has no solution; during a search
+// it means the current guess is wrong.
+type ContradictionError struct {
+	At     grid.Coord
+	Rule   string
+	Reason string
+}
+
+func (e *ContradictionError) Error() string {
+	return fmt.Sprintf("contradiction at %s in %s: %s", e.At, e.Rule, e.Reason)
+}
+
+// Contradiction builds a ContradictionError with a formatted reason.
+func Contradiction(at grid.Coord, rule string, format string, args ...any) *ContradictionError {
+	return &ContradictionError{
+		At:     at,
+		Rule:   rule,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// RectBinBoard is a rectangular board of binary cells. Grid holds the
+// settled values and Guess the hypotheses of a search; see the package
+// documentation.
+type RectBinBoard struct {
+	RectBoard
+	Grid  [][]grid.Cell
+	Guess [][]grid.Cell
+}
+
+// RectBinBoardFromLines returns an empty board as wide as the first line of
+// input and as tall as input.
+func RectBinBoardFromLines(input []string) *RectBinBoard {
+	return NewRectBinBoard(len(input[0]), len(input))
+}
+
+// NewRectBinBoard returns an empty w by h board.
+func NewRectBinBoard(w, h int) *RectBinBoard {
+	return &RectBinBoard{
+		RectBoard: RectBoard{
+			W: w,
+			H: h,
+		},
+		Grid:  grid.MakeGrid(w, h),
+		Guess: grid.MakeGrid(w, h),
+	}
+}
+
+func (b *RectBoard) TopLeft() grid.Coord {
+	return grid.Coord{X: 0, Y: 0}
+}
+
+// Next returns the cell after c in reading order. Together with TopLeft and
+// IsValid it makes a loop over every cell.
+func (b *RectBoard) Next(c grid.Coord) grid.Coord {
+	c.X++
+	if c.X == b.W {
+		c.X = 0
+		c.Y++
+	}
+	return c
+}
+
+func (b *RectBoard) IsValid(c grid.Coord) bool {
+	return c.X >= 0 && c.Y >= 0 && c.X < b.W && c.Y < b.H
+}
+
+// clone returns a copy of b that shares its Trace but not its Trail.
+func (b *RectBoard) clone() RectBoard {
+	out := *b
+	out.Trail = nil
+	return out
+}
+
+func (b *RectBoard) InitDone() bool {
+	return b.Inited
+}
+
+func (b *RectBoard) SetDirty() {
+	b.Dirty = true
+}
+
+func (b *RectBoard) ClearDirty() {
+	b.Dirty = false
+}
+
+func (b *RectBoard) IsDirty() bool {
+	return b.Dirty
+}
+
+// Get returns the value of c, looking at Guess before Grid.
+func (b *RectBinBoard) Get(c grid.Coord) grid.Cell {
+	if b.Guess[c.Y][c.X] != grid.UNKNOWN {
+		return b.Guess[c.Y][c.X]
+	}
+	return b.Grid[c.Y][c.X]
+}
+
+func (b *RectBinBoard) ClearGuess() {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Guess[c.Y][c.X] != grid.UNKNOWN {
+			b.write(b.Guess, c, grid.UNKNOWN)
+		}
+	}
+}
+
+// GuessValue places the hypothesis v for cell c in the Guess layer.
+func (b *RectBinBoard) GuessValue(c grid.Coord, v grid.Cell) {
+	b.write(b.Guess, c, v)
+}
+
+// CommitGuess moves every hypothesis in the Guess layer into Grid.
+func (b *RectBinBoard) CommitGuess() {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Guess[c.Y][c.X] != grid.UNKNOWN {
+			b.write(b.Grid, c, b.Guess[c.Y][c.X])
+			b.write(b.Guess, c, grid.UNKNOWN)
+		}
+	}
+}
+
+// write sets c to v in layer, which is Grid or Guess, and records how to
+// undo it.
+func (b *RectBinBoard) write(layer [][]grid.Cell, c grid.Coord, v grid.Cell) {
+	row, old := layer[c.Y], layer[c.Y][c.X]
+	b.OnRollback(func() { row[c.X] = old })
+	row[c.X] = v
+}
+
+// SearchStep summarizes a search that turned b into sol, which must still
+// hold its guesses.
+func (b *RectBinBoard) SearchStep(sol *RectBinBoard) Step {
+	step := Step{Rule: "Search", binary: true}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if sol.Guess[c.Y][c.X] != grid.UNKNOWN {
+			step.Cells = append(step.Cells, c)
+		}
+		if b.IsUnknown(c) && !sol.IsUnknown(c) {
+			step.Marked = append(step.Marked, CellValue{c, int(sol.Get(c))})
+		}
+	}
+	return step
+}
+
+// Clone returns a copy of the board whose Grid and Guess layers can be
+// modified without affecting the original. The copy starts with no open
+// checkpoints.
+func (b *RectBinBoard) Clone() *RectBinBoard {
+	return &RectBinBoard{
+		RectBoard: b.RectBoard.clone(),
+		Grid:      grid.CopyGrid(b.Grid),
+		Guess:     grid.CopyGrid(b.Guess),
+	}
+}
+
+func (b *RectBinBoard) IsPainted(c grid.Coord) bool {
+	return b.IsValid(c) && b.Get(c) == grid.PAINTED
+}
+
+func (b *RectBinBoard) IsClear(c grid.Coord) bool {
+	return b.IsValid(c) && b.Get(c) == grid.CLEAR
+}
+
+func (b *RectBinBoard) IsUnknown(c grid.Coord) bool {
+	return b.IsValid(c) && b.Get(c) == grid.UNKNOWN
+}
+
+// IsComplete reports whether every cell is known; if not, it also returns
+// the first unknown cell.
+func (b *RectBinBoard) IsComplete() (bool, grid.Coord) {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Get(c) == grid.UNKNOWN {
+			return false, c
+		}
+	}
+	return true, grid.Coord{}
+}
+
+// Set writes v to c without running any rules. Returns true iff c changed,
+// or a ContradictionError if c already holds the other value.
+func (b *RectBinBoard) Set(c grid.Coord, v grid.Cell) (bool, error) {
+	if !b.IsValid(c) {
+		return false, fmt.Errorf("coordinate (%d,%d) not valid on board of size (%d,%d)", c.X, c.Y, b.W, b.H)
+	}
+	if b.Get(c) == v {
+		return false, nil
+	}
+	if !b.IsUnknown(c) {
+		return false, Contradiction(c, "Mark", "cell is already %s; cannot make it %s", grid.CellName(b.Get(c)), grid.CellName(v))
+	}
+	b.write(b.Grid, c, v)
+	b.StepMarked(c, int(v))
+	return true, nil
+}
+
+// Cells returns the value of every cell as a grid of ints, for export.
+func (b *RectBinBoard) Cells() [][]int {
+	out := grid.MakeNumGrid(b.W, b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		out[c.Y][c.X] = int(b.Get(c))
+	}
+	return out
+}
+
+func (b *RectBinBoard) EachCell(cb func(c grid.Coord, v grid.Cell) bool) {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if cb(c, b.Get(c)) {
+			return
+		}
+	}
+}
+
+func (b *RectBinBoard) EachNeighbor(start grid.Coord, cb func(c grid.Coord, v grid.Cell) bool) {
+	for _, dir := range grid.DIRECTIONS {
+		c := start.Plus(dir)
+		if b.IsValid(c) {
+			cb(c, b.Get(c))
+		}
+	}
+}
+
+// SliceContains returns true iff the slice haystack contains the value needle.
+func SliceContains(haystack []int, needle int) bool {
+	for _, x := range haystack {
+		if x == needle {
+			return true
+		}
+	}
+	return false
+}
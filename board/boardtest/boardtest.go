@@ -0,0 +1,180 @@
+// Package boardtest checks the solvers of puzzle types against brute force,
+// for their tests.
+package boardtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Case is a puzzle for CheckBin or CheckNum, in the text format its
+// loader reads.
+type Case struct {
+	Name  string
+	Lines []string
+}
+
+// Binary is a puzzle type's board of binary cells as CountFillings sees
+// it; P is the board's own pointer type. Everything but Clone, IsSolved
+// and String comes from the board.RectBinBoard it embeds.
+type Binary[P any] interface {
+	Clone() P
+	IsSolved() (bool, error)
+	String() string
+	EachCell(cb func(c grid.Coord, v grid.Cell) bool)
+	Set(c grid.Coord, v grid.Cell) (bool, error)
+}
+
+// Numeric is a puzzle type's numeric board as CountNumFillings sees it.
+// Everything but Clone, IsSolved and String comes from the
+// board.RectNumBoard it embeds.
+type Numeric[P any] interface {
+	Clone() P
+	IsSolved() (bool, error)
+	String() string
+	EachCell(cb func(c grid.Coord, v int) bool)
+	Set(c grid.Coord, v int) (bool, error)
+	Allow(c grid.Coord, v int) bool
+	MaxRegionSize() int
+	Checkpoint()
+	Rollback() bool
+}
+
+// CountFillings counts the solutions of b by trying every way of painting
+// and clearing its unknown cells and asking IsSolved about each, without
+// running any rules. It takes time exponential in the number of unknown
+// cells, so it is only for small boards.
+func CountFillings[P Binary[P]](b P) int {
+	unknown := make([]grid.Coord, 0)
+	b.EachCell(func(c grid.Coord, v grid.Cell) bool {
+		if v == grid.UNKNOWN {
+			unknown = append(unknown, c)
+		}
+		return false
+	})
+	count := 0
+	for mask := 0; mask < 1<<len(unknown); mask++ {
+		g := b.Clone()
+		for i, c := range unknown {
+			var v grid.Cell = grid.CLEAR
+			if mask&(1<<i) != 0 {
+				v = grid.PAINTED
+			}
+			g.Set(c, v)
+		}
+		if ok, _ := g.IsSolved(); ok {
+			count++
+		}
+	}
+	return count
+}
+
+// CountNumFillings counts the solutions of b by trying every value from 1
+// to the size of its largest region in every empty cell and asking
+// IsSolved about each filling. The candidates the board has worked out are
+// ignored, so a rule that removes too much cannot hide solutions from it.
+// It takes time exponential in the number of empty cells, so it is only
+// for small boards.
+func CountNumFillings[P Numeric[P]](b P) int {
+	unknown := make([]grid.Coord, 0)
+	b.EachCell(func(c grid.Coord, v int) bool {
+		if v == grid.UNKNOWN {
+			unknown = append(unknown, c)
+		}
+		return false
+	})
+	top := b.MaxRegionSize()
+	values := make([]int, len(unknown))
+	for i := range values {
+		values[i] = 1
+	}
+	// Fillings are tried on one copy and rolled back, because cloning a
+	// board for each of them is too slow even for small boards.
+	g := b.Clone()
+	for _, c := range unknown {
+		for v := 1; v <= top; v++ {
+			g.Allow(c, v)
+		}
+	}
+	count := 0
+	for {
+		g.Checkpoint()
+		for i, c := range unknown {
+			g.Set(c, values[i])
+		}
+		if ok, _ := g.IsSolved(); ok {
+			count++
+		}
+		g.Rollback()
+		i := 0
+		for i < len(values) && values[i] == top {
+			values[i] = 1
+			i++
+		}
+		if i == len(values) {
+			return count
+		}
+		values[i]++
+	}
+}
+
+// CheckBin loads every case with load and checks that board.CountBin finds
+// as many solutions as CountFillings, and that board.SearchBin finds one
+// exactly when there is one and leaves the board solved.
+func CheckBin[B any, P interface {
+	*B
+	board.BinGuesser[P]
+	Binary[P]
+}](t *testing.T, load func([]string) (P, error), cases []Case) {
+	t.Helper()
+	check(t, load, cases, CountFillings[P], board.CountBin[P], board.SearchBin[B, P])
+}
+
+// CheckNum is CheckBin for numeric boards, with board.CountNum,
+// board.SearchNum and CountNumFillings.
+func CheckNum[B any, P interface {
+	*B
+	board.NumGuesser[P]
+	Numeric[P]
+}](t *testing.T, load func([]string) (P, error), cases []Case) {
+	t.Helper()
+	check(t, load, cases, CountNumFillings[P], board.CountNum[P], board.SearchNum[B, P])
+}
+
+func check[P interface {
+	Clone() P
+	IsSolved() (bool, error)
+	String() string
+}](t *testing.T, load func([]string) (P, error), cases []Case, brute func(P) int, count func(context.Context, P, int) (int, []P, error), search func(context.Context, P) (bool, error)) {
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			b, err := load(tt.Lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := brute(b)
+			n, witnesses, err := count(context.Background(), b, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != want {
+				t.Errorf("CountSolutions found %d solutions; brute force finds %d", n, want)
+			}
+			for _, w := range witnesses {
+				if solved, err := w.IsSolved(); !solved {
+					t.Errorf("witness is not a solution: %v\n%s", err, w)
+				}
+			}
+			ok, err := search(context.Background(), b)
+			if ok != (want > 0) {
+				t.Fatalf("Search returned %v (%v); brute force finds %d solutions", ok, err, want)
+			}
+			if solved, err := b.IsSolved(); ok && !solved {
+				t.Errorf("Search left an unsolved board: %v\n%s", err, b)
+			}
+		})
+	}
+}
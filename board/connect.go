@@ -0,0 +1,371 @@
+package board
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/set"
+)
+
+// Flood returns the cells that can be reached from start by orthogonal
+// steps through cells whose value passes, start included.
+func (b *RectBinBoard) Flood(start grid.Coord, passes func(grid.Cell) bool) *set.Set[grid.Coord] {
+	reached := grid.NewCoordSet()
+	reached.Add(start)
+	frontier := []grid.Coord{start}
+	for len(frontier) > 0 {
+		touch := frontier[0]
+		frontier = frontier[1:]
+		b.EachNeighbor(touch, func(n grid.Coord, nv grid.Cell) bool {
+			if passes(nv) && !reached.Has(n) {
+				reached.Add(n)
+				frontier = append(frontier, n)
+			}
+			return false
+		})
+	}
+	return reached
+}
+
+// Dominators returns, for every cell that Flood reaches from start through
+// cells whose value passes, the cells that every such path from start to it
+// goes through, itself and start included. Cells Flood does not reach get
+// nil.
+//
+// In graph theory, a node D dominates a sink S with respect to an origin O
+// if every path from O to S includes D. Puzzles whose cells of one value
+// must form a single connected group use this to find choke points: if a
+// cell that must take that value is dominated by an unknown cell, the
+// unknown cell must take it too. This is the first algorithm shown here:
+// https://en.wikipedia.org/wiki/Dominator_(graph_theory)#Algorithms
+func (b *RectBinBoard) Dominators(start grid.Coord, passes func(grid.Cell) bool) [][]*set.Set[grid.Coord] {
+	reached := b.Flood(start, passes)
+	doms := make([][]*set.Set[grid.Coord], 0)
+	for y := 0; y < b.H; y++ {
+		doms = append(doms, make([]*set.Set[grid.Coord], b.W))
+		for x := 0; x < b.W; x++ {
+			if reached.Has(grid.Coord{X: x, Y: y}) {
+				doms[y][x] = grid.NewCoordSet()
+			}
+		}
+	}
+	doms[start.Y][start.X].Add(start)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if reached.Has(c) && c != start {
+			doms[c.Y][c.X].AddAll(reached)
+		}
+	}
+	changed := true
+	for changed {
+		changed = false
+		for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+			if !reached.Has(c) || c == start {
+				continue
+			}
+			// Every reached cell other than start has a reached neighbor.
+			var newDoms *set.Set[grid.Coord]
+			b.EachNeighbor(c, func(n grid.Coord, nv grid.Cell) bool {
+				if reached.Has(n) {
+					if newDoms == nil {
+						newDoms = doms[n.Y][n.X].Copy()
+					} else {
+						newDoms.IntersectWith(doms[n.Y][n.X])
+					}
+				}
+				return false
+			})
+			newDoms.Add(c)
+			if newDoms.Size() != doms[c.Y][c.X].Size() {
+				doms[c.Y][c.X] = newDoms
+				changed = true
+			}
+		}
+	}
+	return doms
+}
+
+// Liberties returns the number of neighbors of c whose value passes, and
+// the last of them.
+func (b *RectBinBoard) Liberties(c grid.Coord, passes func(grid.Cell) bool) (int, grid.Coord) {
+	var lib grid.Coord
+	liberties := 0
+	b.EachNeighbor(c, func(n grid.Coord, nv grid.Cell) bool {
+		if passes(nv) {
+			liberties++
+			lib = n
+		}
+		return false
+	})
+	return liberties, lib
+}
+
+// NotPainted passes the cells that may still turn out clear, for Flood,
+// Dominators and Liberties.
+func NotPainted(v grid.Cell) bool {
+	return v != grid.PAINTED
+}
+
+// NotClear passes the cells that may still turn out painted.
+func NotClear(v grid.Cell) bool {
+	return v != grid.CLEAR
+}
+
+// The rules below are shared by the puzzles whose clear cells must form a
+// single connected group and whose painted cells may not touch, such as
+// kuromasu, hitori and heyawake. Each takes the puzzle type's Mark, so that
+// whatever the type does after a mark still runs, and records its steps
+// under its own name.
+
+// MarkFunc writes a value to a cell and runs the rules that follow from it,
+// as a puzzle type's Mark does.
+type MarkFunc func(c grid.Coord, v grid.Cell) (bool, error)
+
+// ClearConnected returns a contradiction, on behalf of Validate, if some
+// clear cell can no longer be joined to the first one through cells that
+// are not painted.
+func (b *RectBinBoard) ClearConnected() error {
+	start, found := b.firstWith(grid.CLEAR)
+	if !found {
+		return nil
+	}
+	reached := b.Flood(start, NotPainted)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.IsClear(c) && !reached.Has(c) {
+			return Contradiction(c, "Validate", "clear cell is cut off from clear cell %s", start)
+		}
+	}
+	return nil
+}
+
+// firstWith returns the first cell in reading order whose value is v.
+func (b *RectBinBoard) firstWith(v grid.Cell) (grid.Coord, bool) {
+	var at grid.Coord
+	found := false
+	b.EachCell(func(c grid.Coord, cv grid.Cell) bool {
+		if cv == v {
+			at, found = c, true
+		}
+		return found
+	})
+	return at, found
+}
+
+// ClearPaintedNeighbors marks every unknown neighbor of a painted cell as
+// clear. A puzzle type whose PostMark already does this only finds work
+// here on boards whose cells were filled in without it, such as a
+// partially solved state loaded for a hint.
+func (b *RectBinBoard) ClearPaintedNeighbors(mark MarkFunc) error {
+	var err error
+	b.EachCell(func(c grid.Coord, v grid.Cell) bool {
+		if v != grid.PAINTED {
+			return false
+		}
+		b.EachNeighbor(c, func(n grid.Coord, nv grid.Cell) bool {
+			if nv == grid.UNKNOWN && err == nil {
+				b.BeginStep("ClearPaintedNeighbors", c)
+				_, err = mark(n, grid.CLEAR)
+				b.EndStep()
+			}
+			return false
+		})
+		return err != nil
+	})
+	return err
+}
+
+// ClearMiniDominators looks for clear cells with one liberty and marks the
+// liberty as clear. Limited case of ClearAllDominators below.
+func (b *RectBinBoard) ClearMiniDominators(mark MarkFunc) error {
+	var err error
+	b.EachCell(func(c grid.Coord, v grid.Cell) bool {
+		if v != grid.CLEAR {
+			return false
+		}
+		liberties, lib := b.Liberties(c, NotPainted)
+		if liberties == 1 {
+			b.BeginStep("ClearMiniDominators", c)
+			_, err = mark(lib, grid.CLEAR)
+			b.EndStep()
+		} else if liberties == 0 && b.hasOtherClear(c) {
+			err = Contradiction(c, "ClearMiniDominators", "clear cell is walled in by painted cells")
+		}
+		return err != nil
+	})
+	return err
+}
+
+// hasOtherClear returns true iff some clear cell other than c exists.
+func (b *RectBinBoard) hasOtherClear(c grid.Coord) bool {
+	found := false
+	b.EachCell(func(o grid.Coord, v grid.Cell) bool {
+		found = v == grid.CLEAR && o != c
+		return found
+	})
+	return found
+}
+
+// ClearAllDominators marks as clear every unknown cell that dominates
+// another cell as seen from start, a clear cell.
+//
+// Consider a graph whose nodes are non-painted (i.e., clear and unknown)
+// cells and whose edges are orthogonal adjacency relationships. If D
+// dominates ANY other node for ANY origin (see Dominators), then D is a
+// "choke point" that must be clear in order for the board to satisfy the
+// constraint that the clear cells must form a contiguous group, since
+// painted cells can't touch and so can't fill the pocket D would cut off.
+// Note that we have to call this function twice. By definition, a node
+// dominates itself, so by calling the function with two different source
+// nodes, we are sure to find every dominator.
+func (b *RectBinBoard) ClearAllDominators(start grid.Coord, mark MarkFunc) error {
+	// Every unpainted cell must be reachable from start. A cell that isn't
+	// can be neither clear (it would be cut off) nor painted along with the
+	// rest of its pocket (painted cells can't touch), so the board is
+	// contradictory. Checking first also guarantees below that every cell
+	// other than start has an unpainted neighbor.
+	reached := b.Flood(start, NotPainted)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsPainted(c) && !reached.Has(c) {
+			return Contradiction(c, "ClearAllDominators", "cell is cut off from clear cell %s", start)
+		}
+	}
+
+	doms := b.Dominators(start, NotPainted)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if doms[c.Y][c.X] == nil || doms[c.Y][c.X].Size() < 3 {
+			continue
+		}
+		for _, k := range doms[c.Y][c.X].Sorted() {
+			if k != start && k != c && b.IsUnknown(k) {
+				b.BeginStep("ClearAllDominators", start, c)
+				_, err := mark(k, grid.CLEAR)
+				b.EndStep()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ClearDominators runs ClearAllDominators from the first two clear cells on
+// the board; see the note on ClearAllDominators for why two are needed.
+func (b *RectBinBoard) ClearDominators(mark MarkFunc) error {
+	done := 0
+	for c := b.TopLeft(); b.IsValid(c) && done < 2; c = b.Next(c) {
+		if b.IsClear(c) {
+			if err := b.ClearAllDominators(c, mark); err != nil {
+				return err
+			}
+			done++
+		}
+	}
+	return nil
+}
+
+// CellVars adds to f a variable named name(x,y) for every cell, true when
+// the cell is painted, and a unit clause for every cell already known. It
+// returns the variables by cell, for the rest of the encoding and for
+// SetFromModel.
+func (b *RectBinBoard) CellVars(f *cnf.Formula, name string) [][]int {
+	vars := grid.MakeNumGrid(b.W, b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		v := f.NewVar(fmt.Sprintf("%s(%d,%d)", name, c.X, c.Y))
+		vars[c.Y][c.X] = v
+		switch b.Get(c) {
+		case grid.PAINTED:
+			f.Add(v)
+		case grid.CLEAR:
+			f.Add(-v)
+		}
+	}
+	return vars
+}
+
+// SetFromModel fills in every cell from a model of a formula whose cell
+// variables CellVars returned: painted where the variable is true and
+// clear elsewhere. No rules run.
+func (b *RectBinBoard) SetFromModel(m cnf.Model, vars [][]int) error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		var v grid.Cell = grid.CLEAR
+		if m.Value(vars[c.Y][c.X]) {
+			v = grid.PAINTED
+		}
+		if _, err := b.Set(c, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeClearConnected adds to f the clauses that make the clear cells
+// connect, given the painted variable of every cell, as bounded
+// reachability: reach(c, k) may only be true if c is clear and c or a
+// neighbor had reach(., k-1), and every clear cell needs reach(c, W*H-1).
+// The formula therefore grows with the square of the number of cells n:
+// one variable and two clauses per cell and step, less the cells too far
+// from the root to reach in that many steps, or at most n*n variables and
+// 2*n*n clauses. A 30 by 30 board takes about 800,000 variables and 1.6
+// million clauses. Encodings that grow more slowly, such as a spanning
+// tree with binary ranks, leave the solver to search for a ranking, while
+// this one needs no search once the painted cells are known.
+//
+// Reaching starts from the first clear cell if there is one. Otherwise it
+// starts from the top left cell or, if that is painted, from its neighbor,
+// which then cannot be, since painted cells do not touch.
+func (b *RectBinBoard) EncodeClearConnected(f *cnf.Formula, painted [][]int) {
+	p := func(c grid.Coord) int {
+		return painted[c.Y][c.X]
+	}
+	// Where the clear cells start: a literal for each cell that says it is
+	// the root.
+	roots := make(map[grid.Coord]int)
+	if c, ok := b.firstWith(grid.CLEAR); ok {
+		roots[c] = f.True()
+	} else {
+		first := b.TopLeft()
+		roots[first] = -p(first)
+		if second := first.Plus(grid.RIGHT); b.IsValid(second) {
+			roots[second] = p(first)
+		} else if second := first.Plus(grid.DOWN); b.IsValid(second) {
+			roots[second] = p(first)
+		}
+	}
+	reach := grid.MakeNumGrid(b.W, b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		reach[c.Y][c.X] = -f.True()
+		if l, ok := roots[c]; ok {
+			reach[c.Y][c.X] = l
+		}
+	}
+	for k := 1; k < b.W*b.H; k++ {
+		next := grid.MakeNumGrid(b.W, b.H)
+		for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+			// Nothing further than k steps from every root can be reached
+			// in k.
+			far := true
+			for r := range roots {
+				far = far && c.MHDist(r) > k
+			}
+			if far {
+				next[c.Y][c.X] = -f.True()
+				continue
+			}
+			z := f.NewVar("")
+			f.Add(-z, -p(c))
+			from := []int{-z, reach[c.Y][c.X]}
+			for _, dir := range grid.DIRECTIONS {
+				if n := c.Plus(dir); b.IsValid(n) {
+					from = append(from, reach[n.Y][n.X])
+				}
+			}
+			f.Add(from...)
+			next[c.Y][c.X] = z
+		}
+		reach = next
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		f.Add(p(c), reach[c.Y][c.X])
+	}
+}
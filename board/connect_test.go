@@ -0,0 +1,130 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// encodeConnected returns the formula EncodeClearConnected builds for an
+// empty w by h board, its painted variables and how many variables and
+// clauses the encoding itself added.
+func encodeConnected(w, h int) (*cnf.Formula, [][]int, int, int) {
+	b := NewRectBinBoard(w, h)
+	f := cnf.New()
+	painted := grid.MakeNumGrid(w, h)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		painted[c.Y][c.X] = f.NewVar("")
+	}
+	vars, clauses := f.NumVars, len(f.Clauses)
+	b.EncodeClearConnected(f, painted)
+	return f, painted, f.NumVars - vars, len(f.Clauses) - clauses
+}
+
+// connectedModel reports whether the formula has a model with the cells in
+// paint painted and the rest clear. With every painted variable fixed,
+// each clause EncodeClearConnected adds has at most one negative literal
+// left open, so the formula holds iff its greatest model does: start with
+// every open variable true and make one false only when a clause forces it.
+func connectedModel(f *cnf.Formula, painted [][]int, paint [][]bool) bool {
+	val := make([]bool, f.NumVars+1)
+	fixed := make([]bool, f.NumVars+1)
+	for v := range val {
+		val[v] = true
+	}
+	for y, row := range painted {
+		for x, v := range row {
+			val[v], fixed[v] = paint[y][x], true
+		}
+	}
+	holds := func(l int) bool {
+		if l < 0 {
+			return !val[-l]
+		}
+		return val[l]
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, clause := range f.Clauses {
+			sat := false
+			for _, l := range clause {
+				sat = sat || holds(l)
+			}
+			if sat {
+				continue
+			}
+			forced := false
+			for _, l := range clause {
+				if l < 0 && !fixed[-l] {
+					val[-l], forced, changed = false, true, true
+					break
+				}
+			}
+			if !forced {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// snake paints an n by n board so that the clear cells form one path that
+// winds through every other row, as long a path as a board of that size
+// has room for.
+func snake(n int) [][]bool {
+	paint := make([][]bool, n)
+	for y := range paint {
+		paint[y] = make([]bool, n)
+		if y%2 == 0 {
+			continue
+		}
+		for x := range paint[y] {
+			paint[y][x] = true
+		}
+		if y%4 == 1 {
+			paint[y][n-1] = false
+		} else {
+			paint[y][0] = false
+		}
+	}
+	return paint
+}
+
+func TestEncodeClearConnected(t *testing.T) {
+	const n = 12
+	f, painted, _, _ := encodeConnected(n, n)
+	for _, tt := range []struct {
+		name  string
+		paint func([][]bool)
+		want  bool
+	}{
+		{"snake", func([][]bool) {}, true},
+		{"root painted", func(p [][]bool) { p[0][0] = true }, true},
+		{"cut", func(p [][]bool) { p[n-3][n-1] = true }, false},
+		{"cut near the root", func(p [][]bool) { p[1][n-1] = true }, false},
+		{"island", func(p [][]bool) { p[n-2][4], p[n-2][6] = true, true }, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			paint := snake(n)
+			tt.paint(paint)
+			if got := connectedModel(f, painted, paint); got != tt.want {
+				t.Errorf("formula holds: %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEncodeClearConnectedSize checks the size of the formula on a large
+// board against the bound in EncodeClearConnected's doc comment.
+func TestEncodeClearConnectedSize(t *testing.T) {
+	const w, h = 30, 30
+	_, _, vars, clauses := encodeConnected(w, h)
+	n := w * h
+	if vars > n*n || clauses > 2*n*n {
+		t.Errorf("%d by %d board takes %d variables and %d clauses; want at most %d and %d", w, h, vars, clauses, n*n, 2*n*n)
+	}
+	if vars < n*n/2 {
+		t.Errorf("%d by %d board takes only %d variables; update the doc comment", w, h, vars)
+	}
+}
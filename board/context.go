@@ -0,0 +1,22 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTimedOut is wrapped by the error a SolveContext or SearchContext
+// returns when its context is done before it finishes. The board is left
+// holding every deduction made up to that point.
+var ErrTimedOut = errors.New("timed out")
+
+// Stopped returns an error wrapping ErrTimedOut and the context's own error
+// if ctx is done, or nil if it is not. Solve loops call it between one rule
+// and the next.
+func Stopped(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrTimedOut, err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Package board provides the rectangular boards that puzzle types are built
+// on, RectBinBoard for painted/clear puzzles and RectNumBoard for puzzles
+// that fill cells with numbers, together with what the puzzle types share:
+// contradiction errors, step traces, technique ratings and hints.
+//
+// Both boards keep a Guess layer on top of Grid, and Get reads Guess first.
+// Puzzle types use it for a depth-first backtracking search layered on top
+// of their Solve. When the rules stall, the search picks an unknown cell,
+// clones the board, writes a hypothesis for that cell into the clone's Guess
+// layer and runs the rules again. A hypothesis that leads to a contradiction
+// is abandoned and the next one is tried. Deductions made while a guess is
+// in place live only on the clone, so the original board is never
+// corrupted; once a clone reaches a solution its guesses are committed to
+// Grid and it replaces the original. Every branch of the search tree fixes
+// at least one cell differently from its siblings, so the solutions it
+// visits are always distinct, which is what makes counting solutions exact.
+//
+// The search explores guesses on clones with tracing turned off, so dead
+// ends never show up in the trace. Once a solution is found, the whole
+// search is summarized as a single "Search" step (see SearchStep) whose
+// cells are the guesses that held and whose marks are every cell the search
+// filled in.
+//
+// A caller that wants to try a hypothesis without cloning can instead open a
+// Checkpoint, change the board in place, and Rollback. Every write to the
+// Grid, Guess and Allowed layers, and to the extra state a puzzle type keeps
+// (wing ranges, permutation lists), is recorded on the board's Trail while a
+// checkpoint is open. Clones start with an empty Trail, so a clone and its
+// original never undo each other's changes.
+package board
@@ -0,0 +1,283 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Hint is a single deduction offered to someone solving a puzzle by hand:
+// a cell, the value it must take (or, for numeric boards, a candidate it
+// cannot take), the rule that found it and a sentence explaining why.
+type Hint struct {
+	Rule   string
+	At     grid.Coord
+	Value  int
+	Remove bool
+	Binary bool
+	Reason string
+}
+
+func (h *Hint) String() string {
+	var claim string
+	if h.Binary {
+		claim = fmt.Sprintf("must be %s", grid.CellName(grid.Cell(h.Value)))
+	} else if h.Remove {
+		claim = fmt.Sprintf("cannot be %d", h.Value)
+	} else {
+		claim = fmt.Sprintf("must be %d", h.Value)
+	}
+	return fmt.Sprintf("%s %s: %s [%s]", h.At, claim, h.Reason, h.Rule)
+}
+
+// FindHint applies techs cheapest-first, starting over from the cheapest
+// after every success, until a step recorded on b yields a hint through
+// pick. Returns nil if the techniques run dry first, or an error if one of
+// them finds a contradiction. b must be tracing, and since the techniques
+// change it, it should be a scratch copy.
+func FindHint(b *RectBoard, techs []Technique, pick func(Step) *Hint) (*Hint, error) {
+	for {
+		start := len(b.Steps())
+		progressed := false
+		for _, t := range techs {
+			ok, err := t.Apply()
+			if err != nil {
+				return nil, fmt.Errorf("state has no solution: %w", err)
+			}
+			if !ok {
+				continue
+			}
+			for _, s := range b.Steps()[start:] {
+				if h := pick(s); h != nil {
+					return h, nil
+				}
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, nil
+		}
+	}
+}
+
+// BinHinter is a board of painted and clear cells that HintBin can find
+// hints on. Techniques and Explain, which describes why a step made a
+// mark, are the puzzle type's own; the rest comes from the RectBinBoard it
+// embeds.
+type BinHinter[P any] interface {
+	Clone() P
+	Validate() error
+	Techniques() []Technique
+	Explain(s Step, m CellValue) string
+	binBoard() *RectBinBoard
+}
+
+func (b *RectBinBoard) binBoard() *RectBinBoard {
+	return b
+}
+
+// HintBin returns the cheapest deduction that can be made from the current
+// state of b, a board of painted and clear cells, or nil if none can be
+// made without guessing: the first mark, made by b's techniques on a copy
+// of b, that concerns a cell still unknown on b. b is not changed.
+func HintBin[P BinHinter[P]](b P) (*Hint, error) {
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("state breaks a rule: %w", err)
+	}
+	orig, g := b.binBoard(), b.Clone()
+	g.binBoard().EnableTrace()
+	return FindHint(&g.binBoard().RectBoard, g.Techniques(), func(s Step) *Hint {
+		for _, m := range s.Marked {
+			if orig.IsUnknown(m.At) {
+				return &Hint{
+					Rule:   s.Rule,
+					At:     m.At,
+					Value:  m.Value,
+					Binary: true,
+					Reason: b.Explain(s, m),
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// NumHinter is a numeric board that HintNum can find hints on. Techniques
+// is the puzzle type's own; the rest comes from the RectNumBoard it
+// embeds, whose Explain describes the steps.
+type NumHinter[P any] interface {
+	Clone() P
+	Validate() error
+	Techniques() []Technique
+	numBoard() *RectNumBoard
+}
+
+func (b *RectNumBoard) numBoard() *RectNumBoard {
+	return b
+}
+
+// HintNum is HintBin for a numeric board, whose hint may also be a
+// candidate a cell cannot take; see NumHint.
+func HintNum[P NumHinter[P]](b P) (*Hint, error) {
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("state breaks a rule: %w", err)
+	}
+	g := b.Clone()
+	g.numBoard().EnableTrace()
+	return FindHint(&g.numBoard().RectBoard, g.Techniques(), func(s Step) *Hint {
+		return NumHint(b.numBoard(), s, g.numBoard().Explain)
+	})
+}
+
+// ApplyState fills in the cells of a partially solved state: X or # for a
+// painted cell, · or . for a clear one; anything else, such as a number
+// printed in the cell, leaves the cell as it is. The cells are set without
+// running any rules, so that a hint starts from exactly what the solver
+// has written down.
+func (b *RectBinBoard) ApplyState(lines []string) error {
+	lines = StateLines(lines)
+	if len(lines) != b.H {
+		return fmt.Errorf("state has %d rows; puzzle has %d", len(lines), b.H)
+	}
+	for y, l := range lines {
+		row := []rune(l)
+		if len(row) > b.W {
+			return fmt.Errorf("state row %d has %d cells; puzzle has %d", y, len(row), b.W)
+		}
+		for x, ch := range row {
+			var v grid.Cell
+			switch ch {
+			case 'X', 'x', '#':
+				v = grid.PAINTED
+			case '·', '.':
+				v = grid.CLEAR
+			default:
+				continue
+			}
+			if _, err := b.Set(grid.Coord{X: x, Y: y}, v); err != nil {
+				return err
+			}
+		}
+	}
+	b.SetDirty()
+	return nil
+}
+
+// StateLines strips the border that String draws around a board, so that a
+// board printed by the solver can be fed back in as a state.
+func StateLines(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if strings.HasPrefix(l, "+") {
+			continue
+		}
+		out = append(out, strings.TrimSuffix(strings.TrimPrefix(l, "|"), "|"))
+	}
+	return out
+}
+
+// ParseNumState reads a partially solved numeric state of size w by h.
+// Digits and letters are values as in CharToNum; anything else is an empty
+// cell. A tokenized grid (see grid.IsTokenGrid) works too.
+func ParseNumState(lines []string, w, h int) ([][]int, error) {
+	lines = StateLines(lines)
+	if len(lines) != h {
+		return nil, fmt.Errorf("state has %d rows; puzzle has %d", len(lines), h)
+	}
+	if grid.IsTokenGrid(lines) {
+		nums, err := grid.TokensToIntGrid(lines)
+		if err != nil {
+			return nil, err
+		}
+		for y, row := range nums {
+			if len(row) != w {
+				return nil, fmt.Errorf("state row %d has %d cells; puzzle has %d", y, len(row), w)
+			}
+		}
+		return nums, nil
+	}
+	nums := grid.MakeNumGrid(w, h)
+	for y, l := range lines {
+		row := []rune(l)
+		if len(row) > w {
+			return nil, fmt.Errorf("state row %d has %d cells; puzzle has %d", y, len(row), w)
+		}
+		for x, ch := range row {
+			if n, ok := grid.CharToNum(ch); ok {
+				nums[y][x] = n
+			}
+		}
+	}
+	return nums, nil
+}
+
+// ApplyNumState fills in the values of a partially solved state on b, a
+// numeric board, and removes them from the candidates of the cells they
+// rule out through b's PostMark, the way anyone keeping pencil marks
+// would.
+func ApplyNumState[P interface {
+	PostMark(c grid.Coord, v int) (bool, error)
+	numBoard() *RectNumBoard
+}](b P, lines []string) error {
+	n := b.numBoard()
+	nums, err := ParseNumState(lines, n.W, n.H)
+	if err != nil {
+		return err
+	}
+	for c := n.TopLeft(); n.IsValid(c); c = n.Next(c) {
+		v := nums[c.Y][c.X]
+		if v == grid.UNKNOWN {
+			continue
+		}
+		if _, err := n.Set(c, v); err != nil {
+			return err
+		}
+		n.AllowOnly(c, v)
+		if _, err := b.PostMark(c, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NumHint picks the first mark in s, or failing that the first candidate
+// removal, that concerns a cell still empty on orig.
+func NumHint(orig *RectNumBoard, s Step, explain func(Step, CellValue, bool) string) *Hint {
+	for _, m := range s.Marked {
+		if orig.IsUnknown(m.At) {
+			return &Hint{Rule: s.Rule, At: m.At, Value: m.Value, Reason: explain(s, m, false)}
+		}
+	}
+	for _, r := range s.Removed {
+		if orig.IsUnknown(r.At) && orig.IsAllowed(r.At, r.Value) {
+			return &Hint{Rule: s.Rule, At: r.At, Value: r.Value, Remove: true, Reason: explain(s, r, true)}
+		}
+	}
+	return nil
+}
+
+// Explain describes why step s made mark m (or, if remove is set, removed
+// candidate m).
+func (b *RectNumBoard) Explain(s Step, m CellValue, remove bool) string {
+	cells := make([]string, 0, len(s.Cells))
+	for _, c := range s.Cells {
+		cells = append(cells, c.String())
+	}
+	switch s.Rule {
+	case "MarkMandatory":
+		if !remove {
+			return "it is the only candidate left"
+		}
+		return fmt.Sprintf("%s must be %d, and shares a region with it", s.Cells[0], b.Get(s.Cells[0]))
+	case "TrimNakedSets":
+		vals := b.Allowed[s.Cells[0].Y][s.Cells[0].X].Sorted()
+		return fmt.Sprintf("cells %s form a naked set %v, so no other cell in their region can hold those values", strings.Join(cells, " "), vals)
+	case "TrimFoundGroups":
+		return fmt.Sprintf("the values in cells %s can go nowhere else in their region, so those cells hold nothing else", strings.Join(cells, " "))
+	case "TrimAllowedFromPerms":
+		return "no arrangement of its row and column that satisfies the observers puts it there"
+	}
+	return fmt.Sprintf("follows from %s at %s", s.Rule, strings.Join(cells, " "))
+}
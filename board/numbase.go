@@ -0,0 +1,799 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/perms"
+	"github.com/bismuthsalamander/mutantcheckerboard/set"
+)
+
+// RectNumBoard is a rectangular board of numbers in which each region holds
+// every value at most once. Allowed holds the candidates left for each cell,
+// and Guess plays the same part as in RectBinBoard.
+type RectNumBoard struct {
+	RectBoard
+	Grid       [][]int
+	AllRegions []*[]grid.Coord
+	RegionGrid [][][]*[]grid.Coord
+	Allowed    [][]*set.Set[int]
+	Guess      [][]int
+}
+
+// RectNumBoardFromNums returns a board holding the givens in input, with
+// one region for each row and each column. The caller fills in Allowed.
+func RectNumBoardFromNums(input [][]int) *RectNumBoard {
+	w := len(input[0])
+	h := len(input)
+	board := &RectNumBoard{
+		RectBoard: RectBoard{
+			W: w,
+			H: h,
+		},
+		Grid:       grid.MakeNumGrid(w, h),
+		AllRegions: make([]*[]grid.Coord, 0),
+		RegionGrid: MakeRegionGrid(w, h),
+		Guess:      grid.MakeNumGrid(w, h),
+	}
+	board.AddRowColRegions()
+	for r, row := range input {
+		copy(board.Grid[r], row)
+	}
+	return board
+}
+
+// LinesToRegionGrid reads a map of regions in which every character names
+// the region its cell belongs to. Returns the regions, and for each cell the
+// regions it is in.
+func LinesToRegionGrid(input []string) ([]*[]grid.Coord, [][][]*[]grid.Coord) {
+	rows := make([][]rune, len(input))
+	for y, row := range input {
+		rows[y] = []rune(row)
+	}
+	return regionGridFrom(rows)
+}
+
+// IDsToRegionGrid is LinesToRegionGrid for a map of regions written as
+// numbers.
+func IDsToRegionGrid(ids [][]int) ([]*[]grid.Coord, [][][]*[]grid.Coord) {
+	return regionGridFrom(ids)
+}
+
+// IDsToLines is the inverse of LinesToRegionGrid for a map of regions
+// written as numbers from 0, as IDsToRegionGrid reads: it names region i
+// by the i-th of a to z, A to Z and the letters after them in Unicode.
+func IDsToLines(ids [][]int) []string {
+	lines := make([]string, len(ids))
+	for y, row := range ids {
+		names := make([]rune, len(row))
+		for x, id := range row {
+			switch {
+			case id < 26:
+				names[x] = 'a' + rune(id)
+			case id < 52:
+				names[x] = 'A' + rune(id-26)
+			default:
+				names[x] = 'À' + rune(id-52)
+			}
+		}
+		lines[y] = string(names)
+	}
+	return lines
+}
+
+// SplitRegion reports whether region r of regionGrid, as from
+// LinesToRegionGrid, is in more than one piece, by flooding it from its
+// first cell. If it is, cut is a cell the flood did not reach and from is
+// the first cell.
+func SplitRegion(r []grid.Coord, regionGrid [][][]*[]grid.Coord) (cut grid.Coord, from grid.Coord, split bool) {
+	from = r[0]
+	own := regionGrid[from.Y][from.X][0]
+	reached := grid.NewCoordSet()
+	reached.Add(from)
+	frontier := []grid.Coord{from}
+	for len(frontier) > 0 {
+		c := frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+		for _, dir := range grid.DIRECTIONS {
+			n := c.Plus(dir)
+			if n.Y < 0 || n.Y >= len(regionGrid) || n.X < 0 || n.X >= len(regionGrid[n.Y]) {
+				continue
+			}
+			if regionGrid[n.Y][n.X][0] == own && !reached.Has(n) {
+				reached.Add(n)
+				frontier = append(frontier, n)
+			}
+		}
+	}
+	for _, c := range r {
+		if !reached.Has(c) {
+			return c, from, true
+		}
+	}
+	return grid.Coord{}, from, false
+}
+
+// IsBlank reports whether ch stands for an empty cell in a text grid, and
+// so names no region in a map.
+func IsBlank(ch rune) bool {
+	return ch == ' ' || ch == '.' || ch == '_'
+}
+
+// SplitAtSeparator splits the lines of a text puzzle in two at the first
+// empty line or line of dashes, and returns how many lines come before the
+// second part. ok is false if there is no such line.
+func SplitAtSeparator(input []string) ([]string, []string, int, bool) {
+	for i, l := range input {
+		if len(l) == 0 || strings.Trim(l, "-") == "" {
+			return input[:i], input[i+1:], i + 1, true
+		}
+	}
+	return nil, nil, 0, false
+}
+
+// SplitSections is SplitAtSeparator for puzzles written as a map followed
+// by a grid of the same height: without a separator, it halves the lines.
+// ok is false if there is no separator and the lines cannot be halved.
+func SplitSections(input []string) ([]string, []string, int, bool) {
+	if first, second, at, ok := SplitAtSeparator(input); ok {
+		return first, second, at, true
+	}
+	if len(input) == 0 || len(input)%2 != 0 {
+		return nil, nil, 0, false
+	}
+	h := len(input) / 2
+	return input[:h], input[h:], h, true
+}
+
+func regionGridFrom[K comparable](rows [][]K) ([]*[]grid.Coord, [][][]*[]grid.Coord) {
+	allRegions := make([]*[]grid.Coord, 0)
+	regionGrid := make([][][]*[]grid.Coord, 0)
+	regionMap := make(map[K]*[]grid.Coord)
+	for y, row := range rows {
+		regionGrid = append(regionGrid, make([][]*[]grid.Coord, 0, len(row)))
+		for x, key := range row {
+			regionGrid[y] = append(regionGrid[y], make([]*[]grid.Coord, 0))
+			region, ok := regionMap[key]
+			if !ok {
+				r := make([]grid.Coord, 0)
+				region = &r
+				regionMap[key] = region
+				allRegions = append(allRegions, region)
+			}
+			*region = append(*region, grid.Coord{X: x, Y: y})
+			regionGrid[y][x] = append(regionGrid[y][x], region)
+		}
+	}
+	return allRegions, regionGrid
+}
+
+func NewRegion() []grid.Coord {
+	return make([]grid.Coord, 0)
+}
+
+func (b *RectNumBoard) AddRowColRegions() {
+	for ri := 0; ri < b.W; ri++ {
+		rowRegion := NewRegion()
+		c := grid.Coord{X: 0, Y: ri}
+		for ; b.IsValid(c); c.X++ {
+			rowRegion = append(rowRegion, c)
+		}
+		b.AddRegion(rowRegion)
+	}
+	for ci := 0; ci < b.H; ci++ {
+		colRegion := NewRegion()
+		c := grid.Coord{X: ci, Y: 0}
+		for ; b.IsValid(c); c.Y++ {
+			colRegion = append(colRegion, c)
+		}
+		b.AddRegion(colRegion)
+	}
+}
+
+func (b *RectNumBoard) AddRegion(r []grid.Coord) {
+	b.AllRegions = append(b.AllRegions, &r)
+	for _, c := range r {
+		b.RegionGrid[c.Y][c.X] = append(b.RegionGrid[c.Y][c.X], &r)
+	}
+}
+
+func (b *RectNumBoard) AllowedCount(c grid.Coord) int {
+	return b.Allowed[c.Y][c.X].Size()
+}
+
+// Get returns the value of c, looking at Guess before Grid.
+func (b *RectNumBoard) Get(c grid.Coord) int {
+	if b.Guess[c.Y][c.X] != grid.UNKNOWN {
+		return b.Guess[c.Y][c.X]
+	}
+	return b.Grid[c.Y][c.X]
+}
+
+func (b *RectNumBoard) ClearGuess() {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Guess[c.Y][c.X] != grid.UNKNOWN {
+			b.write(b.Guess, c, grid.UNKNOWN)
+		}
+	}
+}
+
+// GuessValue places the hypothesis v for cell c in the Guess layer and
+// restricts the cell's allowed values accordingly. The caller is responsible
+// for running the board's PostMark on the guessed cell.
+func (b *RectNumBoard) GuessValue(c grid.Coord, v int) {
+	b.write(b.Guess, c, v)
+	b.AllowOnly(c, v)
+	b.SetDirty()
+}
+
+// AllowOnly reduces the allowed values of cell c to v alone.
+func (b *RectNumBoard) AllowOnly(c grid.Coord, v int) {
+	s := b.Allowed[c.Y][c.X]
+	old := s.Copy()
+	b.OnRollback(func() { *s = *old })
+	s.Clear()
+	s.Add(v)
+}
+
+// CommitGuess moves every hypothesis in the Guess layer into Grid.
+func (b *RectNumBoard) CommitGuess() {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Guess[c.Y][c.X] != grid.UNKNOWN {
+			b.write(b.Grid, c, b.Guess[c.Y][c.X])
+			b.write(b.Guess, c, grid.UNKNOWN)
+		}
+	}
+}
+
+// write sets c to v in layer, which is Grid or Guess, and records how to
+// undo it.
+func (b *RectNumBoard) write(layer [][]int, c grid.Coord, v int) {
+	row, old := layer[c.Y], layer[c.Y][c.X]
+	b.OnRollback(func() { row[c.X] = old })
+	row[c.X] = v
+}
+
+// SearchStep summarizes a search that turned b into sol, which must still
+// hold its guesses.
+func (b *RectNumBoard) SearchStep(sol *RectNumBoard) Step {
+	step := Step{Rule: "Search"}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if sol.Guess[c.Y][c.X] != grid.UNKNOWN {
+			step.Cells = append(step.Cells, c)
+		}
+		if b.IsUnknown(c) && !sol.IsUnknown(c) {
+			step.Marked = append(step.Marked, CellValue{c, sol.Get(c)})
+		}
+	}
+	return step
+}
+
+// Clone returns a copy of the board whose Grid, Guess and Allowed layers can
+// be modified without affecting the original. Regions never change after the
+// board is loaded, so they are shared. The copy starts with no open
+// checkpoints.
+func (b *RectNumBoard) Clone() *RectNumBoard {
+	return &RectNumBoard{
+		RectBoard:  b.RectBoard.clone(),
+		Grid:       grid.CopyNumGrid(b.Grid),
+		AllRegions: b.AllRegions,
+		RegionGrid: b.RegionGrid,
+		Allowed:    CopyAllowedSets(b.Allowed),
+		Guess:      grid.CopyNumGrid(b.Guess),
+	}
+}
+
+// MostConstrained returns the unknown cell with the fewest allowed values.
+// The second return value is false if every cell is filled.
+func (b *RectNumBoard) MostConstrained() (grid.Coord, bool) {
+	var best grid.Coord
+	found := false
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.IsFilled(c) {
+			continue
+		}
+		if !found || b.AllowedCount(c) < b.AllowedCount(best) {
+			best = c
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Validate returns an error if the board's current contents already break the
+// region rule: an empty cell with nothing left in Allowed, a filled cell whose
+// value has been eliminated, or a value that appears twice in one region.
+func (b *RectNumBoard) Validate() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		v := b.Get(c)
+		if v == grid.UNKNOWN && b.AllowedCount(c) == 0 {
+			return Contradiction(c, "Validate", "cell has no candidates left")
+		}
+		if v != grid.UNKNOWN && !b.IsAllowed(c, v) {
+			return Contradiction(c, "Validate", "cell holds %d, which has been ruled out", v)
+		}
+	}
+	for _, r := range b.AllRegions {
+		seen := make(map[int]grid.Coord)
+		for _, c := range *r {
+			v := b.Get(c)
+			if v == grid.UNKNOWN {
+				continue
+			}
+			if prev, ok := seen[v]; ok {
+				return Contradiction(c, "Validate", "cell holds %d, as does %s in the same region", v, prev)
+			}
+			seen[v] = c
+		}
+	}
+	return nil
+}
+
+func (b *RectNumBoard) IsFilled(c grid.Coord) bool {
+	return b.IsValid(c) && b.Get(c) != grid.UNKNOWN
+}
+
+func (b *RectNumBoard) IsUnknown(c grid.Coord) bool {
+	return b.IsValid(c) && b.Get(c) == grid.UNKNOWN
+}
+
+func (b *RectNumBoard) IsComplete() (bool, grid.Coord) {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Get(c) == grid.UNKNOWN {
+			return false, c
+		}
+	}
+	return true, grid.Coord{}
+}
+
+// Disallow removes v from the candidates of c and returns true iff it was
+// one.
+func (b *RectNumBoard) Disallow(c grid.Coord, v int) bool {
+	s := b.Allowed[c.Y][c.X]
+	ok := s.Del(v)
+	if ok {
+		b.OnRollback(func() { s.Add(v) })
+		b.StepRemoved(c, v)
+	}
+	return ok
+}
+
+// Allow adds v to the candidates of c and returns true iff it was not one
+// already.
+func (b *RectNumBoard) Allow(c grid.Coord, v int) bool {
+	s := b.Allowed[c.Y][c.X]
+	if s.Has(v) {
+		return false
+	}
+	s.Add(v)
+	b.OnRollback(func() { s.Del(v) })
+	return true
+}
+
+// Eliminate removes v from the candidates of c on behalf of rule, like
+// Disallow, and returns a ContradictionError if c holds v or is left with no
+// candidates at all.
+func (b *RectNumBoard) Eliminate(c grid.Coord, v int, rule string) (bool, error) {
+	ok := b.Disallow(c, v)
+	if b.Get(c) == v {
+		return ok, Contradiction(c, rule, "cell holds %d, which has been ruled out", v)
+	}
+	if b.Get(c) == grid.UNKNOWN && b.AllowedCount(c) == 0 {
+		return ok, Contradiction(c, rule, "no candidates left once %d is removed", v)
+	}
+	return ok, nil
+}
+
+func (b *RectNumBoard) MaxRegionSize() int {
+	n := 0
+	for _, r := range b.AllRegions {
+		n = max(len(*r), n)
+	}
+	return n
+}
+
+func (b *RectNumBoard) IsAllowed(c grid.Coord, v int) bool {
+	return b.Allowed[c.Y][c.X].Has(v)
+}
+
+func (b *RectNumBoard) AllowsExactly(c grid.Coord, nums []int) bool {
+	return b.Allowed[c.Y][c.X].EqualsSlice(nums)
+}
+
+// CharAt generates a character for the specified cell in the board's grid.
+func (b *RectNumBoard) CharAt(coord grid.Coord) string {
+	if !b.IsValid(coord) {
+		return " "
+	}
+	return string(grid.IntToCh(b.Get(coord)))
+}
+
+// Mark writes v to c and, once the board is set up, removes v from the
+// candidates of every other cell in c's regions.
+func (b *RectNumBoard) Mark(c grid.Coord, v int) (bool, error) {
+	res, err := b.Set(c, v)
+	if !b.Inited {
+		return res, err
+	}
+	if err != nil {
+		return res, err
+	}
+	b.SetDirty()
+	if _, err := b.PostMark(c, v); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// PostMark removes v, just placed at c, from the candidates of the other
+// cells in c's regions.
+func (b *RectNumBoard) PostMark(c grid.Coord, v int) (bool, error) {
+	changed := false
+	for _, region := range b.RegionGrid[c.Y][c.X] {
+		for _, neighbor := range *region {
+			if neighbor == c {
+				continue
+			}
+			ok, err := b.Eliminate(neighbor, v, "PostMark")
+			if err != nil {
+				return changed, err
+			}
+			if ok {
+				changed = true
+			}
+		}
+	}
+	return changed, nil
+}
+
+// Set writes v to c without running any rules. Returns true iff c changed,
+// or a ContradictionError if c already holds another value.
+func (b *RectNumBoard) Set(c grid.Coord, v int) (bool, error) {
+	if !b.IsValid(c) {
+		return false, fmt.Errorf("coordinate (%d,%d) not valid on board of size (%d,%d)", c.X, c.Y, b.W, b.H)
+	}
+	if b.IsFilled(c) {
+		if b.Get(c) == v {
+			return false, nil
+		}
+		return false, Contradiction(c, "Mark", "cell already holds %d; cannot make it %d", b.Get(c), v)
+	}
+	b.write(b.Grid, c, v)
+	b.StepMarked(c, v)
+	return true, nil
+}
+
+// Cells returns the value of every cell, for export.
+func (b *RectNumBoard) Cells() [][]int {
+	out := grid.MakeNumGrid(b.W, b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		out[c.Y][c.X] = b.Get(c)
+	}
+	return out
+}
+
+// Candidates returns the allowed values of every cell in ascending order,
+// for export.
+func (b *RectNumBoard) Candidates() [][][]int {
+	out := make([][][]int, b.H)
+	for y := range out {
+		out[y] = make([][]int, b.W)
+		for x := range out[y] {
+			out[y][x] = b.Allowed[y][x].Sorted()
+		}
+	}
+	return out
+}
+
+// RestrictCandidates removes from each cell every candidate missing from
+// its entry in cands, which must be as large as the board. A cell whose
+// entry is empty is left alone. Returns a ContradictionError if that leaves
+// a cell with no candidates or rules out a value already on the board.
+func (b *RectNumBoard) RestrictCandidates(cands [][][]int) error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		keep := cands[c.Y][c.X]
+		if len(keep) == 0 {
+			continue
+		}
+		for _, v := range b.Allowed[c.Y][c.X].Sorted() {
+			if SliceContains(keep, v) {
+				continue
+			}
+			if _, err := b.Eliminate(c, v, "Candidates"); err != nil {
+				return err
+			}
+		}
+	}
+	b.SetDirty()
+	return nil
+}
+
+func (b *RectNumBoard) EachCell(cb func(c grid.Coord, v int) bool) {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if cb(c, b.Get(c)) {
+			return
+		}
+	}
+}
+
+func (b *RectNumBoard) EachNeighbor(start grid.Coord, cb func(c grid.Coord, v int) bool) {
+	for _, dir := range grid.DIRECTIONS {
+		c := start.Plus(dir)
+		if b.IsValid(c) {
+			cb(c, b.Get(c))
+		}
+	}
+}
+
+func (b *RectNumBoard) DisallowAll(c grid.Coord, s set.Set[int]) bool {
+	changed := false
+	for _, k := range s.Sorted() {
+		if b.Disallow(c, k) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (b *RectNumBoard) IsRegionSolved(r []grid.Coord) bool {
+	ns := set.NewNumSet(len(r))
+	for _, c := range r {
+		ns.Del(b.Get(c))
+	}
+	return ns.Size() == 0
+}
+
+// MarkMandatory searches for cells with only one entry in Allowed and marks
+// the appropriate value. Returns true iff a change was made. The redo flag
+// repeats the loop if marking a mandatory cell eliminated entries in Allowed
+// for other cells in the same row or column. If, for example, cell (a, b) is
+// the last empty cell in its row and column, then marking (a, b) will not
+// eliminate any possibilities from other cells, and repeating the loop is not
+// necessary. Returns a ContradictionError if marking a cell leaves another
+// cell with no candidates.
+func (b *RectNumBoard) MarkMandatory() (bool, error) {
+	changed := false
+	redo := false
+	var markErr error
+	b.EachCell(func(c grid.Coord, v int) bool {
+		allowed := b.Allowed[c.Y][c.X]
+		if len(allowed.M) != 1 || b.Get(c) != grid.UNKNOWN {
+			return false
+		}
+		k := allowed.GetOne()
+		b.BeginStep("MarkMandatory", c)
+		res, err := b.Mark(c, k)
+		b.EndStep()
+		if err != nil {
+			markErr = err
+			return true
+		}
+		if res {
+			changed = true
+		}
+		return false
+	})
+	if markErr != nil {
+		return changed, markErr
+	}
+	if redo {
+		return b.MarkMandatory()
+	}
+	return changed, nil
+}
+
+// CheckRegionFoundGroup returns true iff row the region contains a found group for
+// the numbers specified in numbers.
+func (b *RectNumBoard) CheckRegionFoundGroup(numbers []int, r []grid.Coord) bool {
+	numberCells := make([]*set.Set[grid.Coord], len(numbers))
+	for i := range numbers {
+		numberCells[i] = grid.NewCoordSet()
+	}
+	for _, c := range r {
+		for nidx, num := range numbers {
+			if b.IsAllowed(c, num) {
+				numberCells[nidx].Add(c)
+			}
+		}
+	}
+	if numberCells[0].Size() != len(numbers) {
+		return false
+	}
+	for i := 1; i < len(numbers); i++ {
+		if !numberCells[i].Equals(numberCells[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// TrimFoundGroups looks at each row and column for found groups of size n and
+// makes the appropriate changes to b.Allowed if any are found. Returns true
+// iff at least one change was made. A found group (I haven't looked up the
+// correct name for this heuristic) occurs when, e.g., the numbers 2 and 3 each
+// have the same exact two possible homes in a given line. Since 2 and 3 must
+// go in those two cells, all other numbers can be removed from their allowed
+// lists. TODO: update with the correct term for "found groups!"
+func (b *RectNumBoard) TrimFoundGroups(n int) bool {
+	changed := false
+	numbers := perms.Permute(1, b.MaxRegionSize(), n)
+	for _, nums := range numbers {
+		for _, r := range b.AllRegions {
+			if n >= len(*r) {
+				continue
+			}
+			if !b.CheckRegionFoundGroup(nums, *r) {
+				continue
+			}
+			group := make([]grid.Coord, 0, n)
+			for _, c := range *r {
+				if b.IsAllowed(c, nums[0]) {
+					group = append(group, c)
+				}
+			}
+			b.BeginStep("TrimFoundGroups", group...)
+			for _, c := range group {
+				if b.DisallowOthers(c, nums) {
+					changed = true
+				}
+			}
+			b.EndStep()
+		}
+	}
+	return changed
+}
+
+func (b *RectNumBoard) DisallowOthers(c grid.Coord, nums []int) bool {
+	changed := false
+	for n := 1; n <= b.MaxRegionSize(); n++ {
+		if !SliceContains(nums, n) && b.Disallow(c, n) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// CheckRowNakedSet returns true iff row rowIndex contains a naked set at the
+// indices specified in indices.
+func (b *RectNumBoard) CheckRegionNakedSet(indices []int, region []grid.Coord) bool {
+	if len(indices) == 0 {
+		return false
+	}
+	if len(indices) > len(region) {
+		return false
+	}
+	firstCoord := region[indices[0]]
+	if len(indices) != b.AllowedCount(firstCoord) {
+		return false
+	}
+	for _, idx := range indices[1:] {
+		if idx >= len(region) {
+			return false
+		}
+		if b.Get(region[idx]) != grid.UNKNOWN {
+			return false
+		}
+		if !b.Allowed[region[idx].Y][region[idx].X].Equals(b.Allowed[firstCoord.Y][firstCoord.X]) {
+			return false
+		}
+	}
+	return true
+}
+
+// TrimNakedSets looks at each row and column for naked sets of size n and
+// makes the appropriate changes to b.Allowed if any are found. Returns true
+// iff at least one change was made. A naked set (known more commonly as a
+// naked pair or naked triple) occurs when, e.g., the allowed lists for cells
+// A and B are [1, 2]. It allows us to eliminate 1 and 2 from the allowed lists
+// of other cells in the same line.
+func (b *RectNumBoard) TrimNakedSets(n int) bool {
+	result := false
+	for _, region := range b.AllRegions {
+		if n >= len(*region) {
+			continue
+		}
+		regionSubsets := perms.Permute(0, len(*region)-1, n)
+		for _, idxs := range regionSubsets {
+			if b.CheckRegionNakedSet(idxs, *region) {
+				tmp := (*region)[idxs[0]]
+				numsToDisallow := b.Allowed[tmp.Y][tmp.X].Copy()
+				set := make([]grid.Coord, 0, n)
+				for _, idx := range idxs {
+					set = append(set, (*region)[idx])
+				}
+				b.BeginStep("TrimNakedSets", set...)
+				for idx, c := range *region {
+					if SliceContains(idxs, idx) {
+						continue
+					}
+					if b.DisallowAll(c, *numsToDisallow) {
+						result = true
+					}
+				}
+				b.EndStep()
+			}
+		}
+
+	}
+	return result
+}
+
+func (b *RectNumBoard) TrimAllNakedSets() bool {
+	changed, _ := b.TrimAllNakedSetsContext(context.Background())
+	return changed
+}
+
+// TrimAllNakedSetsContext is TrimAllNakedSets, but stops before the next
+// set size once ctx is done; see Stopped.
+func (b *RectNumBoard) TrimAllNakedSetsContext(ctx context.Context) (bool, error) {
+	changed := false
+	for n := 2; n < b.MaxRegionSize(); n++ {
+		if err := Stopped(ctx); err != nil {
+			return changed, err
+		}
+		if b.TrimNakedSets(n) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func (b *RectNumBoard) TrimAllFoundGroups() bool {
+	changed, _ := b.TrimAllFoundGroupsContext(context.Background())
+	return changed
+}
+
+// TrimAllFoundGroupsContext is TrimAllFoundGroups, but stops before the
+// next group size once ctx is done; see Stopped.
+func (b *RectNumBoard) TrimAllFoundGroupsContext(ctx context.Context) (bool, error) {
+	changed := false
+	for n := 2; n < b.MaxRegionSize(); n++ {
+		if err := Stopped(ctx); err != nil {
+			return changed, err
+		}
+		if b.TrimFoundGroups(n) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// MakeRegionGrid returns a w by h grid of empty region lists.
+func MakeRegionGrid(w, h int) [][][]*[]grid.Coord {
+	g := make([][][]*[]grid.Coord, 0, h)
+	for i := 0; i < int(h); i++ {
+		g = append(g, make([][]*[]grid.Coord, 0, w))
+		for j := 0; j < w; j++ {
+			g[i] = append(g[i], make([]*[]grid.Coord, 0))
+		}
+	}
+	return g
+}
+
+// MakeAllowedSets returns a w by h grid of candidate sets, each holding 1
+// through order.
+func MakeAllowedSets(w, h int, order int) [][]*set.Set[int] {
+	g := make([][]*set.Set[int], 0, h)
+	for i := 0; i < h; i++ {
+		g = append(g, make([]*set.Set[int], 0, w))
+		for j := 0; j < w; j++ {
+			g[i] = append(g[i], set.NewNumSet(order))
+		}
+	}
+	return g
+}
+
+// CopyAllowedSets returns a deep copy of a grid of allowed sets.
+func CopyAllowedSets(g [][]*set.Set[int]) [][]*set.Set[int] {
+	out := make([][]*set.Set[int], 0, len(g))
+	for _, row := range g {
+		newRow := make([]*set.Set[int], 0, len(row))
+		for _, s := range row {
+			newRow = append(newRow, s.Copy())
+		}
+		out = append(out, newRow)
+	}
+	return out
+}
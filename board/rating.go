@@ -0,0 +1,218 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Technique is a solving rule together with how hard it is for a person to
+// spot. Apply runs the rule once over the whole board and returns true iff
+// it made progress, or an error if the rule found a contradiction.
+type Technique struct {
+	Name  string
+	Cost  int
+	Apply func() (bool, error)
+}
+
+// SearchCost is the cost charged for each guess the backtracking search had
+// to make once every technique stalled.
+const SearchCost = 10
+
+// Rating describes how hard a puzzle was to solve: the hardest technique it
+// needed, how many times each technique made progress, and a numeric score
+// that grows with both.
+type Rating struct {
+	Grade       string
+	Score       int
+	Hardest     string
+	HardestCost int
+	Uses        map[string]int
+	Order       []string
+	SearchDepth int
+	Solved      bool
+}
+
+// GradeForCost maps the cost of the hardest technique a puzzle needed to a
+// grade.
+func GradeForCost(cost int) string {
+	if cost <= 1 {
+		return "easy"
+	} else if cost <= 2 {
+		return "medium"
+	} else if cost <= 4 {
+		return "hard"
+	}
+	return "diabolical"
+}
+
+func (r *Rating) String() string {
+	out := fmt.Sprintf("Grade: %s (score %d)\n", r.Grade, r.Score)
+	out += fmt.Sprintf("Hardest technique: %s\n", r.Hardest)
+	if r.SearchDepth > 0 {
+		out += fmt.Sprintf("Search depth: %d\n", r.SearchDepth)
+	}
+	uses := make([]string, 0, len(r.Order))
+	for _, name := range r.Order {
+		uses = append(uses, fmt.Sprintf("%s x%d", name, r.Uses[name]))
+	}
+	out += fmt.Sprintf("Techniques used: %s\n", strings.Join(uses, ", "))
+	out += fmt.Sprintf("Solved: %v", r.Solved)
+	return out
+}
+
+// RateTechniques repeatedly applies the cheapest technique that makes
+// progress, starting over from the cheapest one after every success, until
+// none of them can do anything more. This mimics a solver who only reaches
+// for a harder technique when every easier one is exhausted. techs must be
+// sorted by increasing cost. If ctx is done first, RateTechniques returns
+// an error wrapping ErrTimedOut.
+func RateTechniques(ctx context.Context, techs []Technique) (*Rating, error) {
+	r := &Rating{
+		Uses:    make(map[string]int),
+		Hardest: "none",
+	}
+	for {
+		if err := Stopped(ctx); err != nil {
+			return nil, err
+		}
+		progressed := false
+		for _, t := range techs {
+			ok, err := t.Apply()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			r.use(t.Name, t.Cost, 1)
+			progressed = true
+			break
+		}
+		if !progressed {
+			return r, nil
+		}
+	}
+}
+
+func (r *Rating) use(name string, cost int, times int) {
+	if _, ok := r.Uses[name]; !ok {
+		r.Order = append(r.Order, name)
+	}
+	r.Uses[name] += times
+	r.Score += cost * times
+	if cost > r.HardestCost {
+		r.HardestCost = cost
+		r.Hardest = name
+	}
+}
+
+// Finish falls back to search if the techniques stalled, and fills in the
+// grade.
+func (r *Rating) Finish(solved bool, search func() (bool, int, error)) error {
+	r.Solved = solved
+	if !r.Solved {
+		ok, depth, err := search()
+		if err != nil {
+			return err
+		}
+		r.Solved = ok
+		r.SearchDepth = depth
+		r.use("Search", SearchCost, max(depth, 1))
+	}
+	r.Grade = GradeForCost(r.HardestCost)
+	return nil
+}
+
+// Rate solves a board using techs in increasing order of cost, falling
+// back to search if they stall, and reports how hard that was. b is the
+// board's RectBoard, which starts tracing if it is not already, so that
+// the search depth can be read off its steps; isSolved and search are the
+// board's own. The board is left solved (or as far along as the search
+// got). If ctx is done first, Rate returns an error wrapping ErrTimedOut.
+func Rate(ctx context.Context, b *RectBoard, techs []Technique, isSolved func() (bool, error), search func(context.Context) (bool, error)) (*Rating, error) {
+	if b.Trace == nil {
+		b.EnableTrace()
+	}
+	r, err := RateTechniques(ctx, techs)
+	if errors.Is(err, ErrTimedOut) {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("puzzle has no solution: %w", err)
+	}
+	solved, _ := isSolved()
+	err = r.Finish(solved, func() (bool, int, error) {
+		ok, err := search(ctx)
+		return ok, SearchDepth(b.Steps()), err
+	})
+	return r, err
+}
+
+// RateBin is Rate for a board of painted and clear cells, with its own
+// Techniques and SearchBin to fall back on.
+func RateBin[B any, P interface {
+	*B
+	BinGuesser[P]
+	Techniques() []Technique
+}](ctx context.Context, b P) (*Rating, error) {
+	return Rate(ctx, b.layer().Rect(), b.Techniques(), b.IsSolved, func(ctx context.Context) (bool, error) {
+		return SearchBin[B, P](ctx, b)
+	})
+}
+
+// RateNum is RateBin for a numeric board, with SearchNum.
+func RateNum[B any, P interface {
+	*B
+	NumGuesser[P]
+	Techniques() []Technique
+}](ctx context.Context, b P) (*Rating, error) {
+	return Rate(ctx, b.layer().Rect(), b.Techniques(), b.IsSolved, func(ctx context.Context) (bool, error) {
+		return SearchNum[B, P](ctx, b)
+	})
+}
+
+// SearchDepth returns the number of guesses recorded by the last Search
+// step in steps.
+func SearchDepth(steps []Step) int {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Rule == "Search" {
+			return len(steps[i].Cells)
+		}
+	}
+	return 0
+}
+
+// DirtyTechnique adapts a rule that reports progress through the board's
+// dirty flag.
+func DirtyTechnique(b *RectBoard, name string, cost int, rule func() error) Technique {
+	return Technique{name, cost, func() (bool, error) {
+		b.ClearDirty()
+		err := rule()
+		return b.IsDirty(), err
+	}}
+}
+
+// PlainTechnique adapts a rule that cannot run into a contradiction itself;
+// anything it breaks is caught by Validate.
+func PlainTechnique(name string, cost int, rule func() bool) Technique {
+	return Technique{name, cost, func() (bool, error) {
+		return rule(), nil
+	}}
+}
+
+// SetTechniques lists the naked set and found group rules for every size
+// that can matter on this board, in increasing order of size.
+func (b *RectNumBoard) SetTechniques() []Technique {
+	out := make([]Technique, 0)
+	for n := 2; n < b.MaxRegionSize(); n++ {
+		n := n
+		out = append(out, PlainTechnique(fmt.Sprintf("TrimNakedSets(%d)", n), n, func() bool {
+			return b.TrimNakedSets(n)
+		}))
+		out = append(out, PlainTechnique(fmt.Sprintf("TrimFoundGroups(%d)", n), n, func() bool {
+			return b.TrimFoundGroups(n)
+		}))
+	}
+	return out
+}
@@ -0,0 +1,251 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Searchable is a puzzle type's board as Search, CountSolutions and
+// Enumerate see it; P is the board's own pointer type. The guess layer,
+// trace and trail come from the RectBinBoard or RectNumBoard it embeds.
+type Searchable[P any] interface {
+	Clone() P
+	SolveContext(ctx context.Context) error
+	IsSolved() (bool, error)
+	layer() guessLayer
+}
+
+// guessLayer is what RectBinBoard and RectNumBoard do for a search.
+type guessLayer interface {
+	Rect() *RectBoard
+	CommitGuess()
+	searchStep(sol guessLayer) Step
+}
+
+func (b *RectBinBoard) layer() guessLayer {
+	return b
+}
+
+func (b *RectBinBoard) searchStep(sol guessLayer) Step {
+	return b.SearchStep(sol.(*RectBinBoard))
+}
+
+func (b *RectNumBoard) layer() guessLayer {
+	return b
+}
+
+func (b *RectNumBoard) searchStep(sol guessLayer) Step {
+	return b.SearchStep(sol.(*RectNumBoard))
+}
+
+// Search solves b, guessing and backtracking whenever its SolveContext
+// stops making progress. The search is driven by two functions of the
+// puzzle type. pick chooses the cell to branch on and the values to try
+// there, in order, and returns false once no cell is left to guess. assume
+// writes a guess for a cell into the Guess layer and runs whatever the
+// rules do immediately after a mark; an error means the guess is wrong.
+// Guesses are explored on clones with tracing turned off, so dead ends
+// never show up in the trace.
+//
+// Returns true if a solution was found, in which case b holds it and its
+// trace ends with a single step summarizing the search; otherwise the
+// error explains why the puzzle has no solution. If ctx is done first,
+// Search returns an error wrapping ErrTimedOut and leaves b as the rules
+// left it before the first guess.
+func Search[B any, P interface {
+	*B
+	Searchable[P]
+}, V any](ctx context.Context, b P, pick func(P) (grid.Coord, []V, bool), assume func(P, grid.Coord, V) error) (bool, error) {
+	if err := b.SolveContext(ctx); err != nil {
+		if errors.Is(err, ErrTimedOut) {
+			return false, err
+		}
+		return false, fmt.Errorf("puzzle has no solution: %w", err)
+	}
+	var sol P
+	root := b.Clone()
+	root.layer().Rect().Trace = nil
+	_, err := Enumerate(ctx, root, pick, assume, func(s P) bool {
+		sol = s
+		return false
+	})
+	if errors.Is(err, ErrTimedOut) {
+		return false, err
+	}
+	if sol == nil {
+		return false, fmt.Errorf("puzzle has no solution: %w", err)
+	}
+	base, found := b.layer(), sol.layer()
+	base.Rect().RecordStep(base.searchStep(found))
+	found.CommitGuess()
+	found.Rect().Trace = base.Rect().Trace
+	found.Rect().Trail = base.Rect().Trail
+	old := *b
+	*b = *sol
+	b.layer().Rect().OnRollback(func() { *b = old })
+	return true, nil
+}
+
+// CountSolutions enumerates the solutions of b, stopping once limit have
+// been found (a limit of 0 or less means no limit). Returns the number
+// found and, as witnesses, copies of the first two. b itself is left
+// untouched. If ctx is done first, the count so far is returned with an
+// error wrapping ErrTimedOut.
+func CountSolutions[P Searchable[P], V any](ctx context.Context, b P, limit int, pick func(P) (grid.Coord, []V, bool), assume func(P, grid.Coord, V) error) (int, []P, error) {
+	count := 0
+	witnesses := make([]P, 0, 2)
+	root := b.Clone()
+	root.layer().Rect().Trace = nil
+	_, err := Enumerate(ctx, root, pick, assume, func(s P) bool {
+		count++
+		if len(witnesses) < 2 {
+			w := s.Clone()
+			w.layer().CommitGuess()
+			witnesses = append(witnesses, w)
+		}
+		return limit <= 0 || count < limit
+	})
+	if errors.Is(err, ErrTimedOut) {
+		return count, witnesses, err
+	}
+	return count, witnesses, nil
+}
+
+// Enumerate calls visit for every solution below b in the search tree until
+// visit returns false, in which case Enumerate also returns false. Every
+// branch is a clone, so b is left as its SolveContext leaves it. Each
+// branch fixes at least one cell differently from its siblings, so the
+// solutions visited are always distinct. The error is non-nil only if the
+// subtree holds no solution at all, and describes the contradiction that
+// closed it. If ctx is done, Enumerate stops and returns false with an
+// error wrapping ErrTimedOut.
+func Enumerate[P Searchable[P], V any](ctx context.Context, b P, pick func(P) (grid.Coord, []V, bool), assume func(P, grid.Coord, V) error, visit func(P) bool) (bool, error) {
+	if err := b.SolveContext(ctx); err != nil {
+		return !errors.Is(err, ErrTimedOut), err
+	}
+	c, values, ok := pick(b)
+	if !ok {
+		if _, err := b.IsSolved(); err != nil {
+			return true, err
+		}
+		return visit(b), nil
+	}
+	var lastErr error
+	found := false
+	for _, v := range values {
+		g := b.Clone()
+		if err := assume(g, c, v); err != nil {
+			lastErr = err
+			continue
+		}
+		more, err := Enumerate(ctx, g, pick, assume, visit)
+		if errors.Is(err, ErrTimedOut) {
+			return false, err
+		} else if err != nil {
+			lastErr = err
+		} else {
+			found = true
+		}
+		if !more {
+			return false, nil
+		}
+	}
+	if found {
+		return true, nil
+	}
+	return true, fmt.Errorf("no value of %s works (last: %w)", c, lastErr)
+}
+
+// BinGuesser is a board of painted and clear cells that SearchBin and
+// CountBin can branch on. PickUnknown chooses the cell to guess on and
+// returns a cell off the board once none is unknown. GuessValue and
+// SetDirty come from the embedded RectBinBoard, and PostMark runs the
+// rules that follow a mark.
+type BinGuesser[P any] interface {
+	Searchable[P]
+	PickUnknown() grid.Coord
+	GuessValue(c grid.Coord, v grid.Cell)
+	SetDirty()
+	PostMark(c grid.Coord, v grid.Cell) error
+}
+
+// NumGuesser is a numeric board that SearchNum and CountNum can branch on.
+// Pick and GuessValue come from the embedded RectNumBoard, and PostMark
+// runs the rules that follow a mark.
+type NumGuesser[P any] interface {
+	Searchable[P]
+	Pick() (grid.Coord, []int, bool)
+	GuessValue(c grid.Coord, v int)
+	PostMark(c grid.Coord, v int) (bool, error)
+}
+
+func binPick[P BinGuesser[P]](b P) (grid.Coord, []grid.Cell, bool) {
+	return BinaryPick(P.PickUnknown)(b)
+}
+
+func binAssume[P BinGuesser[P]](b P, c grid.Coord, v grid.Cell) error {
+	b.GuessValue(c, v)
+	b.SetDirty()
+	return b.PostMark(c, v)
+}
+
+func numPick[P NumGuesser[P]](b P) (grid.Coord, []int, bool) {
+	return b.Pick()
+}
+
+func numAssume[P NumGuesser[P]](b P, c grid.Coord, v int) error {
+	b.GuessValue(c, v)
+	_, err := b.PostMark(c, v)
+	return err
+}
+
+// SearchBin is Search for a board of painted and clear cells, trying to
+// paint the cell PickUnknown chooses before clearing it.
+func SearchBin[B any, P interface {
+	*B
+	BinGuesser[P]
+}](ctx context.Context, b P) (bool, error) {
+	return Search(ctx, b, binPick[P], binAssume[P])
+}
+
+// CountBin is CountSolutions for a board of painted and clear cells.
+func CountBin[P BinGuesser[P]](ctx context.Context, b P, limit int) (int, []P, error) {
+	return CountSolutions(ctx, b, limit, binPick[P], binAssume[P])
+}
+
+// SearchNum is Search for a numeric board, guessing on the cell with the
+// fewest candidates.
+func SearchNum[B any, P interface {
+	*B
+	NumGuesser[P]
+}](ctx context.Context, b P) (bool, error) {
+	return Search(ctx, b, numPick[P], numAssume[P])
+}
+
+// CountNum is CountSolutions for a numeric board.
+func CountNum[P NumGuesser[P]](ctx context.Context, b P, limit int) (int, []P, error) {
+	return CountSolutions(ctx, b, limit, numPick[P], numAssume[P])
+}
+
+// BinaryPick turns the PickUnknown of a board of binary cells, which
+// returns a cell off the board once none is unknown, into a pick for
+// Search: it tries painting the cell, then clearing it.
+func BinaryPick[P any](pickUnknown func(P) grid.Coord) func(P) (grid.Coord, []grid.Cell, bool) {
+	return func(b P) (grid.Coord, []grid.Cell, bool) {
+		c := pickUnknown(b)
+		return c, []grid.Cell{grid.PAINTED, grid.CLEAR}, c.X >= 0
+	}
+}
+
+// Pick is the pick of a numeric board for Search: the unknown cell with
+// the fewest allowed values, trying each of them from the smallest.
+func (b *RectNumBoard) Pick() (grid.Coord, []int, bool) {
+	c, ok := b.MostConstrained()
+	if !ok {
+		return c, nil, false
+	}
+	return c, b.Allowed[c.Y][c.X].Sorted(), true
+}
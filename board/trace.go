@@ -0,0 +1,209 @@
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Step records one deduction made by a solving rule: the rule's name, the
+// cells it reasoned from, and everything it changed as a result.
+type Step struct {
+	Rule    string       `json:"rule"`
+	Cells   []grid.Coord `json:"cells,omitempty"`
+	Marked  []CellValue  `json:"marked,omitempty"`
+	Removed []CellValue  `json:"removed,omitempty"`
+	Wings   []WingRange  `json:"wings,omitempty"`
+	Perms   []PermCount  `json:"perms,omitempty"`
+
+	// binary is set for steps taken on a RectBinBoard, whose marks String
+	// writes by name.
+	binary bool
+}
+
+// CellValue pairs a cell with a value. For binary boards the value is the
+// Cell constant (PAINTED or CLEAR).
+type CellValue struct {
+	At    grid.Coord `json:"at"`
+	Value int        `json:"value"`
+}
+
+// WingRange is the range a kuromasu wing was tightened to.
+type WingRange struct {
+	Root grid.Coord `json:"root"`
+	Dir  grid.Delta `json:"dir"`
+	Min  int        `json:"min"`
+	Max  int        `json:"max"`
+}
+
+// PermCount is the number of permutations left for a row or column of a
+// towers.Board after a trimming rule ran.
+type PermCount struct {
+	Row       bool `json:"row"`
+	Index     int  `json:"index"`
+	Remaining int  `json:"remaining"`
+}
+
+func (s Step) IsEmpty() bool {
+	return len(s.Marked) == 0 && len(s.Removed) == 0 && len(s.Wings) == 0 && len(s.Perms) == 0
+}
+
+func (s Step) String() string {
+	out := s.Rule
+	if len(s.Cells) > 0 {
+		cells := make([]string, 0, len(s.Cells))
+		for _, c := range s.Cells {
+			cells = append(cells, c.String())
+		}
+		out += " at " + strings.Join(cells, " ")
+	}
+	for _, m := range s.Marked {
+		if s.binary {
+			out += fmt.Sprintf("; mark %s %s", m.At, grid.CellName(grid.Cell(m.Value)))
+		} else {
+			out += fmt.Sprintf("; mark %s=%d", m.At, m.Value)
+		}
+	}
+	for _, r := range s.Removed {
+		out += fmt.Sprintf("; remove %d from %s", r.Value, r.At)
+	}
+	for _, w := range s.Wings {
+		out += fmt.Sprintf("; wing %s %s [%d,%d]", w.Root, w.Dir, w.Min, w.Max)
+	}
+	for _, p := range s.Perms {
+		line := "col"
+		if p.Row {
+			line = "row"
+		}
+		out += fmt.Sprintf("; %s %d has %d perms", line, p.Index, p.Remaining)
+	}
+	return out
+}
+
+// Trace collects the steps taken while a board is solved. Rules may run
+// inside other rules (marking a cell runs PostMark, which may run more
+// rules), so steps are opened and closed like a stack. A step's slot is
+// reserved when it opens, so a cause is always listed before the
+// consequences it triggered. Steps that end up changing nothing are dropped.
+type Trace struct {
+	Steps  []Step
+	open   []int
+	binary bool
+}
+
+// EnableTrace starts recording steps on the board.
+func (b *RectBoard) EnableTrace() {
+	b.Trace = &Trace{}
+}
+
+// EnableTrace starts recording steps on the board, whose marks are written
+// as painted or clear.
+func (b *RectBinBoard) EnableTrace() {
+	b.Trace = &Trace{binary: true}
+}
+
+// Steps returns the steps recorded so far, or nil if tracing is disabled.
+func (b *RectBoard) Steps() []Step {
+	if b.Trace == nil {
+		return nil
+	}
+	return b.Trace.Steps
+}
+
+// BeginStep opens a step for rule, reasoning from cells.
+func (b *RectBoard) BeginStep(rule string, cells ...grid.Coord) {
+	if b.Trace == nil {
+		return
+	}
+	b.Trace.Steps = append(b.Trace.Steps, Step{
+		Rule:   rule,
+		Cells:  append([]grid.Coord(nil), cells...),
+		binary: b.Trace.binary,
+	})
+	b.Trace.open = append(b.Trace.open, len(b.Trace.Steps)-1)
+}
+
+// EndStep closes the innermost open step.
+func (b *RectBoard) EndStep() {
+	if b.Trace == nil || len(b.Trace.open) == 0 {
+		return
+	}
+	idx := b.Trace.open[len(b.Trace.open)-1]
+	b.Trace.open = b.Trace.open[:len(b.Trace.open)-1]
+	if b.Trace.Steps[idx].IsEmpty() {
+		b.Trace.Steps = append(b.Trace.Steps[:idx], b.Trace.Steps[idx+1:]...)
+	}
+}
+
+// RecordStep adds an already completed step to the trace.
+func (b *RectBoard) RecordStep(s Step) {
+	if b.Trace == nil || s.IsEmpty() {
+		return
+	}
+	b.Trace.Steps = append(b.Trace.Steps, s)
+}
+
+// current returns the innermost open step, or nil if there is none. The
+// pointer is only good until the next step is opened.
+func (b *RectBoard) current() *Step {
+	if b.Trace == nil || len(b.Trace.open) == 0 {
+		return nil
+	}
+	return &b.Trace.Steps[b.Trace.open[len(b.Trace.open)-1]]
+}
+
+func (b *RectBoard) StepMarked(c grid.Coord, v int) {
+	if s := b.current(); s != nil {
+		s.Marked = append(s.Marked, CellValue{c, v})
+	}
+}
+
+func (b *RectBoard) StepRemoved(c grid.Coord, v int) {
+	if s := b.current(); s != nil {
+		s.Removed = append(s.Removed, CellValue{c, v})
+	}
+}
+
+// StepWing records the new range of a wing. A wing that is tightened more
+// than once in the same step appears only once, with its final range.
+func (b *RectBoard) StepWing(root grid.Coord, dir grid.Delta, min, max int) {
+	s := b.current()
+	if s == nil {
+		return
+	}
+	for i := range s.Wings {
+		if s.Wings[i].Root == root && s.Wings[i].Dir == dir {
+			s.Wings[i].Min = min
+			s.Wings[i].Max = max
+			return
+		}
+	}
+	s.Wings = append(s.Wings, WingRange{root, dir, min, max})
+}
+
+func (b *RectBoard) StepPerms(row bool, idx int, remaining int) {
+	if s := b.current(); s != nil {
+		s.Perms = append(s.Perms, PermCount{row, idx, remaining})
+	}
+}
+
+// WriteTrace writes steps to w, either one JSON object per line (format
+// "json") or one human-readable line per step (format "text").
+func WriteTrace(w io.Writer, steps []Step, format string) error {
+	enc := json.NewEncoder(w)
+	for _, s := range steps {
+		var err error
+		if format == "json" {
+			err = enc.Encode(s)
+		} else {
+			_, err = fmt.Fprintln(w, s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package board
+
+// Trail records how to undo the changes made to a board since a checkpoint,
+// so that a hypothesis can be tried in place and taken back without copying
+// the board. Changes are only recorded while at least one checkpoint is
+// open; with none open, the board runs exactly as it would without a Trail.
+type Trail struct {
+	undo  []func()
+	marks []int
+}
+
+// Checkpoint opens a checkpoint. Checkpoints nest: Rollback and Commit act
+// on the most recent one still open.
+func (b *RectBoard) Checkpoint() {
+	if b.Trail == nil {
+		b.Trail = &Trail{}
+	}
+	b.Trail.marks = append(b.Trail.marks, len(b.Trail.undo))
+	dirty, inited := b.Dirty, b.Inited
+	b.OnRollback(func() { b.Dirty, b.Inited = dirty, inited })
+}
+
+// Rollback undoes every change made since the most recent open checkpoint
+// and closes it. Returns false if no checkpoint is open.
+func (b *RectBoard) Rollback() bool {
+	t := b.Trail
+	if t == nil || len(t.marks) == 0 {
+		return false
+	}
+	mark := t.marks[len(t.marks)-1]
+	t.marks = t.marks[:len(t.marks)-1]
+	for i := len(t.undo) - 1; i >= mark; i-- {
+		t.undo[i]()
+	}
+	t.undo = t.undo[:mark]
+	return true
+}
+
+// Commit closes the most recent open checkpoint and keeps the changes made
+// since it was opened; rolling back an older checkpoint still undoes them.
+// Returns false if no checkpoint is open.
+func (b *RectBoard) Commit() bool {
+	t := b.Trail
+	if t == nil || len(t.marks) == 0 {
+		return false
+	}
+	t.marks = t.marks[:len(t.marks)-1]
+	if len(t.marks) == 0 {
+		t.undo = nil
+	}
+	return true
+}
+
+// OnRollback records undo, which must revert a change about to be made to
+// the board, if a checkpoint is open.
+func (b *RectBoard) OnRollback(undo func()) {
+	if b.Recording() {
+		b.Trail.undo = append(b.Trail.undo, undo)
+	}
+}
+
+// Recording reports whether a checkpoint is open, so that callers can skip
+// building undo records nobody will use.
+func (b *RectBoard) Recording() bool {
+	return b.Trail != nil && len(b.Trail.marks) > 0
+}
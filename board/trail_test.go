@@ -0,0 +1,106 @@
+package board
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/set"
+)
+
+func TestRollbackBin(t *testing.T) {
+	b := RectBinBoardFromLines([]string{"...", "...", "..."})
+	b.Set(grid.Coord{X: 0, Y: 0}, grid.PAINTED)
+	before := b.Clone()
+
+	b.Checkpoint()
+	b.Set(grid.Coord{X: 1, Y: 0}, grid.CLEAR)
+	b.GuessValue(grid.Coord{X: 2, Y: 2}, grid.PAINTED)
+	outer := b.Clone()
+
+	b.Checkpoint()
+	b.Set(grid.Coord{X: 1, Y: 1}, grid.PAINTED)
+	b.GuessValue(grid.Coord{X: 0, Y: 2}, grid.CLEAR)
+	b.CommitGuess()
+	if !b.Rollback() {
+		t.Fatal("inner Rollback found no checkpoint")
+	}
+	checkBin(t, "inner rollback", b, outer)
+
+	b.Set(grid.Coord{X: 2, Y: 0}, grid.PAINTED)
+	if !b.Rollback() {
+		t.Fatal("outer Rollback found no checkpoint")
+	}
+	checkBin(t, "outer rollback", b, before)
+	if b.Rollback() {
+		t.Error("Rollback with no checkpoint open returned true")
+	}
+}
+
+func TestCommitKeepsChangesForOuterRollback(t *testing.T) {
+	b := RectBinBoardFromLines([]string{"..", ".."})
+	before := b.Clone()
+	b.Checkpoint()
+	b.Checkpoint()
+	b.Set(grid.Coord{X: 1, Y: 1}, grid.PAINTED)
+	b.Commit()
+	if !b.IsPainted(grid.Coord{X: 1, Y: 1}) {
+		t.Fatal("Commit undid the change")
+	}
+	b.Rollback()
+	checkBin(t, "outer rollback", b, before)
+}
+
+func checkBin(t *testing.T, when string, got, want *RectBinBoard) {
+	t.Helper()
+	if !reflect.DeepEqual(got.Grid, want.Grid) {
+		t.Errorf("after %s, Grid is %v; want %v", when, got.Grid, want.Grid)
+	}
+	if !reflect.DeepEqual(got.Guess, want.Guess) {
+		t.Errorf("after %s, Guess is %v; want %v", when, got.Guess, want.Guess)
+	}
+}
+
+func TestRollbackNum(t *testing.T) {
+	b := RectNumBoardFromNums([][]int{{1, 0, 0}, {0, 0, 0}, {0, 0, 0}})
+	b.Allowed = make([][]*set.Set[int], b.H)
+	for y := range b.Allowed {
+		b.Allowed[y] = make([]*set.Set[int], b.W)
+		for x := range b.Allowed[y] {
+			b.Allowed[y][x] = set.NewNumSet(3)
+		}
+	}
+	before := b.Clone()
+
+	b.Checkpoint()
+	b.Set(grid.Coord{X: 1, Y: 0}, 2)
+	b.Disallow(grid.Coord{X: 2, Y: 0}, 2)
+	b.GuessValue(grid.Coord{X: 1, Y: 1}, 3)
+	outer := b.Clone()
+
+	b.Checkpoint()
+	b.AllowOnly(grid.Coord{X: 2, Y: 0}, 3)
+	b.Disallow(grid.Coord{X: 0, Y: 2}, 1)
+	b.GuessValue(grid.Coord{X: 2, Y: 2}, 1)
+	b.CommitGuess()
+	b.Rollback()
+	checkNum(t, "inner rollback", b, outer)
+
+	b.Rollback()
+	checkNum(t, "outer rollback", b, before)
+}
+
+func checkNum(t *testing.T, when string, got, want *RectNumBoard) {
+	t.Helper()
+	if !reflect.DeepEqual(got.Grid, want.Grid) {
+		t.Errorf("after %s, Grid is %v; want %v", when, got.Grid, want.Grid)
+	}
+	if !reflect.DeepEqual(got.Guess, want.Guess) {
+		t.Errorf("after %s, Guess is %v; want %v", when, got.Guess, want.Guess)
+	}
+	for c := got.TopLeft(); got.IsValid(c); c = got.Next(c) {
+		if g, w := got.Allowed[c.Y][c.X].Sorted(), want.Allowed[c.Y][c.X].Sorted(); !reflect.DeepEqual(g, w) {
+			t.Errorf("after %s, candidates of %s are %v; want %v", when, c, g, w)
+		}
+	}
+}
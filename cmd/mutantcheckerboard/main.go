@@ -0,0 +1,611 @@
+// Command mutantcheckerboard solves the puzzles in the puzzle registry.
+// Run it with --list-types to see which ones those are.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamensky/argparse"
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+	"github.com/bismuthsalamander/mutantcheckerboard/render"
+)
+
+// countLimit returns the number of solutions worth enumerating for mode.
+// Uniqueness is settled as soon as a second solution turns up.
+func countLimit(mode string, limit int) int {
+	if mode == "unique" {
+		return 2
+	}
+	return limit
+}
+
+// ReportCount prints the result of a count or unique run. When the puzzle
+// has more than one solution, the first two are printed as a witness. If
+// err says the run timed out, n is only as many solutions as it found.
+func ReportCount[T fmt.Stringer](mode string, n int, limit int, witnesses []T, err error) {
+	timedOut := errors.Is(err, board.ErrTimedOut)
+	if timedOut {
+		fmt.Printf("Status: timed out\n")
+	}
+	if mode == "unique" {
+		switch {
+		case n >= 2:
+			fmt.Printf("Unique: false (more than one solution)\n")
+		case timedOut:
+			fmt.Printf("Unique: unknown (%d solution(s) found)\n", n)
+		case n == 0:
+			fmt.Printf("Unique: false (no solution)\n")
+		default:
+			fmt.Printf("Unique: true\n\n%s\n", witnesses[0])
+		}
+	} else if timedOut || (limit > 0 && n >= limit) {
+		fmt.Printf("Solutions: at least %d\n", n)
+	} else {
+		fmt.Printf("Solutions: %d\n", n)
+	}
+	if n >= 2 {
+		for i, w := range witnesses {
+			fmt.Printf("\nSolution %d:\n\n%s\n", i+1, w)
+		}
+	} else if n == 1 && mode != "unique" {
+		fmt.Printf("\n%s\n", witnesses[0])
+	}
+}
+
+// WriteTraceTo writes steps in the given format to the named file, or to
+// stderr if fn is empty. Does nothing if format is "none".
+func WriteTraceTo(fn string, steps []board.Step, format string) {
+	if format == "none" {
+		return
+	}
+	out := os.Stderr
+	if len(fn) > 0 {
+		f, err := os.Create(fn)
+		if err != nil {
+			fmt.Printf("error opening trace file: %s\n", err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := board.WriteTrace(out, steps, format); err != nil {
+		fmt.Printf("error writing trace: %s\n", err)
+	}
+}
+
+// ReportRating prints the result of a rate run.
+func ReportRating(r *board.Rating, err error) {
+	if errors.Is(err, board.ErrTimedOut) {
+		fmt.Printf("Status: timed out\n")
+		return
+	} else if err != nil {
+		fmt.Printf("error rating puzzle: %s\n", err)
+		return
+	}
+	fmt.Printf("%s\n", r)
+}
+
+// ReportHint loads the state in fn (if any) onto b and prints the cheapest
+// next deduction.
+func ReportHint(b puzzle.Hinter, fn string) {
+	if len(fn) > 0 {
+		lines, err := grid.LoadFile(fn)
+		if err != nil {
+			fmt.Printf("error loading state: %s\n", err)
+			return
+		}
+		if err := b.ApplyState(lines); err != nil {
+			fmt.Printf("error applying state: %s\n", err)
+			return
+		}
+	}
+	h, err := b.Hint()
+	if err != nil {
+		fmt.Printf("error finding hint: %s\n", err)
+	} else if h == nil {
+		fmt.Printf("No deduction available without guessing\n")
+	} else {
+		fmt.Printf("Hint: %s\n", h)
+	}
+}
+
+// loadPuzzle reads the puzzle in fn. A file ending in .json is read as a
+// puzzle.Document, whose type (if it names one) overrides typeName;
+// anything else is read as text. fn may also be a puzz.link URL instead of
+// a file name, and a file may hold just a URL; the type then comes from the
+// URL. A text file takes its type from typeName if it is set, else from
+// its puzzle.Header, else from its content; see puzzle.Detect.
+func loadPuzzle(typeName string, fn string) (puzzle.Type, puzzle.Puzzle, error) {
+	var d *puzzle.Document
+	var inp []string
+	if pzpr.IsURL(fn) {
+		inp = []string{fn}
+	} else if strings.HasSuffix(strings.ToLower(fn), ".json") {
+		var err error
+		d, err = puzzle.LoadDocument(fn)
+		if err != nil {
+			return puzzle.Type{}, nil, fmt.Errorf("error loading file: %w", err)
+		}
+		if len(d.Type) > 0 {
+			typeName = d.Type
+		}
+	} else {
+		var err error
+		inp, err = grid.LoadFile(fn)
+		if err != nil {
+			return puzzle.Type{}, nil, fmt.Errorf("error loading file: %w", err)
+		}
+	}
+	t, err := pickType(typeName, inp)
+	if err != nil {
+		return t, nil, err
+	}
+	p := t.New()
+	if d != nil {
+		dp, ok := p.(puzzle.Documenter)
+		if !ok {
+			return t, nil, fmt.Errorf("puzzle type \"%s\" cannot be read from JSON", t.Name)
+		}
+		if err := dp.ParseDocument(d); err != nil {
+			return t, nil, fmt.Errorf("error loading puzzle: %w", err)
+		}
+		return t, p, nil
+	}
+	if err := p.Parse(inp); err != nil {
+		return t, nil, fmt.Errorf("error loading puzzle: %w", err)
+	}
+	return t, p, nil
+}
+
+// pickType decides the puzzle type of a text puzzle made of lines: the one
+// its URL names, if it is a URL, or else typeName, the type in its header
+// or the one type whose content it matches, in that order.
+func pickType(typeName string, lines []string) (puzzle.Type, error) {
+	h, body, err := puzzle.SplitHeader(lines)
+	if err != nil {
+		return puzzle.Type{}, fmt.Errorf("error loading puzzle: %w", err)
+	}
+	if len(body) == 1 && pzpr.IsURL(body[0]) {
+		u, err := pzpr.ParseURL(body[0])
+		if err != nil {
+			return puzzle.Type{}, fmt.Errorf("error loading puzzle: %w", err)
+		}
+		t, ok := puzzle.LookupPzpr(u.Type)
+		if !ok {
+			return t, fmt.Errorf("no puzzle type for puzz.link type \"%s\"; see --list-types", u.Type)
+		}
+		return t, nil
+	}
+	if len(typeName) > 0 {
+		t, ok := puzzle.Lookup(typeName)
+		if !ok {
+			return t, fmt.Errorf("unrecognized puzzle type \"%s\"; see --list-types", typeName)
+		}
+		return t, nil
+	}
+	if t, ok, err := h.LookupType(); err != nil || ok {
+		return t, err
+	}
+	types := puzzle.Detect(body)
+	switch len(types) {
+	case 0:
+		return puzzle.Type{}, fmt.Errorf("cannot tell the puzzle type from the file; add a \"type:\" header or use -t")
+	case 1:
+		return types[0], nil
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name
+	}
+	return puzzle.Type{}, fmt.Errorf("file could be any of %s; add a \"type:\" header or use -t", strings.Join(names, ", "))
+}
+
+// LintFile checks the puzzle in fn for problems without solving it and
+// returns all of them. A text file goes through its type's puzzle.Linter
+// and, if that finds nothing, through a full load; a JSON document or a
+// URL only through the load, whose loader runs the type's Lint on the
+// puzzle it read, written out as text.
+func LintFile(typeName string, fn string) []error {
+	if pzpr.IsURL(fn) || strings.HasSuffix(strings.ToLower(fn), ".json") {
+		_, _, err := loadPuzzle(typeName, fn)
+		return splitErrors(err)
+	}
+	inp, err := grid.LoadFile(fn)
+	if err != nil {
+		return []error{fmt.Errorf("error loading file: %w", err)}
+	}
+	t, err := pickType(typeName, inp)
+	if err != nil {
+		return []error{err}
+	}
+	p := t.New()
+	if l, ok := p.(puzzle.Linter); ok {
+		if errs := l.Lint(inp); len(errs) > 0 {
+			return errs
+		}
+	}
+	return splitErrors(p.Parse(inp))
+}
+
+// splitErrors returns the errors joined in err (see errors.Join), looking
+// through any errors that wrap it, or err alone if it joins none.
+func splitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if j, ok := e.(interface{ Unwrap() []error }); ok {
+			return j.Unwrap()
+		}
+	}
+	return []error{err}
+}
+
+// PrintProblems prints the problems LintFile found in fn, one per line,
+// prefixed with fn and the line and column of the problem where there is
+// one, as compilers do.
+func PrintProblems(fn string, errs []error) {
+	for _, err := range errs {
+		var le *grid.LineError
+		switch {
+		case errors.As(err, &le) && le.Col > 0:
+			fmt.Printf("%s:%d:%d: %s\n", fn, le.Line, le.Col, le.Msg)
+		case errors.As(err, &le):
+			fmt.Printf("%s:%d: %s\n", fn, le.Line, le.Msg)
+		default:
+			fmt.Printf("%s: %s\n", fn, err)
+		}
+	}
+}
+
+// WriteDocumentTo writes p as a puzzle.Document to the named file, or to
+// stdout if fn is "-".
+func WriteDocumentTo(fn string, p puzzle.Puzzle) {
+	dp, ok := p.(puzzle.Documenter)
+	if !ok {
+		fmt.Printf("puzzle type cannot be written as JSON\n")
+		return
+	}
+	out := os.Stdout
+	if fn != "-" {
+		f, err := os.Create(fn)
+		if err != nil {
+			fmt.Printf("error opening JSON output file: %s\n", err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := dp.Document().Write(out); err != nil {
+		fmt.Printf("error writing JSON: %s\n", err)
+	}
+}
+
+// WriteCNFTo writes p as a DIMACS CNF file named fn, with the names of its
+// variables in fn.map.
+func WriteCNFTo(fn string, p puzzle.Encoder) error {
+	f := p.CNF()
+	out, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := f.WriteDIMACS(out); err != nil {
+		return err
+	}
+	mapOut, err := os.Create(fn + ".map")
+	if err != nil {
+		return err
+	}
+	defer mapOut.Close()
+	if err := f.WriteMap(mapOut); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s (%d variables, %d clauses) and %s.map\n", fn, f.NumVars, len(f.Clauses), fn)
+	return nil
+}
+
+// ApplyModelFrom fills in p from the SAT solver output in the named file.
+func ApplyModelFrom(p puzzle.Encoder, fn string) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	m, err := cnf.ReadModel(f)
+	if err != nil {
+		return err
+	}
+	return p.ApplyModel(m)
+}
+
+// renderPath returns the file to write a rendering to: fn if given, or
+// else the input file name with its extension replaced by ext.
+func renderPath(fn, input, ext string) string {
+	if len(fn) > 0 {
+		return fn
+	}
+	if pzpr.IsURL(input) {
+		return "puzzle." + ext
+	}
+	return strings.TrimSuffix(input, filepath.Ext(input)) + "." + ext
+}
+
+// WriteRender draws s in the given format to the named file. orig is the
+// scene of the puzzle as loaded, used to tell givens from solved cells.
+// Cells are cellSize pixels across, or the renderer's default if it is 0.
+func WriteRender(fn string, format string, orig, s *render.Scene, cellSize int, candidates bool) {
+	s.MarkClues(orig)
+	f, err := os.Create(fn)
+	if err != nil {
+		fmt.Printf("error opening render file: %s\n", err)
+		return
+	}
+	defer f.Close()
+	switch format {
+	case "svg":
+		err = render.WriteSVG(f, s, render.SVGOptions{CellSize: cellSize, Candidates: candidates})
+	case "png":
+		err = render.WritePNG(f, s, render.PNGOptions{CellSize: cellSize, Candidates: candidates})
+	}
+	if err != nil {
+		fmt.Printf("error rendering puzzle: %s\n", err)
+		return
+	}
+	fmt.Printf("Wrote %s\n", fn)
+}
+
+// PrintBoard prints p, in color with its candidates if color is set and p
+// can describe itself as a render.Scene. orig is the scene of the puzzle as
+// loaded, or nil if p is the puzzle as loaded.
+func PrintBoard(p puzzle.Puzzle, orig *render.Scene, color bool) {
+	sc, ok := p.(render.Scener)
+	if !color || !ok {
+		fmt.Printf("%s\n", p)
+		return
+	}
+	s := sc.Scene()
+	if orig == nil {
+		orig = s
+	}
+	s.MarkClues(orig)
+	if err := render.WriteANSI(os.Stdout, s); err != nil {
+		fmt.Printf("%s\n", p)
+	}
+}
+
+// unsupported reports that puzzle type t has no implementation of mode and
+// exits.
+func unsupported(t puzzle.Type, mode string) {
+	fmt.Printf("puzzle type \"%s\" does not support %s mode\n", t.Name, mode)
+	os.Exit(-1)
+}
+
+func main() {
+	parser := argparse.NewParser("mutantcheckerboard", "Solver for binary determination puzzles")
+	var puzzleType *string = parser.String("t", "type", &argparse.Options{
+		Help: "type of puzzle in the input file; see --list-types. Defaults to the type in the file's header, or else the type its content looks like",
+	})
+	var listTypes *bool = parser.Flag("", "list-types", &argparse.Options{
+		Help: "list the puzzle types that can be solved and exit",
+	})
+	var mode *string = parser.Selector("m", "mode", []string{"solve", "search", "count", "unique", "rate", "hint", "lint"}, &argparse.Options{
+		Default: "solve",
+		Help:    "solve: apply the deduction rules only; search: backtrack when the rules stall; count: count solutions up to --limit; unique: check for exactly one solution; rate: grade the puzzle by the techniques it needs; hint: show the cheapest next deduction from --state; lint: list the problems in the file without solving",
+	})
+	var limit *int = parser.Int("n", "limit", &argparse.Options{
+		Default: 100,
+		Help:    "stop counting after this many solutions (0 for no limit)",
+	})
+	var traceFormat *string = parser.Selector("", "trace", []string{"none", "text", "json"}, &argparse.Options{
+		Default: "none",
+		Help:    "record each deduction step and write it as text or JSON lines",
+	})
+	var traceFile *string = parser.String("", "trace-file", &argparse.Options{
+		Help: "write the trace to this file instead of stderr",
+	})
+	var stateFilename *string = parser.String("", "state", &argparse.Options{
+		Help: "partially solved state to start from in hint mode",
+	})
+	var timeout *string = parser.String("", "timeout", &argparse.Options{
+		Help: "stop solving, searching, counting or rating after this long (for example 30s or 2m) and print the partial result",
+	})
+	var printURL *bool = parser.Flag("", "url", &argparse.Options{
+		Help: "print the puzz.link URL of the puzzle as loaded",
+	})
+	var cnfOut *string = parser.String("", "cnf-out", &argparse.Options{
+		Help: "write the puzzle as loaded as a DIMACS CNF file for a SAT solver, with the variable names in the same name plus .map",
+	})
+	var satModel *string = parser.String("", "sat-model", &argparse.Options{
+		Help: "instead of solving, fill in the board from a SAT solver's model of the --cnf-out formula, then check it",
+	})
+	var jsonOut *string = parser.String("", "json-out", &argparse.Options{
+		Help: "after solving or searching, write the board, its candidates and wing ranges as JSON to this file (- for stdout)",
+	})
+	var renderFormat *string = parser.Selector("", "render", []string{"text", "svg", "png"}, &argparse.Options{
+		Default: "text",
+		Help:    "after solving or searching, also draw the board as svg or png to --render-file",
+	})
+	var renderFile *string = parser.String("", "render-file", &argparse.Options{
+		Help: "file to draw the board to; defaults to the input file name with the format's extension",
+	})
+	var cellSize *int = parser.Int("", "cell-size", &argparse.Options{
+		Default: 0,
+		Help:    "size of a cell in pixels when rendering (0 for the renderer's default)",
+	})
+	var highlightLast *bool = parser.Flag("", "highlight-last", &argparse.Options{
+		Help: "when rendering, highlight the cells changed by the last solver step",
+	})
+	var candidates *bool = parser.Flag("", "candidates", &argparse.Options{
+		Help: "draw the remaining candidates of empty cells when rendering",
+	})
+	var colorMode *string = parser.Selector("", "color", []string{"auto", "always", "never"}, &argparse.Options{
+		Default: "auto",
+		Help:    "draw boards with colors, box-drawing borders and candidates; auto does so only when stdout is a terminal",
+	})
+	var inputFilename *string = parser.StringPositional(nil)
+	err := parser.Parse(os.Args)
+	if err != nil {
+		fmt.Printf("error parsing command line arguments: %s", err)
+		os.Exit(-1)
+	}
+	if *listTypes {
+		for _, t := range puzzle.Types() {
+			fmt.Printf("%-10s %s\n", t.Name, t.Description)
+		}
+		return
+	}
+	if inputFilename == nil || len(*inputFilename) == 0 {
+		fmt.Printf("usage: %s [-t puzzle type] [input filename]\n", os.Args[0])
+		os.Exit(-1)
+	}
+	ctx := context.Background()
+	if len(*timeout) > 0 {
+		d, err := time.ParseDuration(*timeout)
+		if err != nil {
+			fmt.Printf("error parsing timeout: %s\n", err)
+			os.Exit(-1)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	if *mode == "lint" {
+		if errs := LintFile(*puzzleType, *inputFilename); len(errs) > 0 {
+			PrintProblems(*inputFilename, errs)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: OK\n", *inputFilename)
+		return
+	}
+	t, p, err := loadPuzzle(*puzzleType, *inputFilename)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		os.Exit(-1)
+	}
+	color := *colorMode == "always" || (*colorMode == "auto" && render.IsTerminal(os.Stdout))
+	if dp, ok := p.(puzzle.Described); ok {
+		if m := dp.Metadata(); *m != (board.Meta{}) {
+			fmt.Printf("%s\n", puzzle.Header{Author: m.Author, Source: m.Source})
+		}
+	}
+	PrintBoard(p, nil, color)
+	var origScene *render.Scene
+	if sc, ok := p.(render.Scener); ok {
+		origScene = sc.Scene()
+	} else if *renderFormat != "text" {
+		unsupported(t, "render")
+	}
+	if *printURL {
+		if l, ok := p.(puzzle.Linker); ok {
+			fmt.Printf("URL: %s\n", l.URL())
+		} else {
+			fmt.Printf("puzzle type \"%s\" has no puzz.link URL format; --url is not supported\n", t.Name)
+			os.Exit(-1)
+		}
+	}
+
+	if len(*cnfOut) > 0 {
+		e, ok := p.(puzzle.Encoder)
+		if !ok {
+			unsupported(t, "CNF")
+		}
+		if err := WriteCNFTo(*cnfOut, e); err != nil {
+			fmt.Printf("error writing CNF: %s\n", err)
+		}
+	}
+
+	switch *mode {
+	case "count", "unique":
+		c, ok := p.(puzzle.Counter)
+		if !ok {
+			unsupported(t, *mode)
+		}
+		n, witnesses, err := c.CountSolutionsContext(ctx, countLimit(*mode, *limit))
+		ReportCount(*mode, n, *limit, witnesses, err)
+		return
+	case "hint":
+		h, ok := p.(puzzle.Hinter)
+		if !ok {
+			unsupported(t, *mode)
+		}
+		ReportHint(h, *stateFilename)
+		return
+	}
+
+	var steps func() []board.Step
+	if tr, ok := p.(puzzle.Tracer); ok && *traceFormat != "none" {
+		tr.EnableTrace()
+		steps = tr.Steps
+	} else if *traceFormat != "none" {
+		unsupported(t, "trace")
+	}
+	if tr, ok := p.(puzzle.Tracer); ok && steps == nil && *highlightLast {
+		tr.EnableTrace()
+		steps = tr.Steps
+	}
+	switch {
+	case len(*satModel) > 0:
+		e, ok := p.(puzzle.Encoder)
+		if !ok {
+			unsupported(t, "CNF")
+		}
+		if err = ApplyModelFrom(e, *satModel); err != nil {
+			err = fmt.Errorf("error applying SAT model: %w", err)
+		}
+	case *mode == "rate":
+		r, ok := p.(puzzle.Rater)
+		if !ok {
+			unsupported(t, *mode)
+		}
+		ReportRating(r.RateContext(ctx))
+		if steps != nil {
+			WriteTraceTo(*traceFile, steps(), *traceFormat)
+		}
+		return
+	case *mode == "search":
+		s, ok := p.(puzzle.Searcher)
+		if !ok {
+			unsupported(t, *mode)
+		}
+		_, err = s.SearchContext(ctx)
+	default:
+		err = p.SolveContext(ctx)
+	}
+	if errors.Is(err, board.ErrTimedOut) {
+		fmt.Printf("Status: timed out\n")
+	} else if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+	if steps != nil {
+		WriteTraceTo(*traceFile, steps(), *traceFormat)
+	}
+	PrintBoard(p, origScene, color)
+	solved, err := p.IsSolved()
+	if err != nil {
+		fmt.Printf("Solved: %v (%s)\n", solved, err)
+	} else {
+		fmt.Printf("Solved: %v\n", solved)
+	}
+	if len(*jsonOut) > 0 {
+		WriteDocumentTo(*jsonOut, p)
+	}
+	if *renderFormat != "text" {
+		fn := renderPath(*renderFile, *inputFilename, *renderFormat)
+		s := p.(render.Scener).Scene()
+		if *highlightLast && steps != nil && len(steps()) > 0 {
+			s.HighlightStep(steps()[len(steps())-1])
+		}
+		WriteRender(fn, *renderFormat, origScene, s, *cellSize, *candidates)
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+// The puzzle types this command can solve. Each one adds itself to the
+// puzzle registry when it is imported.
+import (
+	_ "github.com/bismuthsalamander/mutantcheckerboard/heyawake"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/hitori"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/kuromasu"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/nonogram"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/nurikabe"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/ripple"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/starbattle"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/towers"
+)
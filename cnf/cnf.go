@@ -0,0 +1,228 @@
+// Package cnf builds SAT problems in conjunctive normal form and writes them
+// in the DIMACS format that external SAT solvers read, so that a puzzle can
+// be handed to a solver such as minisat or kissat and the solver's model
+// read back.
+//
+// Variables are numbered from 1. A literal is a variable, or its negation
+// written as a negative number, as in DIMACS.
+package cnf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formula is a set of clauses over NumVars variables. Names holds a name for
+// the variables that stand for something in the puzzle, such as a cell
+// holding a value; the auxiliary variables the encodings add have none.
+type Formula struct {
+	NumVars  int
+	Clauses  [][]int
+	Names    map[int]string
+	Comments []string
+	t        int
+}
+
+// New returns an empty formula.
+func New() *Formula {
+	return &Formula{Names: make(map[int]string)}
+}
+
+// NewVar adds a variable with the given name, which may be empty, and
+// returns it.
+func (f *Formula) NewVar(name string) int {
+	f.NumVars++
+	if len(name) > 0 {
+		f.Names[f.NumVars] = name
+	}
+	return f.NumVars
+}
+
+// Add adds a clause: at least one of lits is true.
+func (f *Formula) Add(lits ...int) {
+	f.Clauses = append(f.Clauses, append([]int(nil), lits...))
+}
+
+// True returns a literal that is always true. Its negation is always
+// false.
+func (f *Formula) True() int {
+	if f.t == 0 {
+		f.t = f.NewVar("")
+		f.Add(f.t)
+	}
+	return f.t
+}
+
+// Or returns a new variable that is true exactly when one of lits is.
+func (f *Formula) Or(lits ...int) int {
+	if len(lits) == 0 {
+		return -f.True()
+	}
+	z := f.NewVar("")
+	big := []int{-z}
+	for _, l := range lits {
+		f.Add(z, -l)
+		big = append(big, l)
+	}
+	f.Add(big...)
+	return z
+}
+
+// And returns a new variable that is true exactly when all of lits are.
+func (f *Formula) And(lits ...int) int {
+	if len(lits) == 0 {
+		return f.True()
+	}
+	z := f.NewVar("")
+	big := []int{z}
+	for _, l := range lits {
+		f.Add(-z, l)
+		big = append(big, -l)
+	}
+	f.Add(big...)
+	return z
+}
+
+// AtMostOne adds the clauses that keep two of lits from both being true.
+// It uses the pairwise encoding, which needs no new variables and suits
+// the short lists puzzles have.
+func (f *Formula) AtMostOne(lits []int) {
+	for i := range lits {
+		for j := i + 1; j < len(lits); j++ {
+			f.Add(-lits[i], -lits[j])
+		}
+	}
+}
+
+// ExactlyOne adds the clauses that make exactly one of lits true.
+func (f *Formula) ExactlyOne(lits []int) {
+	f.Add(lits...)
+	f.AtMostOne(lits)
+}
+
+// Exactly adds the clauses that make exactly k of lits true, with a
+// sequential counter: r[i][j] says that at least j of the first i literals
+// are true.
+func (f *Formula) Exactly(lits []int, k int) {
+	if k < 0 || k > len(lits) {
+		f.Add()
+		return
+	}
+	t := f.True()
+	prev := make([]int, k+2)
+	prev[0] = t
+	for j := 1; j < len(prev); j++ {
+		prev[j] = -t
+	}
+	for _, l := range lits {
+		cur := make([]int, k+2)
+		cur[0] = t
+		for j := 1; j < len(cur); j++ {
+			// cur[j] <=> prev[j] or (l and prev[j-1])
+			z := f.NewVar("")
+			f.Add(-prev[j], z)
+			f.Add(-l, -prev[j-1], z)
+			f.Add(-z, prev[j], l)
+			f.Add(-z, prev[j], prev[j-1])
+			cur[j] = z
+		}
+		prev = cur
+	}
+	f.Add(prev[k])
+	f.Add(-prev[k+1])
+}
+
+// WriteDIMACS writes f to w in the DIMACS CNF format, with its Comments as
+// "c" lines at the top.
+func (f *Formula) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range f.Comments {
+		fmt.Fprintf(bw, "c %s\n", c)
+	}
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.NumVars, len(f.Clauses))
+	for _, cl := range f.Clauses {
+		for _, l := range cl {
+			bw.WriteString(strconv.Itoa(l))
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+	return bw.Flush()
+}
+
+// WriteMap writes the names of f's named variables to w, one "var name"
+// line each in variable order, for reading a model by hand or with other
+// tools.
+func (f *Formula) WriteMap(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for v := 1; v <= f.NumVars; v++ {
+		if name, ok := f.Names[v]; ok {
+			fmt.Fprintf(bw, "%d %s\n", v, name)
+		}
+	}
+	return bw.Flush()
+}
+
+// Model is an assignment of the variables of a formula, as a SAT solver
+// reports it.
+type Model map[int]bool
+
+// Value returns whether literal l is true in m. Variables the model leaves
+// out are false.
+func (m Model) Value(l int) bool {
+	if l < 0 {
+		return !m[-l]
+	}
+	return m[l]
+}
+
+// ErrUnsatisfiable is returned by ReadModel when the solver output says
+// that the formula has no model.
+var ErrUnsatisfiable = fmt.Errorf("solver reports the formula unsatisfiable")
+
+// ReadModel reads a model in either of the usual output formats: the SAT
+// competition one, with an "s SATISFIABLE" line and "v" lines of literals,
+// or minisat's, with "SAT" on the first line and the literals after it.
+// Comment lines starting with "c" are skipped and a 0 ends the literals.
+func ReadModel(r io.Reader) (Model, error) {
+	m := make(Model)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<26)
+	line := 0
+	for sc.Scan() {
+		line++
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		switch strings.ToUpper(strings.Join(fields, " ")) {
+		case "S UNSATISFIABLE", "UNSAT", "UNSATISFIABLE":
+			return nil, ErrUnsatisfiable
+		case "S SATISFIABLE", "SAT", "SATISFIABLE":
+			continue
+		}
+		if fields[0] == "v" {
+			fields = fields[1:]
+		}
+		for _, tok := range fields {
+			l, err := strconv.Atoi(tok)
+			if err != nil {
+				return nil, fmt.Errorf("model line %d: %q is not a literal", line, tok)
+			}
+			if l == 0 {
+				return m, nil
+			}
+			m[max(l, -l)] = l > 0
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("model is empty")
+	}
+	return m, nil
+}
@@ -0,0 +1,259 @@
+package cnf_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+
+	_ "github.com/bismuthsalamander/mutantcheckerboard/heyawake"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/hitori"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/kuromasu"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/nonogram"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/nurikabe"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/ripple"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/starbattle"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/towers"
+)
+
+// solve finds a model of clauses over vars variables by DPLL, trying the
+// variables in order, then the rest, and false before true. It is only
+// meant for the small formulas of these tests.
+func solve(clauses [][]int, vars int, order []int) (cnf.Model, bool) {
+	val := make([]int8, vars+1)
+	order = append([]int(nil), order...)
+	for v := 1; v <= vars; v++ {
+		order = append(order, v)
+	}
+	var search func() bool
+	search = func() bool {
+		saved := append([]int8(nil), val...)
+		if !propagate(clauses, val) {
+			copy(val, saved)
+			return false
+		}
+		for _, v := range order {
+			if val[v] != 0 {
+				continue
+			}
+			for _, try := range []int8{-1, 1} {
+				val[v] = try
+				if search() {
+					return true
+				}
+				val[v] = 0
+			}
+			copy(val, saved)
+			return false
+		}
+		return true
+	}
+	if !search() {
+		return nil, false
+	}
+	m := make(cnf.Model)
+	for v := 1; v <= vars; v++ {
+		m[v] = val[v] > 0
+	}
+	return m, true
+}
+
+// propagate assigns the last open literal of every clause whose other
+// literals are false, until nothing changes. Returns false if a clause
+// has every literal false.
+func propagate(clauses [][]int, val []int8) bool {
+	for changed := true; changed; {
+		changed = false
+		for _, cl := range clauses {
+			open, last := 0, 0
+			sat := false
+			for _, l := range cl {
+				v := val[max(l, -l)]
+				if l < 0 {
+					v = -v
+				}
+				if v > 0 {
+					sat = true
+					break
+				} else if v == 0 {
+					open++
+					last = l
+				}
+			}
+			if sat {
+				continue
+			}
+			if open == 0 {
+				return false
+			}
+			if open == 1 {
+				if last > 0 {
+					val[last] = 1
+				} else {
+					val[-last] = -1
+				}
+				changed = true
+			}
+		}
+	}
+	return true
+}
+
+// countModels counts the models of f that differ on the variables in
+// project, blocking each one found, up to limit.
+func countModels(f *cnf.Formula, project []int, limit int) int {
+	clauses := append([][]int(nil), f.Clauses...)
+	n := 0
+	for ; n < limit; n++ {
+		m, ok := solve(clauses, f.NumVars, project)
+		if !ok {
+			break
+		}
+		block := make([]int, 0, len(project))
+		for _, v := range project {
+			if m[v] {
+				block = append(block, -v)
+			} else {
+				block = append(block, v)
+			}
+		}
+		clauses = append(clauses, block)
+	}
+	return n
+}
+
+func TestExactly(t *testing.T) {
+	binomial := func(n, k int) int {
+		out := 1
+		for i := 0; i < k; i++ {
+			out = out * (n - i) / (i + 1)
+		}
+		return out
+	}
+	for n := 1; n <= 5; n++ {
+		for k := -1; k <= n+1; k++ {
+			f := cnf.New()
+			lits := make([]int, 0, n)
+			for i := 0; i < n; i++ {
+				lits = append(lits, f.NewVar(""))
+			}
+			f.Exactly(lits, k)
+			want := 0
+			if k >= 0 && k <= n {
+				want = binomial(n, k)
+			}
+			if got := countModels(f, lits, 100); got != want {
+				t.Errorf("Exactly(%d of %d) has %d models; want %d", k, n, got, want)
+			}
+		}
+	}
+}
+
+func TestGates(t *testing.T) {
+	f := cnf.New()
+	a, b, c := f.NewVar("a"), f.NewVar("b"), f.NewVar("c")
+	and, or := f.And(a, -b, c), f.Or(a, -b, c)
+	f.ExactlyOne([]int{a, b, c})
+	for mask := 0; mask < 8; mask++ {
+		g := &cnf.Formula{NumVars: f.NumVars, Clauses: append([][]int(nil), f.Clauses...)}
+		in := map[int]bool{a: mask&1 != 0, b: mask&2 != 0, c: mask&4 != 0}
+		for v, on := range in {
+			if on {
+				g.Add(v)
+			} else {
+				g.Add(-v)
+			}
+		}
+		ones := 0
+		for _, on := range in {
+			if on {
+				ones++
+			}
+		}
+		m, ok := solve(g.Clauses, g.NumVars, []int{a, b, c})
+		if ok != (ones == 1) {
+			t.Errorf("a, b, c = %v: satisfiable %v; want %v", in, ok, ones == 1)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if m.Value(and) != (in[a] && !in[b] && in[c]) {
+			t.Errorf("a, b, c = %v: And is %v", in, m.Value(and))
+		}
+		if m.Value(or) != (in[a] || !in[b] || in[c]) {
+			t.Errorf("a, b, c = %v: Or is %v", in, m.Value(or))
+		}
+	}
+}
+
+func TestPuzzleModels(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		typeName string
+		lines    []string
+		cells    string
+	}{
+		// Without a cross to start from, the clear cells must still connect.
+		{"no clues", "kuromasu", []string{"___", "___", "___"}, "painted("},
+		{"unique", "kuromasu", []string{"3___", "__5_", "____", "___2"}, "painted("},
+		{"corner pair", "kuromasu", []string{"2___", "____", "____", "___7"}, "painted("},
+		{"unique", "hitori", []string{"3144", "4241", "1234", "3424"}, "painted("},
+		{"six", "hitori", []string{"3121", "1334", "4213", "2412"}, "painted("},
+		{"unique", "nurikabe", []string{"....", "...4", "....", "...3"}, "painted("},
+		{"two islands", "nurikabe", []string{"..3.", "....", "4...", "...."}, "painted("},
+		{"unique", "heyawake", []string{"aabb", "aabb", "ccdd", "ccdd", "----", "0.0.", "....", "0.2.", "...."}, "painted("},
+		{"no counts", "heyawake", []string{"aab", "aab", "ccc", "---", "...", "...", "..."}, "painted("},
+		{"quadrants", "starbattle", []string{"aabb", "aabb", "ccdd", "ccdd"}, "star("},
+		{"no solution", "starbattle", []string{"aaab", "accb", "dccb", "dddb"}, "star("},
+		{"checkers", "nonogram", []string{"1,1", "1,1", "1,1", "1,1", "---", "2", "2", "2", "2"}, "painted("},
+		{"permutations", "nonogram", []string{"1", "1", "1", "---", "1", "1", "1"}, "painted("},
+		{"one clue", "regions", []string{"aabb", "aabb", "ccdd", "ccdd", "----", "1", ".", ".", "."}, "cell("},
+		{"unique", "towers", []string{"4", " 3214 ", "3    2", "2    2", "1    2", "4    1", " 2221 "}, "cell("},
+		{"open", "towers", []string{"3", "     ", "3    ", "     ", "     ", "     "}, "cell("},
+	} {
+		t.Run(tt.typeName+"/"+tt.name, func(t *testing.T) {
+			typ, ok := puzzle.Lookup(tt.typeName)
+			if !ok {
+				t.Fatalf("puzzle type %q is not registered", tt.typeName)
+			}
+			p := typ.New()
+			if err := p.Parse(tt.lines); err != nil {
+				t.Fatal(err)
+			}
+			want, _, _ := p.(puzzle.Counter).CountSolutionsContext(context.Background(), 0)
+			f := p.(puzzle.Encoder).CNF()
+			cells := make([]int, 0)
+			for v := 1; v <= f.NumVars; v++ {
+				if strings.HasPrefix(f.Names[v], tt.cells) {
+					cells = append(cells, v)
+				}
+			}
+			if got := countModels(f, cells, want+10); got != want {
+				t.Errorf("formula has %d models; the puzzle has %d solutions", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyModel(t *testing.T) {
+	typ, _ := puzzle.Lookup("kuromasu")
+	p := typ.New()
+	if err := p.Parse([]string{"3___", "__5_", "____", "___2"}); err != nil {
+		t.Fatal(err)
+	}
+	e := p.(puzzle.Encoder)
+	f := e.CNF()
+	m, ok := solve(f.Clauses, f.NumVars, nil)
+	if !ok {
+		t.Fatal("formula has no model")
+	}
+	if err := e.ApplyModel(m); err != nil {
+		t.Fatal(err)
+	}
+	if solved, err := p.IsSolved(); !solved {
+		t.Errorf("model does not solve the puzzle: %v\n%s", err, p)
+	}
+}
@@ -2,4 +2,4 @@ module github.com/bismuthsalamander/mutantcheckerboard
 
 go 1.22.0
 
-require github.com/akamensky/argparse v1.4.0 // indirect
+require github.com/akamensky/argparse v1.4.0
@@ -0,0 +1,55 @@
+package grid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CellFormat lays out the cells of a printed grid. The narrow format, the
+// zero value, writes every cell as one character, with IntToCh for
+// numbers. A wide format, needed once a value is too large for IntToCh,
+// writes every cell as a space followed by Width columns, right-aligned,
+// which reads back as a tokenized grid (see IsTokenGrid).
+type CellFormat struct {
+	Width int
+}
+
+// FormatFor returns the narrow format if every value up to max fits in a
+// character, and otherwise a wide format just wide enough for max.
+func FormatFor(max int) CellFormat {
+	if max <= 35 {
+		return CellFormat{}
+	}
+	return CellFormat{Width: len(strconv.Itoa(max))}
+}
+
+// Wide reports whether f writes more than one character per cell.
+func (f CellFormat) Wide() bool {
+	return f.Width > 0
+}
+
+// Len returns the number of columns a cell takes.
+func (f CellFormat) Len() int {
+	if !f.Wide() {
+		return 1
+	}
+	return f.Width + 1
+}
+
+// Num formats the number n.
+func (f CellFormat) Num(n int) string {
+	if !f.Wide() {
+		return string(IntToCh(n))
+	}
+	return fmt.Sprintf(" %*d", f.Width, n)
+}
+
+// Mark formats a cell that holds something other than a number, such as
+// the X of a painted cell.
+func (f CellFormat) Mark(r rune) string {
+	if !f.Wide() {
+		return string(r)
+	}
+	return strings.Repeat(" ", f.Width) + string(r)
+}
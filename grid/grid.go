@@ -0,0 +1,226 @@
+// Package grid holds the geometry every puzzle is built on: coordinates,
+// directions, the value of a binary cell, and the characters used to print
+// and read cell values.
+package grid
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/set"
+)
+
+// Coord is the position of a cell; X grows to the right and Y grows down.
+// In JSON it is an object with members x and y.
+type Coord struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func (c Coord) String() string {
+	return fmt.Sprintf("(%d,%d)", c.X, c.Y)
+}
+
+// Delta is the offset between two cells, written in JSON like a Coord.
+type Delta struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func (d Delta) String() string {
+	if d == LEFT {
+		return "LEFT"
+	} else if d == RIGHT {
+		return "RIGHT"
+	} else if d == DOWN {
+		return "DOWN"
+	} else if d == UP {
+		return "UP"
+	}
+	return fmt.Sprintf("(%d,%d)", d.X, d.Y)
+}
+
+func (d Delta) TurnCW() Delta {
+	return Delta{
+		X: d.Y * -1,
+		Y: d.X,
+	}
+}
+
+func (d Delta) TurnCCW() Delta {
+	return Delta{
+		X: d.Y,
+		Y: d.X * -1,
+	}
+}
+
+func (d Delta) Reverse() Delta {
+	return Delta{
+		d.X * -1,
+		d.Y * -1,
+	}
+}
+
+func (d Delta) Times(n int) Delta {
+	return Delta{d.X * n, d.Y * n}
+}
+
+// The four directions a cell has neighbors in.
+var LEFT = Delta{-1, 0}
+
+var RIGHT = Delta{1, 0}
+
+var UP = Delta{0, -1}
+
+var DOWN = Delta{0, 1}
+
+var DIRECTIONS = []Delta{LEFT, RIGHT, UP, DOWN}
+
+// Minus returns the offset from o to c.
+func (c Coord) Minus(o Coord) Delta {
+	d := Delta{
+		c.X - o.X,
+		c.Y - o.Y,
+	}
+	if d.X < 0 {
+		d.X *= -1
+	}
+	if d.Y < 0 {
+		d.Y *= -1
+	}
+	return d
+}
+
+// MHDist returns the Manhattan distance between c and o.
+func (c Coord) MHDist(o Coord) int {
+	val := 0
+	if c.X > o.X {
+		val += c.X - o.X
+	} else {
+		val += o.X - c.X
+	}
+	if c.Y > o.Y {
+		val += c.Y - o.Y
+	} else {
+		val += o.Y - c.Y
+	}
+	return val
+}
+
+// Plus returns the cell at offset d from c.
+func (c Coord) Plus(d Delta) Coord {
+	return Coord{
+		X: c.X + d.X,
+		Y: c.Y + d.Y,
+	}
+}
+
+// Cell is the value of a cell on a binary board.
+type Cell int8
+
+// The values a Cell can hold. UNKNOWN is also the empty value on a numeric
+// board.
+const UNKNOWN = 0
+
+const PAINTED = 1
+
+const CLEAR = 2
+
+func (c Cell) Ch() rune {
+	if c == PAINTED {
+		return 'X'
+	} else if c == CLEAR {
+		return '·'
+	}
+	return ' '
+}
+
+func (c Cell) String() string {
+	return string(c.Ch())
+}
+
+// IntToCh returns the character used to print value n: a digit up to 9,
+// then lowercase letters up to 35, and ? for anything larger.
+func IntToCh(n int) rune {
+	if n < 10 {
+		return rune(int('0') + n)
+	} else if n <= 35 {
+		return rune(int('a') + (n - 10))
+	}
+	return '?'
+}
+
+// CharToNum is the inverse of IntToCh. The bool is false if ch is not a
+// value.
+func CharToNum(ch rune) (int, bool) {
+	if ch >= '0' && ch <= '9' {
+		return int(ch - '0'), true
+	}
+	if ch >= 'a' && ch <= 'z' {
+		return int(ch-'a') + 10, true
+	}
+	return 0, false
+}
+
+// MakeGrid returns a w by h grid of UNKNOWN cells.
+func MakeGrid(w, h int) [][]Cell {
+	g := make([][]Cell, 0, h)
+	for i := 0; i < int(h); i++ {
+		g = append(g, make([]Cell, w))
+	}
+	return g
+}
+
+// MakeNumGrid returns a w by h grid of empty numeric cells.
+func MakeNumGrid(w, h int) [][]int {
+	g := make([][]int, 0, h)
+	for i := 0; i < int(h); i++ {
+		g = append(g, make([]int, w))
+	}
+	return g
+}
+
+// CopyGrid returns a deep copy of a cell grid.
+func CopyGrid(g [][]Cell) [][]Cell {
+	out := make([][]Cell, 0, len(g))
+	for _, row := range g {
+		out = append(out, append([]Cell(nil), row...))
+	}
+	return out
+}
+
+// CopyNumGrid returns a deep copy of a number grid.
+func CopyNumGrid(g [][]int) [][]int {
+	out := make([][]int, 0, len(g))
+	for _, row := range g {
+		out = append(out, append([]int(nil), row...))
+	}
+	return out
+}
+
+// NewCoordSet returns an empty set of coordinates that sorts in reading
+// order.
+func NewCoordSet() *set.Set[Coord] {
+	return set.NewSet[Coord](func(a, b Coord) int {
+		if a.Y < b.Y {
+			return -1
+		} else if a.Y > b.Y {
+			return 1
+		} else if a.X < b.X {
+			return -1
+		} else if a.X > b.X {
+			return 1
+		}
+		return 0
+	})
+}
+
+// CellName returns the word used for a binary cell value in traces and
+// hints.
+func CellName(v Cell) string {
+	if v == PAINTED {
+		return "painted"
+	} else if v == CLEAR {
+		return "clear"
+	}
+	return "unknown"
+}
@@ -0,0 +1,285 @@
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LineError is a problem at a place in a puzzle file. Line counts from 1;
+// Col counts cells from 1, or is 0 if the problem is with the whole line.
+type LineError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *LineError) Error() string {
+	if e.Col > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Col, e.Msg)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// LineErrorf builds a LineError with a formatted message.
+func LineErrorf(line, col int, format string, args ...any) *LineError {
+	return &LineError{Line: line, Col: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// ShiftLines adds n to the line of err if it is a LineError, or of every
+// LineError in it if it wraps several (see errors.Join), for callers that
+// pass only the lines after the first n of a file on to a loader. Returns
+// err.
+func ShiftLines(err error, n int) error {
+	if n == 0 {
+		return err
+	}
+	switch e := err.(type) {
+	case *LineError:
+		e.Line += n
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			ShiftLines(inner, n)
+		}
+	case interface{ Unwrap() error }:
+		ShiftLines(e.Unwrap(), n)
+	}
+	return err
+}
+
+// LintLines runs lint, a puzzle type's Lint, on lines: the text form of
+// a puzzle that was read some other way, such as from a URL or a JSON
+// document. The problems it finds are joined with errors.Join, or nil if
+// there are none. A LineError among them becomes a plain error that says
+// where in the text form it is, since it has no place in what was read.
+func LintLines(lint func([]string) []error, lines []string) error {
+	errs := lint(lines)
+	for i, err := range errs {
+		var le *LineError
+		if errors.As(err, &le) {
+			errs[i] = fmt.Errorf("puzzle as text, %s", le)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadFile reads a puzzle file into lines, dropping line endings and any
+// blank lines at the start and end.
+func LoadFile(fn string) ([]string, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0)
+	for _, txt := range strings.Split(string(data), "\n") {
+		lines = append(lines, strings.Trim(txt, "\r\n"))
+	}
+	for len(lines) > 0 && len(lines[0]) == 0 {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines, nil
+}
+
+// LinesToIntGrid converts every character of lines with CharToNum; anything
+// that is not a value becomes UNKNOWN.
+func LinesToIntGrid(lines []string) ([][]int, error) {
+	grid := make([][]int, 0)
+	for _, l := range lines {
+		row := make([]int, len(l))
+		for col, ch := range l {
+			num, ok := CharToNum(ch)
+			if ok {
+				row[col] = num
+			}
+		}
+		grid = append(grid, row)
+	}
+	return grid, nil
+}
+
+// A tokenized grid writes one cell per token instead of one per character,
+// so that it can hold values too large for IntToCh:
+//
+//	12  .  .  3
+//	 .  . 40  .
+//
+// or, the same grid,
+//
+//	12,,,3
+//	,,40,
+//
+// Tokens are separated by commas or, on lines without commas, by runs of
+// whitespace. A token is a decimal number, or "." or "_" for an empty cell;
+// between commas, an empty token is an empty cell too.
+
+// SplitTokens splits a line of a tokenized grid into its tokens.
+func SplitTokens(line string) []string {
+	if !strings.Contains(line, ",") {
+		return strings.Fields(line)
+	}
+	toks := strings.Split(line, ",")
+	for i, t := range toks {
+		toks[i] = strings.TrimSpace(t)
+	}
+	return toks
+}
+
+// parseToken reads one token of a tokenized grid.
+func parseToken(tok string) (int, bool) {
+	if tok == "" || tok == "." || tok == "_" {
+		return UNKNOWN, true
+	}
+	n, err := strconv.Atoi(tok)
+	if err != nil || n < 0 || tok[0] == '+' {
+		return 0, false
+	}
+	return n, true
+}
+
+// IsTokenGrid reports whether lines are a tokenized grid rather than one
+// character per cell. Every line must split into valid tokens, and
+// something must rule out the character format: a comma, a lone blank
+// token, or a multi-digit number on lines that all have the same number of
+// tokens, more than one; a line of digits alone is a row of one-character
+// cells.
+func IsTokenGrid(lines []string) bool {
+	marked, wide := false, false
+	count := -1
+	for _, l := range lines {
+		toks := SplitTokens(l)
+		if len(toks) == 0 {
+			return false
+		}
+		if count >= 0 && len(toks) != count {
+			count = -2
+		} else if count == -1 {
+			count = len(toks)
+		}
+		if strings.Contains(l, ",") {
+			marked = true
+		}
+		for _, t := range toks {
+			if _, ok := parseToken(t); !ok {
+				return false
+			}
+			if t == "." || t == "_" {
+				marked = true
+			} else if len(t) > 1 {
+				wide = true
+			}
+		}
+	}
+	return marked || (wide && count > 1)
+}
+
+// TokensToIntGrid reads a tokenized grid. Rows may have different lengths;
+// checking them is up to the caller.
+func TokensToIntGrid(lines []string) ([][]int, error) {
+	out := make([][]int, 0, len(lines))
+	for y, l := range lines {
+		toks := SplitTokens(l)
+		row := make([]int, len(toks))
+		for x, t := range toks {
+			n, ok := parseToken(t)
+			if !ok {
+				return nil, LineErrorf(y+1, x+1, "%q is not a number, \".\" or \"_\"", t)
+			}
+			row[x] = n
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// LinesToNumGrid reads lines as a tokenized grid if IsTokenGrid says they
+// are one, and with LinesToIntGrid otherwise.
+func LinesToNumGrid(lines []string) ([][]int, error) {
+	if IsTokenGrid(lines) {
+		return TokensToIntGrid(lines)
+	}
+	return LinesToIntGrid(lines)
+}
+
+// NumGridToLines is the inverse of LinesToNumGrid: it writes nums in the
+// format FormatFor picks for its largest value, with "." for every cell
+// that holds blank.
+func NumGridToLines(nums [][]int, blank int) []string {
+	top := 0
+	for _, row := range nums {
+		for _, v := range row {
+			if v != blank {
+				top = max(top, v)
+			}
+		}
+	}
+	f := FormatFor(top)
+	lines := make([]string, len(nums))
+	for y, row := range nums {
+		var sb strings.Builder
+		for _, v := range row {
+			if v == blank {
+				sb.WriteString(f.Mark('.'))
+			} else {
+				sb.WriteString(f.Num(v))
+			}
+		}
+		lines[y] = sb.String()
+	}
+	return lines
+}
+
+// Width returns the width of nums, the grid LinesToNumGrid read from
+// lines, and a LineError for every row whose length differs from it. The
+// width is the length of the first row, with one exception: in a grid of
+// one character per cell that writes blank cells as spaces, an editor that
+// trims trailing blanks leaves rows short, so the width is that of the
+// longest row and shorter rows are read as ending in blank cells.
+func Width(lines []string, nums [][]int) (int, []error) {
+	errs := make([]error, 0)
+	if len(nums) == 0 {
+		return 0, errs
+	}
+	w := len(nums[0])
+	trimmed := !IsTokenGrid(lines) && strings.Contains(strings.Join(lines, ""), " ")
+	if trimmed {
+		for _, row := range nums {
+			w = max(w, len(row))
+		}
+	}
+	for y, row := range nums {
+		if len(row) > w || (len(row) < w && !trimmed) {
+			errs = append(errs, LineErrorf(y+1, 0, "%d cells; the first line has %d", len(row), w))
+		}
+	}
+	return w, errs
+}
+
+// LoadIntFile reads a puzzle file with LoadFile and LinesToNumGrid. Rows
+// of a different width are reported as LineErrors, joined with
+// errors.Join, except for the short rows Width accepts, which are padded
+// with UNKNOWN.
+func LoadIntFile(fn string) ([][]int, error) {
+	lines, err := LoadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := LinesToNumGrid(lines)
+	if err != nil {
+		return nil, err
+	}
+	w, errs := Width(lines, grid)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for y, row := range grid {
+		if len(row) < w {
+			grid[y] = append(row, make([]int, w-len(row))...)
+		}
+	}
+	return grid, nil
+}
@@ -0,0 +1,165 @@
+package grid
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplitTokens(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		want []string
+	}{
+		{"12  .  .  3", []string{"12", ".", ".", "3"}},
+		{"12,,,3", []string{"12", "", "", "3"}},
+		{" 1 , 2 ,_", []string{"1", "2", "_"}},
+		{"", nil},
+	} {
+		if got := SplitTokens(tt.line); !slices.Equal(got, tt.want) {
+			t.Errorf("SplitTokens(%q) = %q; want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestIsTokenGrid(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		want  bool
+	}{
+		{"spaces and dots", []string{"12 . . 3", " . . 40 ."}, true},
+		{"commas", []string{"12,,,3", ",,40,"}, true},
+		{"one comma", []string{"1,2"}, true},
+		{"wide numbers", []string{"10 11", "12 13"}, true},
+		{"digits", []string{"3121", "1334"}, false},
+		{"single digits", []string{"1 2", "3 4"}, false},
+		{"ragged wide numbers", []string{"10 11", "12"}, false},
+		{"one wide column", []string{"10", "12"}, false},
+		{"characters", []string{"_3_", "a._"}, false},
+		{"blank line", []string{"1 . 2", ""}, false},
+	} {
+		if got := IsTokenGrid(tt.lines); got != tt.want {
+			t.Errorf("%s: IsTokenGrid(%q) = %v; want %v", tt.name, tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestTokensToIntGrid(t *testing.T) {
+	got, err := TokensToIntGrid([]string{"12  .  .  3", " .  . 40  _", "1,,,0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int{{12, 0, 0, 3}, {0, 0, 40, 0}, {1, 0, 0, 0}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	for _, tt := range []struct {
+		lines     []string
+		line, col int
+	}{
+		{[]string{"1 2", "3 x"}, 2, 2},
+		{[]string{"1,-2"}, 1, 2},
+		{[]string{"+1 2"}, 1, 1},
+	} {
+		_, err := TokensToIntGrid(tt.lines)
+		var le *LineError
+		if !errors.As(err, &le) || le.Line != tt.line || le.Col != tt.col {
+			t.Errorf("TokensToIntGrid(%q) = %v; want an error at line %d, column %d", tt.lines, err, tt.line, tt.col)
+		}
+	}
+}
+
+func TestLinesToNumGrid(t *testing.T) {
+	for _, tt := range []struct {
+		lines []string
+		want  [][]int
+	}{
+		{[]string{"3_", "_z"}, [][]int{{3, 0}, {0, 35}}},
+		{[]string{"36 .", ". 1"}, [][]int{{36, 0}, {0, 1}}},
+	} {
+		got, err := LinesToNumGrid(tt.lines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(got, tt.want, slices.Equal) {
+			t.Errorf("LinesToNumGrid(%q) = %v; want %v", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestNumGridToLines(t *testing.T) {
+	for _, tt := range []struct {
+		nums  [][]int
+		blank int
+		want  []string
+	}{
+		{[][]int{{3, 0}, {0, 35}}, 0, []string{"3.", ".z"}},
+		{[][]int{{0, -1}, {-1, 2}}, -1, []string{"0.", ".2"}},
+		{[][]int{{36, 0}, {0, 1}}, 0, []string{" 36  .", "  .  1"}},
+	} {
+		got := NumGridToLines(tt.nums, tt.blank)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("NumGridToLines(%v, %d) = %q; want %q", tt.nums, tt.blank, got, tt.want)
+		}
+		if tt.blank != UNKNOWN {
+			continue
+		}
+		if back, err := LinesToNumGrid(got); err != nil || !slices.EqualFunc(back, tt.nums, slices.Equal) {
+			t.Errorf("LinesToNumGrid(%q) = %v, %v; want %v", got, back, err, tt.nums)
+		}
+	}
+}
+
+func TestLintLines(t *testing.T) {
+	lint := func(lines []string) []error {
+		return []error{LineErrorf(2, 3, "bad clue"), errors.New("bad map")}
+	}
+	err := LintLines(lint, nil)
+	var le *LineError
+	if errors.As(err, &le) {
+		t.Errorf("LintLines kept the LineError %v", le)
+	}
+	if want := "puzzle as text, line 2, column 3: bad clue\nbad map"; err == nil || err.Error() != want {
+		t.Errorf("LintLines = %v; want %q", err, want)
+	}
+	if err := LintLines(func([]string) []error { return nil }, nil); err != nil {
+		t.Errorf("LintLines with no problems = %v", err)
+	}
+}
+
+func TestWidth(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		want  int
+		bad   []int
+	}{
+		{"even", []string{"2__", "___", "___"}, 3, nil},
+		{"short rows", []string{"2__", "__", "___", "_"}, 3, []int{2, 4}},
+		{"long row", []string{"2__", "____", "___"}, 3, []int{2}},
+		{"trimmed blanks", []string{" 2 ", "", "3", "   "}, 3, nil},
+		{"trimmed first row", []string{" 2", "   ", "3  "}, 3, nil},
+		{"tokens", []string{"1 . 2", "3 ."}, 3, []int{2}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			nums, err := LinesToNumGrid(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, errs := Width(tt.lines, nums)
+			bad := make([]int, 0)
+			for _, err := range errs {
+				var le *LineError
+				if !errors.As(err, &le) {
+					t.Fatalf("%v is not a LineError", err)
+				}
+				bad = append(bad, le.Line)
+			}
+			if w != tt.want || !slices.Equal(bad, tt.bad) {
+				t.Errorf("width %d with errors on lines %v; want %d and %v", w, bad, tt.want, tt.bad)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package heyawake
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable painted(x,y) is true
+// when that cell is painted, and cells already known are unit clauses.
+//
+// Painted cells do not touch, every span holds a painted cell, and each
+// numbered room has exactly its count of painted cells, with a sequential
+// counter. Clear cells must connect, which is encoded as bounded
+// reachability by board's EncodeClearConnected. The formula therefore
+// grows with the square of the number of cells.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, painted := b.encode()
+	return b.SetFromModel(m, painted)
+}
+
+// encode builds the formula CNF returns, along with the painted variable
+// of every cell.
+func (b *Board) encode() (*cnf.Formula, [][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d", TypeName, b.W, b.H)}
+	painted := b.CellVars(f, "painted")
+	p := func(c grid.Coord) int {
+		return painted[c.Y][c.X]
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for _, n := range []grid.Coord{c.Plus(grid.RIGHT), c.Plus(grid.DOWN)} {
+			if b.IsValid(n) {
+				f.Add(-p(c), -p(n))
+			}
+		}
+	}
+	for _, span := range b.Spans {
+		lits := make([]int, 0, len(span))
+		for _, c := range span {
+			lits = append(lits, p(c))
+		}
+		f.Add(lits...)
+	}
+	for r, room := range b.Rooms {
+		if b.Counts[r] == NoCount {
+			continue
+		}
+		lits := make([]int, 0, len(room))
+		for _, c := range room {
+			lits = append(lits, p(c))
+		}
+		f.Exactly(lits, b.Counts[r])
+	}
+
+	b.EncodeClearConnected(f, painted)
+	return f, painted
+}
@@ -0,0 +1,549 @@
+// Package heyawake solves heyawake. The grid is divided into rooms, some of
+// which say how many of their cells are painted. Painted cells may not
+// touch, all clear cells must be connected, and no straight run of clear
+// cells may cross two room borders, reaching into three rooms.
+package heyawake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// NoCount is the count of a room without a number.
+const NoCount = -1
+
+// Board is a heyawake puzzle. Rooms holds the cells of each room in
+// reading order, RoomOf the room of each cell, and Counts the number of
+// painted cells in each room, or NoCount. Spans are the shortest straight
+// runs of cells that cross two room borders; each must hold a painted
+// cell. None of them change as the puzzle is solved, so clones share them.
+type Board struct {
+	board.RectBinBoard
+	Rooms  [][]grid.Coord
+	RoomOf [][]int
+	Counts []int
+	Spans  [][]grid.Coord
+}
+
+// RoomAt returns the index in Rooms of the room c is in.
+func (b *Board) RoomAt(c grid.Coord) int {
+	return b.RoomOf[c.Y][c.X]
+}
+
+// CountAt returns the count written in c: the count of c's room if c is
+// the room's first cell, and NoCount otherwise.
+func (b *Board) CountAt(c grid.Coord) int {
+	r := b.RoomAt(c)
+	if b.Rooms[r][0] != c {
+		return NoCount
+	}
+	return b.Counts[r]
+}
+
+// IsSolved reports whether every cell is known and every rule holds. The
+// error says what is still wrong.
+func (b *Board) IsSolved() (bool, error) {
+	res, coord := b.IsComplete()
+	if !res {
+		return false, fmt.Errorf("cell %s is unknown", coord)
+	}
+	for r, room := range b.Rooms {
+		if b.Counts[r] == NoCount {
+			continue
+		}
+		if painted := b.countPainted(room); painted != b.Counts[r] {
+			return false, fmt.Errorf("room at %s has %d painted cells, not %d", room[0], painted, b.Counts[r])
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// countPainted returns the number of painted cells in room.
+func (b *Board) countPainted(room []grid.Coord) int {
+	n := 0
+	for _, c := range room {
+		if b.IsPainted(c) {
+			n++
+		}
+	}
+	return n
+}
+
+// Validate returns an error if the board's current contents already break a
+// rule, even though some cells may still be unknown: two adjacent painted
+// cells, a room with more painted cells than its count or too few cells
+// left to reach it, a span that is all clear, or clear cells that can no
+// longer be joined through unpainted cells. For a complete board, Validate
+// returns nil exactly when IsSolved returns true.
+func (b *Board) Validate() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsPainted(c) {
+			continue
+		}
+		for _, n := range []grid.Coord{c.Plus(grid.RIGHT), c.Plus(grid.DOWN)} {
+			if b.IsPainted(n) {
+				return board.Contradiction(c, "Validate", "painted cell is next to painted cell %s", n)
+			}
+		}
+	}
+	for r, room := range b.Rooms {
+		if b.Counts[r] == NoCount {
+			continue
+		}
+		painted, unknown := 0, 0
+		for _, c := range room {
+			if b.IsPainted(c) {
+				painted++
+			} else if b.IsUnknown(c) {
+				unknown++
+			}
+		}
+		if painted > b.Counts[r] {
+			return board.Contradiction(room[0], "Validate", "room has %d painted cells; its count is %d", painted, b.Counts[r])
+		}
+		if painted+unknown < b.Counts[r] {
+			return board.Contradiction(room[0], "Validate", "room has room for at most %d painted cells; its count is %d", painted+unknown, b.Counts[r])
+		}
+	}
+	for _, span := range b.Spans {
+		if b.allClear(span) {
+			return board.Contradiction(span[0], "Validate", "clear cells from %s to %s cross two room borders", span[0], span[len(span)-1])
+		}
+	}
+
+	return b.ClearConnected()
+}
+
+// allClear reports whether every cell of span is clear.
+func (b *Board) allClear(span []grid.Coord) bool {
+	for _, c := range span {
+		if !b.IsClear(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the board whose cells can be changed without
+// affecting the original.
+func (b *Board) Clone() *Board {
+	return &Board{
+		RectBinBoard: *b.RectBinBoard.Clone(),
+		Rooms:        b.Rooms,
+		RoomOf:       b.RoomOf,
+		Counts:       b.Counts,
+		Spans:        b.Spans,
+	}
+}
+
+// Mark writes v to c and runs the rules that follow from it.
+func (b *Board) Mark(c grid.Coord, v grid.Cell) (bool, error) {
+	res, err := b.Set(c, v)
+	if !res {
+		return res, err
+	}
+	b.SetDirty()
+	return res, b.PostMark(c, v)
+}
+
+func (b *Board) MarkPainted(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.PAINTED)
+}
+
+func (b *Board) MarkClear(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.CLEAR)
+}
+
+// PostMark runs the rules that follow from v having just been written to c:
+// the neighbors of a painted cell are clear.
+func (b *Board) PostMark(c grid.Coord, v grid.Cell) error {
+	if v != grid.PAINTED {
+		return nil
+	}
+	b.BeginStep("PostMark", c)
+	defer b.EndStep()
+	var err error
+	b.EachNeighbor(c, func(n grid.Coord, nv grid.Cell) bool {
+		if err == nil {
+			_, err = b.MarkClear(n)
+		}
+		return false
+	})
+	return err
+}
+
+// String draws the board in a frame, with X for painted cells, · for clear
+// ones and each room's count in its first cell unless that cell is
+// painted. Counts above 35 switch every cell to the wide grid.CellFormat.
+func (b *Board) String() string {
+	largest := 0
+	for _, n := range b.Counts {
+		largest = max(largest, n)
+	}
+	f := grid.FormatFor(largest)
+	out := "+" + strings.Repeat("-", b.W*f.Len()) + "+\n"
+	for y := 0; y < b.H; y++ {
+		out += "|"
+		for x := 0; x < b.W; x++ {
+			c := grid.Coord{X: x, Y: y}
+			if n := b.CountAt(c); n != NoCount && !b.IsPainted(c) {
+				out += f.Num(n)
+			} else {
+				out += f.Mark(b.Get(c).Ch())
+			}
+		}
+		out += "|"
+		if y != b.H-1 {
+			out += "\n"
+		}
+	}
+	out += "\n+" + strings.Repeat("-", b.W*f.Len()) + "+"
+	return out
+}
+
+// ClearPaintedNeighbors is board.RectBinBoard.ClearPaintedNeighbors,
+// marking through Mark. PostMark already clears the neighbors of every cell
+// it paints.
+func (b *Board) ClearPaintedNeighbors() error {
+	return b.RectBinBoard.ClearPaintedNeighbors(b.Mark)
+}
+
+// BreakSpans looks for spans with every cell clear but one and paints that
+// one, so that the clear run does not cross two room borders.
+func (b *Board) BreakSpans() error {
+	for _, span := range b.Spans {
+		var last grid.Coord
+		unknown := 0
+		for _, c := range span {
+			if b.IsPainted(c) {
+				unknown = -1
+				break
+			}
+			if b.IsUnknown(c) {
+				unknown++
+				last = c
+			}
+		}
+		if unknown != 1 {
+			continue
+		}
+		b.BeginStep("BreakSpans", span[0], span[len(span)-1])
+		_, err := b.MarkPainted(last)
+		b.EndStep()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillBudget caps the number of partial paintings FillRooms tries for one
+// room before giving up on it.
+const fillBudget = 1 << 16
+
+// FillRooms tries every way to paint the rest of each numbered room and
+// marks the unknown cells that are painted in all of them, or in none.
+// Painted cells of the room may not touch each other or a painted cell
+// outside it. Rooms with too many ways to try are left alone.
+func (b *Board) FillRooms() error {
+	for r, room := range b.Rooms {
+		if b.Counts[r] == NoCount {
+			continue
+		}
+		open := make([]grid.Coord, 0, len(room))
+		for _, c := range room {
+			if b.IsUnknown(c) && !b.touchesPainted(c) {
+				open = append(open, c)
+			}
+		}
+		need := b.Counts[r] - b.countPainted(room)
+		if len(open) == 0 {
+			continue
+		}
+		ways, painted, ok := paintings(open, need)
+		if !ok {
+			continue
+		}
+		if ways == 0 {
+			return board.Contradiction(room[0], "FillRooms", "room cannot hold %d more painted cells", need)
+		}
+		for i, c := range open {
+			if !b.IsUnknown(c) || (painted[i] != 0 && painted[i] != ways) {
+				continue
+			}
+			b.BeginStep("FillRooms", room[0])
+			var err error
+			if painted[i] == ways {
+				_, err = b.MarkPainted(c)
+			} else {
+				_, err = b.MarkClear(c)
+			}
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// touchesPainted reports whether a neighbor of c is painted.
+func (b *Board) touchesPainted(c grid.Coord) bool {
+	found := false
+	b.EachNeighbor(c, func(n grid.Coord, nv grid.Cell) bool {
+		found = nv == grid.PAINTED
+		return found
+	})
+	return found
+}
+
+// paintings counts the ways to paint need of the cells in open, no two of
+// them neighbors, and for each cell the number of ways that paint it. ok
+// is false if there are too many partial paintings to try.
+func paintings(open []grid.Coord, need int) (ways int, painted []int, ok bool) {
+	painted = make([]int, len(open))
+	chosen := make([]bool, len(open))
+	tries := 0
+	var walk func(i, left int) bool
+	walk = func(i, left int) bool {
+		tries++
+		if tries > fillBudget {
+			return false
+		}
+		if left == 0 {
+			ways++
+			for j, p := range chosen {
+				if p {
+					painted[j]++
+				}
+			}
+			return true
+		}
+		if len(open)-i < left {
+			return true
+		}
+		free := true
+		for j := 0; j < i; j++ {
+			if chosen[j] && open[j].MHDist(open[i]) == 1 {
+				free = false
+				break
+			}
+		}
+		if free {
+			chosen[i] = true
+			if !walk(i+1, left-1) {
+				return false
+			}
+			chosen[i] = false
+		}
+		return walk(i+1, left)
+	}
+	if need < 0 {
+		return 0, painted, true
+	}
+	ok = walk(0, need)
+	return ways, painted, ok
+}
+
+// ClearMiniDominators is board.RectBinBoard.ClearMiniDominators, marking
+// through Mark.
+func (b *Board) ClearMiniDominators() error {
+	return b.RectBinBoard.ClearMiniDominators(b.Mark)
+}
+
+// ClearDominators is board.RectBinBoard.ClearDominators, marking through Mark.
+func (b *Board) ClearDominators() error {
+	return b.RectBinBoard.ClearDominators(b.Mark)
+}
+
+// Solve applies the rules until none of them makes progress. Returns a
+// ContradictionError if the board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	b.SetDirty()
+	for b.IsDirty() {
+		b.ClearDirty()
+		for _, rule := range []func() error{
+			b.BreakSpans,
+			b.FillRooms,
+			b.ClearMiniDominators,
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			if err := rule(); err != nil {
+				return err
+			}
+		}
+		if b.IsDirty() {
+			continue
+		}
+		if err := board.Stopped(ctx); err != nil {
+			return err
+		}
+		if err := b.ClearDominators(); err != nil {
+			return err
+		}
+	}
+	return b.Validate()
+}
+
+// BoardFromLines reads a puzzle in two sections, split by a line of dashes
+// or an empty line, as in ripple. First comes the room map, in which every
+// character names the room its cell belongs to; then the counts, one
+// character or one token (see grid.IsTokenGrid) per cell, written in any
+// one cell of their room, with anything that is not a number an empty
+// cell:
+//
+//	aabbb
+//	ccbbb
+//	ccdde
+//	-----
+//	1 2
+//	0
+//	  1
+//
+// Counts lines may stop short, but the sections must have the same number
+// of rows. A single line holding a puzz.link URL is read with
+// BoardFromURL. Every problem Lint finds is returned, joined with
+// errors.Join.
+func BoardFromLines(input []string) (*Board, error) {
+	if len(input) == 1 && pzpr.IsURL(input[0]) {
+		return BoardFromURL(input[0])
+	}
+	if errs := Lint(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	rooms, givens, _, _ := board.SplitSections(input)
+	allRegions, regionGrid := board.LinesToRegionGrid(rooms)
+	counts, err := countGrid(givens)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[*[]grid.Coord]int)
+	for i, r := range allRegions {
+		index[r] = i
+	}
+	roomCounts := make([]int, len(allRegions))
+	for i := range roomCounts {
+		roomCounts[i] = NoCount
+	}
+	for y, row := range counts {
+		for x, n := range row {
+			if n != NoCount {
+				roomCounts[index[regionGrid[y][x][0]]] = n
+			}
+		}
+	}
+	return newBoard(allRegions, regionGrid, roomCounts), nil
+}
+
+// Lines returns the puzzle's rooms and counts in the text format
+// BoardFromLines reads, with each count in the first cell of its room.
+func (b *Board) Lines() []string {
+	counts := grid.MakeNumGrid(b.W, b.H)
+	seen := make(map[int]bool)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		counts[c.Y][c.X] = NoCount
+		if r := b.RoomOf[c.Y][c.X]; !seen[r] {
+			seen[r] = true
+			counts[c.Y][c.X] = b.Counts[r]
+		}
+	}
+	lines := board.IDsToLines(b.RoomOf)
+	lines = append(lines, strings.Repeat("-", b.W))
+	return append(lines, grid.NumGridToLines(counts, NoCount)...)
+}
+
+// countGrid reads the counts section with grid.LinesToNumGrid, but with
+// NoCount for an empty cell, so that a count of 0 can be told from no
+// count at all.
+func countGrid(lines []string) ([][]int, error) {
+	nums, err := grid.LinesToNumGrid(lines)
+	if err != nil {
+		return nil, err
+	}
+	tokens := grid.IsTokenGrid(lines)
+	for y, row := range nums {
+		var toks []string
+		if tokens {
+			toks = grid.SplitTokens(lines[y])
+		}
+		for x, n := range row {
+			if n != 0 {
+				continue
+			}
+			if (tokens && toks[x] != "0") || (!tokens && lines[y][x] != '0') {
+				row[x] = NoCount
+			}
+		}
+	}
+	return nums, nil
+}
+
+// newBoard returns an unsolved board with the given rooms, as from
+// board.LinesToRegionGrid, and the count of each room.
+func newBoard(allRegions []*[]grid.Coord, regionGrid [][][]*[]grid.Coord, counts []int) *Board {
+	b := &Board{
+		RectBinBoard: *board.NewRectBinBoard(len(regionGrid[0]), len(regionGrid)),
+		Rooms:        make([][]grid.Coord, len(allRegions)),
+		RoomOf:       grid.MakeNumGrid(len(regionGrid[0]), len(regionGrid)),
+		Counts:       counts,
+	}
+	for i, r := range allRegions {
+		b.Rooms[i] = *r
+		for _, c := range *r {
+			b.RoomOf[c.Y][c.X] = i
+		}
+	}
+	for y := 0; y < b.H; y++ {
+		line := make([]grid.Coord, b.W)
+		for x := range line {
+			line[x] = grid.Coord{X: x, Y: y}
+		}
+		b.Spans = append(b.Spans, b.spansOf(line)...)
+	}
+	for x := 0; x < b.W; x++ {
+		line := make([]grid.Coord, b.H)
+		for y := range line {
+			line[y] = grid.Coord{X: x, Y: y}
+		}
+		b.Spans = append(b.Spans, b.spansOf(line)...)
+	}
+	b.Inited = true
+	return b
+}
+
+// spansOf returns the spans of a row or column: for every stretch of
+// cells in one room with other rooms on both sides, the stretch and the
+// cell on each side of it.
+func (b *Board) spansOf(line []grid.Coord) [][]grid.Coord {
+	spans := make([][]grid.Coord, 0)
+	start := 0
+	for start < len(line) {
+		end := start + 1
+		for end < len(line) && b.RoomAt(line[end]) == b.RoomAt(line[start]) {
+			end++
+		}
+		if start > 0 && end < len(line) {
+			spans = append(spans, append([]grid.Coord{}, line[start-1:end+1]...))
+		}
+		start = end
+	}
+	return spans
+}
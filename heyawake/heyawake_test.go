@@ -0,0 +1,104 @@
+package heyawake
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+func TestSpans(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		want  [][]grid.Coord
+	}{
+		{"three rooms", []string{"abc", "---", "..."}, [][]grid.Coord{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}}},
+		{"wide middle room", []string{"abbc", "----", "...."}, [][]grid.Coord{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}}},
+		{"two rooms", []string{"aabb", "----", "...."}, nil},
+		{"column", []string{"a", "b", "c", "-", ".", ".", "."}, [][]grid.Coord{{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(b.Spans, tt.want, slices.Equal) {
+				t.Errorf("spans are %v; want %v", b.Spans, tt.want)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		clear []grid.Coord
+		rule  func(*Board) error
+		want  string
+	}{
+		{"break a span", []string{"abc", "---", "..."}, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}}, (*Board).BreakSpans, "··X"},
+		{"fill a room", []string{"aaa", "---", "2.."}, nil, (*Board).FillRooms, "X·X"},
+		{"empty room", []string{"aab", "---", "0.."}, nil, (*Board).FillRooms, "·· "},
+		{"room with two ways", []string{"aaaa", "----", "1..."}, nil, (*Board).FillRooms, "    "},
+		{"clear cell in the room", []string{"aaab", "----", "1..."}, []grid.Coord{{X: 0, Y: 0}, {X: 2, Y: 0}}, (*Board).FillRooms, "·X· "},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.clear {
+				b.Set(c, grid.CLEAR)
+			}
+			if err := tt.rule(b); err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			for x := 0; x < b.W; x++ {
+				switch b.Get(grid.Coord{X: x, Y: 0}) {
+				case grid.PAINTED:
+					got += "X"
+				case grid.CLEAR:
+					got += "·"
+				default:
+					got += " "
+				}
+			}
+			if got != tt.want {
+				t.Errorf("row is %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		paint []grid.Coord
+		clear []grid.Coord
+	}{
+		{"over the count", []string{"aaa", "---", "1.."}, []grid.Coord{{X: 0, Y: 0}, {X: 2, Y: 0}}, nil},
+		{"short of the count", []string{"aaa", "---", "2.."}, nil, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}}},
+		{"clear across three rooms", []string{"abc", "---", "..."}, nil, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}},
+		{"painted neighbors", []string{"ab", "--", ".."}, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}}, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.paint {
+				b.Set(c, grid.PAINTED)
+			}
+			for _, c := range tt.clear {
+				b.Set(c, grid.CLEAR)
+			}
+			if err := b.Validate(); err == nil {
+				t.Errorf("Validate found nothing wrong with\n%s", b)
+			}
+		})
+	}
+}
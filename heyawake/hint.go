@@ -0,0 +1,34 @@
+package heyawake
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Explain describes why step s made mark m, for board.HintBin.
+func (b *Board) Explain(s board.Step, m board.CellValue) string {
+	switch s.Rule {
+	case "ClearPaintedNeighbors", "PostMark":
+		return fmt.Sprintf("it is next to painted cell %s", s.Cells[0])
+	case "BreakSpans":
+		return fmt.Sprintf("otherwise the clear cells from %s to %s would cross two room borders", s.Cells[0], s.Cells[1])
+	case "FillRooms":
+		r := b.RoomAt(s.Cells[0])
+		if grid.Cell(m.Value) == grid.PAINTED {
+			return fmt.Sprintf("every way to paint %d cells of the room at %s paints it", b.Counts[r], s.Cells[0])
+		}
+		return fmt.Sprintf("painting it leaves no way to paint exactly %d cells of the room at %s", b.Counts[r], s.Cells[0])
+	case "ClearMiniDominators":
+		return fmt.Sprintf("it is the only liberty of clear cell %s", s.Cells[0])
+	case "ClearAllDominators":
+		return fmt.Sprintf("painting it would cut %s off from clear cell %s", s.Cells[1], s.Cells[0])
+	}
+	cells := make([]string, 0, len(s.Cells))
+	for _, c := range s.Cells {
+		cells = append(cells, c.String())
+	}
+	return fmt.Sprintf("follows from %s at %s", s.Rule, strings.Join(cells, " "))
+}
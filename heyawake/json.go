@@ -0,0 +1,59 @@
+package heyawake
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// BoardFromDocument reads a puzzle from its regions and their counts, then
+// fills in any given cells. Cells are taken as they are, without running
+// the rules.
+func BoardFromDocument(d *puzzle.Document) (*Board, error) {
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	if len(d.Regions) == 0 {
+		return nil, fmt.Errorf("document has no regions")
+	}
+	allRegions, regionGrid := board.IDsToRegionGrid(d.Regions)
+	counts := make([]int, len(allRegions))
+	for i, r := range allRegions {
+		first := (*r)[0]
+		id := d.Regions[first.Y][first.X]
+		counts[i] = NoCount
+		if id >= 0 && id < len(d.Counts) {
+			counts[i] = d.Counts[id]
+		}
+		if counts[i] < NoCount {
+			return nil, fmt.Errorf("region %d has count %d", id, counts[i])
+		}
+	}
+	b := newBoard(allRegions, regionGrid, counts)
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	if err := d.SetBinCells(&b.RectBinBoard); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Document returns the puzzle with its current cells. Regions are
+// numbered from 0 in reading order of their first cell, as in Rooms.
+func (b *Board) Document() *puzzle.Document {
+	d := &puzzle.Document{
+		Type:    TypeName,
+		Width:   b.W,
+		Height:  b.H,
+		Author:  b.Meta.Author,
+		Source:  b.Meta.Source,
+		Regions: grid.CopyNumGrid(b.RoomOf),
+		Counts:  append([]int{}, b.Counts...),
+		Cells:   b.Cells(),
+	}
+	d.Solved, _ = b.IsSolved()
+	return d
+}
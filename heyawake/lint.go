@@ -0,0 +1,96 @@
+package heyawake
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place:
+// a missing separator, room map lines of different widths or with blank
+// cells, rooms in more than one piece, a counts section of the wrong
+// shape, two counts in one room, and counts too large for their rooms. A
+// puzz.link URL is left to BoardFromURL.
+func Lint(lines []string) []error {
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return nil
+	}
+	rooms, givens, givensAt, ok := board.SplitSections(lines)
+	if !ok {
+		return []error{fmt.Errorf("must have a room map and a grid of counts, split by a line of dashes; have %d lines and no separator", len(lines))}
+	}
+	if len(rooms) == 0 {
+		return []error{grid.LineErrorf(1, 0, "room map is empty")}
+	}
+	errs := make([]error, 0)
+	w := len([]rune(rooms[0]))
+	for y, l := range rooms {
+		row := []rune(l)
+		if len(row) != w {
+			errs = append(errs, grid.LineErrorf(y+1, 0, "room map line has %d cells; the first has %d", len(row), w))
+		}
+		for x, ch := range row {
+			if board.IsBlank(ch) {
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "room map cell %q names no room", ch))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		// Rooms cannot be traced through a ragged or holed map.
+		return errs
+	}
+	allRegions, regionGrid := board.LinesToRegionGrid(rooms)
+	for _, r := range allRegions {
+		if cut, from, ok := board.SplitRegion(*r, regionGrid); ok {
+			errs = append(errs, grid.LineErrorf(cut.Y+1, cut.X+1, "room %q is in more than one piece; this cell is cut off from line %d, column %d", []rune(rooms[cut.Y])[cut.X], from.Y+1, from.X+1))
+		}
+	}
+
+	if len(givens) != len(rooms) {
+		return append(errs, grid.LineErrorf(givensAt+1, 0, "counts have %d rows; the room map has %d (write an empty row as a line of dots)", len(givens), len(rooms)))
+	}
+	counts, err := countGrid(givens)
+	if err != nil {
+		return append(errs, grid.ShiftLines(err, givensAt))
+	}
+	seen := make(map[*[]grid.Coord]grid.Coord)
+	for y, row := range counts {
+		if len(row) > w {
+			errs = append(errs, grid.LineErrorf(givensAt+y+1, 0, "counts line has %d cells; the room map has %d", len(row), w))
+		}
+		for x := 0; x < min(w, len(row)); x++ {
+			n := row[x]
+			if n == NoCount {
+				continue
+			}
+			r := regionGrid[y][x][0]
+			if c, ok := seen[r]; ok {
+				errs = append(errs, grid.LineErrorf(givensAt+y+1, x+1, "room already has a count at line %d, column %d", givensAt+c.Y+1, c.X+1))
+			} else {
+				seen[r] = grid.Coord{X: x, Y: y}
+			}
+			if most := mostPainted(*r); n > most {
+				errs = append(errs, grid.LineErrorf(givensAt+y+1, x+1, "count %d does not fit its room of %d cells; with no two painted cells touching, it holds at most %d", n, len(*r), most))
+			}
+		}
+	}
+	return errs
+}
+
+// mostPainted returns an upper bound on the painted cells room can hold.
+// A rectangle of n cells holds at most (n+1)/2; a room of any other shape
+// is only bounded by its size.
+func mostPainted(room []grid.Coord) int {
+	lo, hi := room[0], room[0]
+	for _, c := range room {
+		lo.X, lo.Y = min(lo.X, c.X), min(lo.Y, c.Y)
+		hi.X, hi.Y = max(hi.X, c.X), max(hi.Y, c.Y)
+	}
+	if (hi.X-lo.X+1)*(hi.Y-lo.Y+1) == len(room) {
+		return (len(room) + 1) / 2
+	}
+	return len(room)
+}
@@ -0,0 +1,14 @@
+package heyawake
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the heyawake rules in increasing order of difficulty.
+func (b *Board) Techniques() []board.Technique {
+	return []board.Technique{
+		board.DirtyTechnique(&b.RectBoard, "ClearPaintedNeighbors", 1, b.ClearPaintedNeighbors),
+		board.DirtyTechnique(&b.RectBoard, "BreakSpans", 1, b.BreakSpans),
+		board.DirtyTechnique(&b.RectBoard, "ClearMiniDominators", 2, b.ClearMiniDominators),
+		board.DirtyTechnique(&b.RectBoard, "FillRooms", 3, b.FillRooms),
+		board.DirtyTechnique(&b.RectBoard, "ClearAllDominators", 4, b.ClearDominators),
+	}
+}
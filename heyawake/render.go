@@ -0,0 +1,20 @@
+package heyawake
+
+import "github.com/bismuthsalamander/mutantcheckerboard/render"
+
+// Scene describes the board for the renderers: each cell's shade, the
+// rooms, and each room's count as a clue in its first cell.
+func (b *Board) Scene() *render.Scene {
+	s := render.NewScene(b.W, b.H)
+	s.Meta = b.Meta
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		cell := s.At(c)
+		cell.Shade = b.Get(c)
+		if n := b.CountAt(c); n != NoCount {
+			cell.Value = n
+			cell.Clue = true
+		}
+	}
+	s.Regions = b.RoomOf
+	return s
+}
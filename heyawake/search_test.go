@@ -0,0 +1,17 @@
+package heyawake
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckBin(t, BoardFromLines, []boardtest.Case{
+		{Name: "no counts", Lines: []string{"aabb", "aabb", "ccdd", "ccdd", "----", "....", "....", "....", "...."}},
+		{Name: "unique", Lines: []string{"aabb", "aabb", "ccdd", "ccdd", "----", "0.0.", "....", "0.2.", "...."}},
+		{Name: "two", Lines: []string{"aaab", "cddb", "cddb", "ceee", "----", "....", "10..", "....", ".1.."}},
+		{Name: "nine", Lines: []string{"abbb", "accd", "accd", "eeed", "----", ".1..", "...0", "....", "0..."}},
+		{Name: "no solution", Lines: []string{"aaab", "cddb", "cddb", "ceee", "----", "...0", "22..", "....", ".2.."}},
+	})
+}
@@ -0,0 +1,48 @@
+package heyawake
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// BoardFromURL reads a puzzle from a pzprjs "heyawake" URL: the room
+// borders followed by one number per room, in reading order of the rooms'
+// first cells.
+func BoardFromURL(s string) (*Board, error) {
+	u, err := pzpr.ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "heyawake" {
+		return nil, fmt.Errorf("puzzle URL is for %q, not heyawake", u.Type)
+	}
+	ids, rest, err := pzpr.DecodeBorder(u.Body, u.Cols, u.Rows)
+	if err != nil {
+		return nil, err
+	}
+	allRegions, regionGrid := board.IDsToRegionGrid(ids)
+	counts, _, err := pzpr.DecodeNumber16(rest, len(allRegions))
+	if err != nil {
+		return nil, err
+	}
+	for i, n := range counts {
+		if n == pzpr.Question {
+			return nil, fmt.Errorf("room at %s holds a question mark, which is not supported", (*allRegions[i])[0])
+		}
+		counts[i] = max(n, NoCount)
+	}
+	b := newBoard(allRegions, regionGrid, counts)
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// URL returns the puzzle's rooms and counts as a puzz.link URL.
+func (b *Board) URL() string {
+	u := pzpr.URL{Type: "heyawake", Cols: b.W, Rows: b.H, Body: pzpr.EncodeBorder(b.RoomOf) + pzpr.EncodeNumber16(b.Counts)}
+	return u.String()
+}
@@ -0,0 +1,57 @@
+package hitori
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable painted(x,y) is true
+// when that cell is painted, and cells already known are unit clauses.
+//
+// Of two cells with the same number in a row or column, one is painted,
+// and cells without a twin are clear. Clear cells must connect, which is
+// encoded as bounded reachability by board's EncodeClearConnected. The
+// formula therefore grows with the square of the number of cells.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, painted := b.encode()
+	return b.SetFromModel(m, painted)
+}
+
+// encode builds the formula CNF returns, along with the painted variable
+// of every cell.
+func (b *Board) encode() (*cnf.Formula, [][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d", TypeName, b.W, b.H)}
+	painted := b.CellVars(f, "painted")
+	p := func(c grid.Coord) int {
+		return painted[c.Y][c.X]
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for _, n := range []grid.Coord{c.Plus(grid.RIGHT), c.Plus(grid.DOWN)} {
+			if b.IsValid(n) {
+				f.Add(-p(c), -p(n))
+			}
+		}
+		if len(b.TwinsOf(c)) == 0 {
+			f.Add(-p(c))
+		}
+		for _, t := range b.TwinsOf(c) {
+			// Each pair once: twins later in reading order.
+			if t.Y > c.Y || (t.Y == c.Y && t.X > c.X) {
+				f.Add(p(c), p(t))
+			}
+		}
+	}
+
+	b.EncodeClearConnected(f, painted)
+	return f, painted
+}
@@ -0,0 +1,39 @@
+package hitori
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Explain describes why step s made mark m, for board.HintBin.
+func (b *Board) Explain(s board.Step, m board.CellValue) string {
+	switch s.Rule {
+	case "ClearPaintedNeighbors":
+		return fmt.Sprintf("it is next to painted cell %s", s.Cells[0])
+	case "PaintClearTwins":
+		return fmt.Sprintf("it repeats the %d of clear cell %s", b.NumAt(m.At), s.Cells[0])
+	case "PostMark":
+		if grid.Cell(m.Value) == grid.PAINTED {
+			return fmt.Sprintf("it repeats the %d of clear cell %s", b.NumAt(m.At), s.Cells[0])
+		}
+		return fmt.Sprintf("it is next to painted cell %s", s.Cells[0])
+	case "ClearSingles":
+		return fmt.Sprintf("no other cell of its row or column holds %d", b.NumAt(m.At))
+	case "ClearSandwiches":
+		return fmt.Sprintf("one of the %ds at %s and %s must be painted, and it touches both", b.NumAt(s.Cells[0]), s.Cells[0], s.Cells[1])
+	case "IsolatePairs":
+		return fmt.Sprintf("one of the %ds at %s and %s stays clear", b.NumAt(s.Cells[0]), s.Cells[0], s.Cells[1])
+	case "ClearMiniDominators":
+		return fmt.Sprintf("it is the only liberty of clear cell %s", s.Cells[0])
+	case "ClearAllDominators":
+		return fmt.Sprintf("painting it would cut %s off from clear cell %s", s.Cells[1], s.Cells[0])
+	}
+	cells := make([]string, 0, len(s.Cells))
+	for _, c := range s.Cells {
+		cells = append(cells, c.String())
+	}
+	return fmt.Sprintf("follows from %s at %s", s.Rule, strings.Join(cells, " "))
+}
@@ -0,0 +1,376 @@
+// Package hitori solves hitori. Every cell holds a number; paint cells so
+// that no number appears twice among the clear cells of a row or column.
+// Painted cells may not touch, and all clear cells must be connected. As in
+// every published hitori, a cell whose number is not repeated in its row or
+// column is never painted.
+package hitori
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Board is a hitori puzzle. Nums holds the number in every cell, and Twins
+// the other cells of each cell's row and column that hold the same number.
+// Neither changes as the puzzle is solved, so clones share them.
+type Board struct {
+	board.RectBinBoard
+	Nums  [][]int
+	Twins [][][]grid.Coord
+}
+
+// NumAt returns the number in c.
+func (b *Board) NumAt(c grid.Coord) int {
+	return b.Nums[c.Y][c.X]
+}
+
+// TwinsOf returns the other cells of c's row and column that hold c's
+// number.
+func (b *Board) TwinsOf(c grid.Coord) []grid.Coord {
+	return b.Twins[c.Y][c.X]
+}
+
+// IsSolved reports whether every cell is known and every rule holds. The
+// error says what is still wrong.
+func (b *Board) IsSolved() (bool, error) {
+	res, coord := b.IsComplete()
+	if !res {
+		return false, fmt.Errorf("cell %s is unknown", coord)
+	}
+
+	// Are all clear cells contiguous?
+	var start grid.Coord
+	b.EachCell(func(cd grid.Coord, v grid.Cell) bool {
+		if v == grid.CLEAR {
+			start = cd
+			return true
+		}
+		return false
+	})
+	reached := b.Flood(start, func(v grid.Cell) bool { return v == grid.CLEAR })
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !reached.Has(c) && b.Get(c) == grid.CLEAR {
+			return false, fmt.Errorf("cannot reach clear cell %s from %s", c, start)
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Validate returns an error if the board's current contents already break a
+// rule, even though some cells may still be unknown: two adjacent painted
+// cells, a painted cell with no twin, two clear cells with the same number
+// in one row or column, or clear cells that can no longer be joined through
+// unpainted cells. For a complete board, Validate returns nil exactly when
+// IsSolved returns true.
+func (b *Board) Validate() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		switch b.Get(c) {
+		case grid.PAINTED:
+			if len(b.TwinsOf(c)) == 0 {
+				return board.Contradiction(c, "Validate", "painted %d is not repeated in its row or column", b.NumAt(c))
+			}
+			for _, n := range []grid.Coord{c.Plus(grid.RIGHT), c.Plus(grid.DOWN)} {
+				if b.IsPainted(n) {
+					return board.Contradiction(c, "Validate", "painted cell is next to painted cell %s", n)
+				}
+			}
+		case grid.CLEAR:
+			for _, t := range b.TwinsOf(c) {
+				if b.IsClear(t) {
+					return board.Contradiction(c, "Validate", "clear %d is repeated by clear cell %s", b.NumAt(c), t)
+				}
+			}
+		}
+	}
+
+	return b.ClearConnected()
+}
+
+// Clone returns a copy of the board whose cells can be changed without
+// affecting the original.
+func (b *Board) Clone() *Board {
+	return &Board{
+		RectBinBoard: *b.RectBinBoard.Clone(),
+		Nums:         b.Nums,
+		Twins:        b.Twins,
+	}
+}
+
+// Mark writes v to c and runs the rules that follow from it.
+func (b *Board) Mark(c grid.Coord, v grid.Cell) (bool, error) {
+	res, err := b.Set(c, v)
+	if !res {
+		return res, err
+	}
+	b.SetDirty()
+	return res, b.PostMark(c, v)
+}
+
+func (b *Board) MarkPainted(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.PAINTED)
+}
+
+func (b *Board) MarkClear(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.CLEAR)
+}
+
+// PostMark runs the rules that follow from v having just been written to c:
+// the neighbors of a painted cell are clear, and the twins of a clear cell
+// are painted.
+func (b *Board) PostMark(c grid.Coord, v grid.Cell) error {
+	b.BeginStep("PostMark", c)
+	defer b.EndStep()
+	switch v {
+	case grid.PAINTED:
+		var err error
+		b.EachNeighbor(c, func(n grid.Coord, nv grid.Cell) bool {
+			if err == nil {
+				_, err = b.MarkClear(n)
+			}
+			return false
+		})
+		return err
+	case grid.CLEAR:
+		for _, t := range b.TwinsOf(c) {
+			if _, err := b.MarkPainted(t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// String draws the board in a frame, with X for painted cells and the
+// number for every other cell. Numbers above 35 switch every cell to the
+// wide grid.CellFormat.
+func (b *Board) String() string {
+	largest := 0
+	for _, row := range b.Nums {
+		for _, n := range row {
+			largest = max(largest, n)
+		}
+	}
+	f := grid.FormatFor(largest)
+	out := "+" + strings.Repeat("-", b.W*f.Len()) + "+\n"
+	for y, row := range b.Nums {
+		out += "|"
+		for x, n := range row {
+			if b.IsPainted(grid.Coord{X: x, Y: y}) {
+				out += f.Mark(grid.Cell(grid.PAINTED).Ch())
+			} else {
+				out += f.Num(n)
+			}
+		}
+		out += "|"
+		if y != b.H-1 {
+			out += "\n"
+		}
+	}
+	out += "\n+" + strings.Repeat("-", b.W*f.Len()) + "+"
+	return out
+}
+
+// ClearPaintedNeighbors is board.RectBinBoard.ClearPaintedNeighbors,
+// marking through Mark. PostMark already clears the neighbors of every cell
+// it paints.
+func (b *Board) ClearPaintedNeighbors() error {
+	return b.RectBinBoard.ClearPaintedNeighbors(b.Mark)
+}
+
+// PaintClearTwins marks every unknown twin of a clear cell as painted. Like
+// ClearPaintedNeighbors, it only finds work on boards filled in without
+// PostMark.
+func (b *Board) PaintClearTwins() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsClear(c) {
+			continue
+		}
+		for _, t := range b.TwinsOf(c) {
+			if !b.IsUnknown(t) {
+				continue
+			}
+			b.BeginStep("PaintClearTwins", c)
+			_, err := b.MarkPainted(t)
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClearSingles marks every cell without a twin as clear: nothing is gained
+// by painting it.
+func (b *Board) ClearSingles() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.IsUnknown(c) && len(b.TwinsOf(c)) == 0 {
+			b.BeginStep("ClearSingles", c)
+			_, err := b.MarkClear(c)
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClearSandwiches marks clear every cell between two equal numbers, as in
+// 3 5 3. One of the two must be painted, which the middle cell could not
+// touch.
+func (b *Board) ClearSandwiches() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsUnknown(c) {
+			continue
+		}
+		for _, dir := range []grid.Delta{grid.RIGHT, grid.DOWN} {
+			l, r := c.Plus(dir.Reverse()), c.Plus(dir)
+			if !b.IsValid(l) || !b.IsValid(r) || b.NumAt(l) != b.NumAt(r) {
+				continue
+			}
+			b.BeginStep("ClearSandwiches", l, r)
+			_, err := b.MarkClear(c)
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// IsolatePairs looks for two neighbors with the same number, as in 4 4. One
+// of them is painted and the other stays clear, so every other cell of
+// their row or column with that number must be painted.
+func (b *Board) IsolatePairs() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for _, dir := range []grid.Delta{grid.RIGHT, grid.DOWN} {
+			n := c.Plus(dir)
+			if !b.IsValid(n) || b.NumAt(n) != b.NumAt(c) {
+				continue
+			}
+			for _, t := range b.TwinsOf(c) {
+				if t == n || !b.IsUnknown(t) || (dir == grid.RIGHT) != (t.Y == c.Y) {
+					continue
+				}
+				b.BeginStep("IsolatePairs", c, n)
+				_, err := b.MarkPainted(t)
+				b.EndStep()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ClearMiniDominators is board.RectBinBoard.ClearMiniDominators, marking
+// through Mark.
+func (b *Board) ClearMiniDominators() error {
+	return b.RectBinBoard.ClearMiniDominators(b.Mark)
+}
+
+// ClearDominators is board.RectBinBoard.ClearDominators, marking through Mark.
+func (b *Board) ClearDominators() error {
+	return b.RectBinBoard.ClearDominators(b.Mark)
+}
+
+// Solve applies the rules until none of them makes progress. Returns a
+// ContradictionError if the board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	b.SetDirty()
+	for b.IsDirty() {
+		b.ClearDirty()
+		for _, rule := range []func() error{
+			b.ClearSingles,
+			b.ClearSandwiches,
+			b.IsolatePairs,
+			b.ClearMiniDominators,
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			if err := rule(); err != nil {
+				return err
+			}
+		}
+		if b.IsDirty() {
+			continue
+		}
+		if err := board.Stopped(ctx); err != nil {
+			return err
+		}
+		if err := b.ClearDominators(); err != nil {
+			return err
+		}
+	}
+	return b.Validate()
+}
+
+// BoardFromLines reads a puzzle with a number in every cell: a digit or
+// lowercase letter (see grid.CharToNum), or a tokenized grid (see
+// grid.IsTokenGrid) for numbers above 35. A single line holding a puzz.link
+// URL is read with BoardFromURL. Every problem Lint finds is returned,
+// joined with errors.Join.
+func BoardFromLines(input []string) (*Board, error) {
+	if len(input) == 1 && pzpr.IsURL(input[0]) {
+		return BoardFromURL(input[0])
+	}
+	if errs := Lint(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	nums, err := grid.LinesToNumGrid(input)
+	if err != nil {
+		return nil, err
+	}
+	return newBoard(nums), nil
+}
+
+// Lines returns the puzzle's numbers in the text format BoardFromLines
+// reads.
+func (b *Board) Lines() []string {
+	return grid.NumGridToLines(b.Nums, grid.UNKNOWN)
+}
+
+// newBoard returns an unsolved board with the given numbers, which must
+// fill a rectangle.
+func newBoard(nums [][]int) *Board {
+	b := &Board{
+		RectBinBoard: *board.NewRectBinBoard(len(nums[0]), len(nums)),
+		Nums:         nums,
+		Twins:        make([][][]grid.Coord, len(nums)),
+	}
+	for y := range nums {
+		b.Twins[y] = make([][]grid.Coord, b.W)
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for _, dir := range grid.DIRECTIONS {
+			for t := c.Plus(dir); b.IsValid(t); t = t.Plus(dir) {
+				if b.NumAt(t) == b.NumAt(c) {
+					b.Twins[c.Y][c.X] = append(b.Twins[c.Y][c.X], t)
+				}
+			}
+		}
+	}
+	b.Inited = true
+	return b
+}
@@ -0,0 +1,66 @@
+package hitori
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+func TestRules(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		rule  func(*Board) error
+		at    grid.Coord
+		want  grid.Cell
+	}{
+		{"single", []string{"123", "312", "231"}, (*Board).ClearSingles, grid.Coord{X: 1, Y: 1}, grid.CLEAR},
+		{"sandwich in a row", []string{"353", "124", "412"}, (*Board).ClearSandwiches, grid.Coord{X: 1, Y: 0}, grid.CLEAR},
+		{"sandwich in a column", []string{"21", "32", "21"}, (*Board).ClearSandwiches, grid.Coord{X: 0, Y: 1}, grid.CLEAR},
+		{"pair", []string{"4414", "1234", "2341", "3123"}, (*Board).IsolatePairs, grid.Coord{X: 3, Y: 0}, grid.PAINTED},
+		{"pair leaves the other line", []string{"4412", "1234", "4341", "3123"}, (*Board).IsolatePairs, grid.Coord{X: 0, Y: 2}, grid.UNKNOWN},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.rule(b); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.Get(tt.at); got != tt.want {
+				t.Errorf("%s is %s; want %s\n%s", tt.at, grid.CellName(got), grid.CellName(tt.want), b)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		paint []grid.Coord
+		clear []grid.Coord
+	}{
+		{"painted neighbors", []string{"112", "213", "321"}, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}}, nil},
+		{"painted single", []string{"123", "231", "312"}, []grid.Coord{{X: 1, Y: 1}}, nil},
+		{"clear repeat", []string{"121", "213", "312"}, nil, []grid.Coord{{X: 0, Y: 0}, {X: 2, Y: 0}}},
+		{"cut off", []string{"121", "212", "121"}, []grid.Coord{{X: 1, Y: 0}, {X: 0, Y: 1}}, []grid.Coord{{X: 0, Y: 0}, {X: 2, Y: 2}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.paint {
+				b.Set(c, grid.PAINTED)
+			}
+			for _, c := range tt.clear {
+				b.Set(c, grid.CLEAR)
+			}
+			if err := b.Validate(); err == nil {
+				t.Errorf("Validate found nothing wrong with\n%s", b)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package hitori
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place:
+// rows of different lengths, cells without a number, and a number that
+// forms two separate pairs of neighbors in one row or column, which would
+// leave two of it clear. A puzz.link URL is left to BoardFromURL.
+func Lint(lines []string) []error {
+	if len(lines) == 0 {
+		return []error{fmt.Errorf("puzzle is empty")}
+	}
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return nil
+	}
+	nums, err := grid.LinesToNumGrid(lines)
+	if err != nil {
+		return []error{err}
+	}
+	errs := make([]error, 0)
+	w := len(nums[0])
+	for y, row := range nums {
+		if len(row) != w {
+			errs = append(errs, grid.LineErrorf(y+1, 0, "%d cells; the first line has %d", len(row), w))
+		}
+		for x, v := range row {
+			if v == 0 {
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "cell holds no number"))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	b := newBoard(nums)
+	for _, dir := range []grid.Delta{grid.RIGHT, grid.DOWN} {
+		// Walk each row (or column) and remember, for every number, where
+		// its first pair of neighbors starts.
+		var start grid.Coord
+		next := grid.DOWN
+		if dir == grid.DOWN {
+			next = grid.RIGHT
+		}
+		for ; b.IsValid(start); start = start.Plus(next) {
+			pairs := make(map[int]grid.Coord)
+			for c := start; b.IsValid(c.Plus(dir)); c = c.Plus(dir) {
+				n := c.Plus(dir)
+				v := b.NumAt(c)
+				if b.NumAt(n) != v {
+					continue
+				}
+				p, ok := pairs[v]
+				if !ok {
+					pairs[v] = c
+				} else if c != p.Plus(dir) {
+					errs = append(errs, grid.LineErrorf(c.Y+1, c.X+1, "%ds here and at line %d, column %d make two pairs; one of each stays clear", v, p.Y+1, p.X+1))
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,94 @@
+package hitori
+
+import (
+	"context"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// TypeName is the name the puzzle type is registered under.
+const TypeName = "hitori"
+
+func init() {
+	puzzle.Register(puzzle.Type{
+		Name:        TypeName,
+		Description: "hitori: paint cells so that no number repeats among the clear cells of a row or column",
+		Pzpr:        "hitori",
+		New:         func() puzzle.Puzzle { return &puzzleAdapter{} },
+		Detect:      looksLike,
+	})
+}
+
+// puzzleAdapter adapts Board to the puzzle.Puzzle interface.
+type puzzleAdapter struct {
+	*Board
+}
+
+// Parse reads a puzzle in the text format of BoardFromLines, after an
+// optional puzzle.Header.
+func (p *puzzleAdapter) Parse(lines []string) (err error) {
+	p.Board, err = puzzle.ParseText(TypeName, lines, BoardFromLines)
+	return err
+}
+
+func (p *puzzleAdapter) ParseDocument(d *puzzle.Document) (err error) {
+	p.Board, err = BoardFromDocument(d)
+	return err
+}
+
+func (p *puzzleAdapter) Clone() puzzle.Puzzle {
+	return &puzzleAdapter{p.Board.Clone()}
+}
+
+func (p *puzzleAdapter) SearchContext(ctx context.Context) (bool, error) {
+	return board.SearchBin(ctx, p.Board)
+}
+
+func (p *puzzleAdapter) CountSolutionsContext(ctx context.Context, limit int) (int, []puzzle.Puzzle, error) {
+	n, witnesses, err := board.CountBin(ctx, p.Board, limit)
+	return n, puzzle.ToPuzzles(witnesses, func(b *Board) puzzle.Puzzle { return &puzzleAdapter{b} }), err
+}
+
+func (p *puzzleAdapter) Hint() (*board.Hint, error) {
+	return board.HintBin(p.Board)
+}
+
+func (p *puzzleAdapter) RateContext(ctx context.Context) (*board.Rating, error) {
+	return board.RateBin(ctx, p.Board)
+}
+
+// Lint lists the problems in a text puzzle after an optional
+// puzzle.Header; see the package function Lint.
+func (p *puzzleAdapter) Lint(lines []string) []error {
+	return puzzle.LintText(lines, Lint)
+}
+
+// looksLike reports whether lines could be a hitori puzzle: at least two
+// rows of the same length with a number in every cell, and some number
+// repeated within a row.
+func looksLike(lines []string) bool {
+	if len(lines) < 2 {
+		return false
+	}
+	nums, err := grid.LinesToNumGrid(lines)
+	if err != nil {
+		return false
+	}
+	repeated := false
+	for _, row := range nums {
+		if len(row) != len(nums[0]) {
+			return false
+		}
+		seen := make(map[int]bool)
+		for _, v := range row {
+			if v == 0 {
+				return false
+			}
+			repeated = repeated || seen[v]
+			seen[v] = true
+		}
+	}
+	return repeated
+}
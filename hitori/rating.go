@@ -0,0 +1,16 @@
+package hitori
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the hitori rules in increasing order of difficulty.
+func (b *Board) Techniques() []board.Technique {
+	return []board.Technique{
+		board.DirtyTechnique(&b.RectBoard, "ClearPaintedNeighbors", 1, b.ClearPaintedNeighbors),
+		board.DirtyTechnique(&b.RectBoard, "PaintClearTwins", 1, b.PaintClearTwins),
+		board.DirtyTechnique(&b.RectBoard, "ClearSingles", 1, b.ClearSingles),
+		board.DirtyTechnique(&b.RectBoard, "ClearSandwiches", 2, b.ClearSandwiches),
+		board.DirtyTechnique(&b.RectBoard, "IsolatePairs", 2, b.IsolatePairs),
+		board.DirtyTechnique(&b.RectBoard, "ClearMiniDominators", 2, b.ClearMiniDominators),
+		board.DirtyTechnique(&b.RectBoard, "ClearAllDominators", 4, b.ClearDominators),
+	}
+}
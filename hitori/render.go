@@ -0,0 +1,17 @@
+package hitori
+
+import "github.com/bismuthsalamander/mutantcheckerboard/render"
+
+// Scene describes the board for the renderers: each cell's shade and
+// number, every number a clue.
+func (b *Board) Scene() *render.Scene {
+	s := render.NewScene(b.W, b.H)
+	s.Meta = b.Meta
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		cell := s.At(c)
+		cell.Shade = b.Get(c)
+		cell.Value = b.NumAt(c)
+		cell.Clue = true
+	}
+	return s
+}
@@ -0,0 +1,17 @@
+package hitori
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckBin(t, BoardFromLines, []boardtest.Case{
+		{Name: "latin square", Lines: []string{"1234", "2341", "3412", "4123"}},
+		{Name: "unique", Lines: []string{"3144", "4241", "1234", "3424"}},
+		{Name: "six", Lines: []string{"3121", "1334", "4213", "2412"}},
+		{Name: "nine", Lines: []string{"4241", "1423", "2314", "3132"}},
+		{Name: "no solution", Lines: []string{"1122", "2211", "1122", "2211"}},
+	})
+}
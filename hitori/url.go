@@ -0,0 +1,48 @@
+package hitori
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// BoardFromURL reads a puzzle from a pzprjs "hitori" URL.
+func BoardFromURL(s string) (*Board, error) {
+	u, err := pzpr.ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "hitori" {
+		return nil, fmt.Errorf("puzzle URL is for %q, not hitori", u.Type)
+	}
+	nums, _, err := pzpr.DecodeNumber36(u.Body, u.Cols*u.Rows)
+	if err != nil {
+		return nil, err
+	}
+	numgrid := grid.MakeNumGrid(u.Cols, u.Rows)
+	for i, n := range nums {
+		c := grid.Coord{X: i % u.Cols, Y: i / u.Cols}
+		if n == pzpr.Question {
+			return nil, fmt.Errorf("cell %s holds a question mark, which is not supported", c)
+		} else if n <= 0 {
+			return nil, fmt.Errorf("cell %s holds no number", c)
+		}
+		numgrid[c.Y][c.X] = n
+	}
+	b := newBoard(numgrid)
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// URL returns the puzzle's numbers as a puzz.link URL.
+func (b *Board) URL() string {
+	nums := make([]int, 0, b.W*b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		nums = append(nums, b.NumAt(c))
+	}
+	u := pzpr.URL{Type: "hitori", Cols: b.W, Rows: b.H, Body: pzpr.EncodeNumber36(nums)}
+	return u.String()
+}
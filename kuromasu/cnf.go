@@ -0,0 +1,68 @@
+package kuromasu
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable painted(x,y) is true
+// when that cell is painted, and cells already known are unit clauses.
+//
+// A cross sees a cell when it and every cell between them are clear; an
+// And variable stands for each such run, and a counter makes exactly
+// Size-1 of them true. Clear cells must connect, which is encoded as
+// bounded reachability by board's EncodeClearConnected, from the first
+// cross or, on a board without one, from the top left cell or its
+// neighbor. The formula therefore grows with the square of the number of
+// cells.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, painted := b.encode()
+	return b.SetFromModel(m, painted)
+}
+
+// encode builds the formula CNF returns, along with the painted variable
+// of every cell.
+func (b *Board) encode() (*cnf.Formula, [][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d", TypeName, b.W, b.H)}
+	painted := b.CellVars(f, "painted")
+	p := func(c grid.Coord) int {
+		return painted[c.Y][c.X]
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for _, n := range []grid.Coord{c.Plus(grid.RIGHT), c.Plus(grid.DOWN)} {
+			if b.IsValid(n) {
+				f.Add(-p(c), -p(n))
+			}
+		}
+	}
+
+	for _, cross := range b.AllCrosses {
+		f.Add(-p(cross.Root))
+		seen := make([]int, 0)
+		for _, dir := range grid.DIRECTIONS {
+			run := 0
+			for n := cross.Root.Plus(dir); b.IsValid(n); n = n.Plus(dir) {
+				if run == 0 {
+					run = -p(n)
+				} else {
+					run = f.And(run, -p(n))
+				}
+				seen = append(seen, run)
+			}
+		}
+		f.Exactly(seen, cross.Size-1)
+	}
+
+	b.EncodeClearConnected(f, painted)
+	return f, painted
+}
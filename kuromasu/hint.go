@@ -0,0 +1,40 @@
+package kuromasu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+func (b *Board) crossName(c grid.Coord) string {
+	if cross := b.CrossAt(c); cross != nil {
+		return fmt.Sprintf("the %d at %s", cross.Size, c)
+	}
+	return fmt.Sprintf("the cross at %s", c)
+}
+
+// Explain describes why step s made mark m, for board.HintBin.
+func (b *Board) Explain(s board.Step, m board.CellValue) string {
+	switch s.Rule {
+	case "ClearPaintedNeighbors", "PostMark":
+		return fmt.Sprintf("it is next to painted cell %s", s.Cells[0])
+	case "ClearMiniDominators":
+		return fmt.Sprintf("it is the only liberty of clear cell %s", s.Cells[0])
+	case "ClearAllDominators":
+		return fmt.Sprintf("painting it would cut %s off from clear cell %s", s.Cells[1], s.Cells[0])
+	case "UpdateWingRange":
+		return fmt.Sprintf("%s must see at least this far in this direction", b.crossName(s.Cells[0]))
+	case "FinishWing":
+		if grid.Cell(m.Value) == grid.PAINTED {
+			return fmt.Sprintf("it ends a wing of %s that has reached its only possible length", b.crossName(s.Cells[0]))
+		}
+		return fmt.Sprintf("it lies on a wing of %s that has only one possible length", b.crossName(s.Cells[0]))
+	}
+	cells := make([]string, 0, len(s.Cells))
+	for _, c := range s.Cells {
+		cells = append(cells, c.String())
+	}
+	return fmt.Sprintf("follows from %s at %s", s.Rule, strings.Join(cells, " "))
+}
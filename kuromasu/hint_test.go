@@ -0,0 +1,51 @@
+package kuromasu
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+)
+
+// TestHint checks the hints for a small puzzle, from its start and from
+// partly solved states, against the text they are printed as.
+func TestHint(t *testing.T) {
+	puzzle := []string{"4__", "___", "3__"}
+	for _, tt := range []struct {
+		name  string
+		state []string
+		want  string
+	}{
+		{"start", nil, "(1,0) must be clear: the 4 at (0,0) must see at least this far in this direction [UpdateWingRange]"},
+		{"one wing started", []string{"4·?", "???", "3??"}, "(0,1) must be clear: the 4 at (0,0) must see at least this far in this direction [UpdateWingRange]"},
+		{"wing at its length", []string{"4·?", "·??", "3??"}, "(2,0) must be painted: it ends a wing of the 4 at (0,0) that has reached its only possible length [FinishWing]"},
+		{"solved", []string{"4·X", "···", "3X·"}, ""},
+		{"sees too far", []string{"4··", "·??", "3??"}, "error: state breaks a rule: contradiction at (0,0) in Validate: the 4 already sees 5"},
+		{"painted cells touch", []string{"4XX", "???", "3??"}, "error: state breaks a rule: contradiction at (1,0) in Validate: painted cell is next to painted cell (2,0)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(puzzle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.state != nil {
+				if err := b.ApplyState(tt.state); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before := b.String()
+			h, err := board.HintBin(b)
+			got := ""
+			if err != nil {
+				got = "error: " + err.Error()
+			} else if h != nil {
+				got = h.String()
+			}
+			if got != tt.want {
+				t.Errorf("hint is\n%s\nwant\n%s", got, tt.want)
+			}
+			if b.String() != before {
+				t.Errorf("Hint changed the board to\n%s\nfrom\n%s", b, before)
+			}
+		})
+	}
+}
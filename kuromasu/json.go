@@ -0,0 +1,69 @@
+package kuromasu
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// BoardFromDocument reads a puzzle from its crosses, then fills in any
+// given cells and narrows any wing ranges the document holds. Cells and
+// wings are taken as they are, without running the rules.
+func BoardFromDocument(d *puzzle.Document) (*Board, error) {
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	b := newBoard(board.NewRectBinBoard(d.Width, d.Height))
+	for _, cc := range d.Crosses {
+		if b.CrossAt(cc.At) != nil {
+			return nil, fmt.Errorf("two crosses at %s", cc.At)
+		}
+		if err := b.addCross(cc.At, cc.Size); err != nil {
+			return nil, err
+		}
+	}
+	b.Inited = true
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	if err := d.SetBinCells(&b.RectBinBoard); err != nil {
+		return nil, err
+	}
+	for _, wr := range d.Wings {
+		cross := b.CrossAt(wr.Root)
+		if cross == nil {
+			return nil, fmt.Errorf("wing root %s is not a cross", wr.Root)
+		}
+		w, ok := cross.Wings[wr.Dir]
+		if !ok {
+			return nil, fmt.Errorf("wing of %s has direction %s", wr.Root, wr.Dir)
+		}
+		if err := b.LimitWing(cross, w, wr.Min, wr.Max); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Document returns the puzzle with its current cells and wing ranges.
+func (b *Board) Document() *puzzle.Document {
+	d := &puzzle.Document{
+		Type:   TypeName,
+		Width:  b.W,
+		Height: b.H,
+		Author: b.Meta.Author,
+		Source: b.Meta.Source,
+		Cells:  b.Cells(),
+	}
+	for _, cross := range b.AllCrosses {
+		d.Crosses = append(d.Crosses, puzzle.CrossClue{At: cross.Root, Size: cross.Size})
+		for _, dir := range grid.DIRECTIONS {
+			w := cross.Wings[dir]
+			d.Wings = append(d.Wings, board.WingRange{Root: cross.Root, Dir: dir, Min: w.Min, Max: w.Max})
+		}
+	}
+	d.Solved, _ = b.IsSolved()
+	return d
+}
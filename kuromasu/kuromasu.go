@@ -0,0 +1,881 @@
+// Package kuromasu solves kuromasu (also known as kurodoko). Every number
+// must see exactly that many clear cells, counting itself, along its row and
+// column; painted cells may not touch, and all clear cells must be
+// connected.
+//
+// Each number is a Cross: four Wings whose possible lengths shrink as the
+// rules run.
+package kuromasu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+	"github.com/bismuthsalamander/mutantcheckerboard/set"
+)
+
+// Board is a kuromasu puzzle.
+type Board struct {
+	board.RectBinBoard
+	Crosses    [][]*Cross
+	AllCrosses []*Cross
+}
+
+// Cross is a number on the board together with the cells it can see.
+type Cross struct {
+	Root     grid.Coord
+	Size     int
+	Wings    map[grid.Delta]*Wing
+	IsCapped bool
+}
+
+// Wing is the run of clear cells a Cross sees in one direction; its length
+// lies between Min and Max.
+type Wing struct {
+	Dir      grid.Delta
+	Min      int
+	Max      int
+	IsCapped bool
+}
+
+// CrossAt returns the cross rooted at c, or nil if c holds no number.
+func (b *Board) CrossAt(c grid.Coord) *Cross {
+	return b.Crosses[c.Y][c.X]
+}
+
+// IsSolved reports whether every cell is known and every rule holds. The
+// error says what is still wrong.
+func (b *Board) IsSolved() (bool, error) {
+	// Are there any remaining unknown cells?
+	res, coord := b.IsComplete()
+	if !res {
+		return false, fmt.Errorf("cell %s is unknown", coord)
+	}
+
+	// Do all the crosses have the right size?
+	for _, cross := range b.AllCrosses {
+		ct := 1
+		for _, dir := range grid.DIRECTIONS {
+			coord := cross.Root.Plus(dir)
+			for b.IsClear(coord) {
+				ct++
+				coord = coord.Plus(dir)
+			}
+		}
+		if ct != cross.Size {
+			return false, fmt.Errorf("cross at %s needs %d, but has %d", cross.Root, cross.Size, ct)
+		}
+	}
+
+	// Are all clear cells contiguous?
+	reached := grid.NewCoordSet()
+	var start grid.Coord
+	b.EachCell(func(cd grid.Coord, v grid.Cell) bool {
+		if v == grid.CLEAR {
+			start = cd
+			return true
+		}
+		return false
+	})
+	frontier := make([]grid.Coord, 0, b.W*b.H)
+	frontier = append(frontier, start)
+	for len(frontier) > 0 {
+		touch := frontier[0]
+		reached.Add(touch)
+		frontier = frontier[1:]
+		b.EachNeighbor(touch, func(c grid.Coord, v grid.Cell) bool {
+			if v == grid.CLEAR && !reached.Has(c) {
+				frontier = append(frontier, c)
+				reached.Add(c)
+			}
+			return false
+		})
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !reached.Has(c) && b.Get(c) == grid.CLEAR {
+			return false, fmt.Errorf("cannot reach clear cell %s from %s", c, start)
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Validate returns an error if the board's current contents already break a
+// rule, even though some cells may still be unknown: a painted cross, two
+// adjacent painted cells, a cross that sees too many or can no longer see
+// enough cells, a wing whose range is empty, or clear cells that can no
+// longer be joined through unpainted cells. For a complete board, Validate
+// returns nil exactly when IsSolved returns true.
+func (b *Board) Validate() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsPainted(c) {
+			continue
+		}
+		if b.CrossAt(c) != nil {
+			return board.Contradiction(c, "Validate", "cross is painted")
+		}
+		for _, n := range []grid.Coord{c.Plus(grid.RIGHT), c.Plus(grid.DOWN)} {
+			if b.IsPainted(n) {
+				return board.Contradiction(c, "Validate", "painted cell is next to painted cell %s", n)
+			}
+		}
+	}
+	for _, cross := range b.AllCrosses {
+		seen, reach := 1, 1
+		for dir, wing := range cross.Wings {
+			if wing.Min > wing.Max || wing.Max < 0 {
+				return board.Contradiction(cross.Root, "Validate", "wing %s has empty range [%d,%d]", dir, wing.Min, wing.Max)
+			}
+			coord := cross.Root.Plus(dir)
+			for b.IsClear(coord) {
+				seen++
+				coord = coord.Plus(dir)
+			}
+			coord = cross.Root.Plus(dir)
+			for b.IsValid(coord) && !b.IsPainted(coord) {
+				reach++
+				coord = coord.Plus(dir)
+			}
+		}
+		if seen > cross.Size {
+			return board.Contradiction(cross.Root, "Validate", "the %d already sees %d", cross.Size, seen)
+		}
+		if reach < cross.Size {
+			return board.Contradiction(cross.Root, "Validate", "the %d can see at most %d", cross.Size, reach)
+		}
+	}
+
+	return b.ClearConnected()
+}
+
+// Clone returns a deep copy of the board, including every cross and wing, so
+// that rules can run on the copy without touching the original.
+func (b *Board) Clone() *Board {
+	out := &Board{
+		RectBinBoard: *b.RectBinBoard.Clone(),
+		Crosses:      MakeCrosses(b.W, b.H),
+		AllCrosses:   make([]*Cross, 0, len(b.AllCrosses)),
+	}
+	for _, cross := range b.AllCrosses {
+		nc := cross.Clone()
+		out.Crosses[nc.Root.Y][nc.Root.X] = nc
+		out.AllCrosses = append(out.AllCrosses, nc)
+	}
+	return out
+}
+
+func (c *Cross) Clone() *Cross {
+	out := &Cross{
+		Root:     c.Root,
+		Size:     c.Size,
+		Wings:    make(map[grid.Delta]*Wing),
+		IsCapped: c.IsCapped,
+	}
+	for dir, w := range c.Wings {
+		tmp := *w
+		out.Wings[dir] = &tmp
+	}
+	return out
+}
+
+// Mark writes v to c and, once the board is set up, runs the rules that
+// follow from it.
+func (b *Board) Mark(c grid.Coord, v grid.Cell) (bool, error) {
+	res, err := b.Set(c, v)
+	if !res {
+		return res, err
+	}
+	b.SetDirty()
+	return res, b.PostMark(c, v)
+}
+
+func (b *Board) MarkPainted(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.PAINTED)
+}
+
+func (b *Board) MarkClear(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.CLEAR)
+}
+
+func (c *Cross) String() string {
+	return strings.TrimSpace(grid.FormatFor(c.Size).Num(c.Size))
+}
+
+func (c *Cross) StringVerbose() string {
+	out := fmt.Sprintf("Cross at %s sz %d\n", c.Root, c.Size)
+	for dir, wing := range c.Wings {
+		out += fmt.Sprintf("\tWing %s [%d-%d] capped %v\n", dir, wing.Min, wing.Max, wing.IsCapped)
+	}
+	return out[:len(out)-1]
+}
+
+// Generates an empty 2d slice of pointers to Cross structs.
+func MakeCrosses(w, h int) [][]*Cross {
+	c := make([][]*Cross, 0, h)
+	for i := 0; i < h; i++ {
+		c = append(c, make([]*Cross, w))
+	}
+	return c
+}
+
+// Generates default wings for an island rooted at c with a size crossSize. Initial values are
+// chosen so that wings cannot run off the board OR exceed the size of the entire cross.
+func (b *Board) MakeWings(c grid.Coord, crossSize int) map[grid.Delta]*Wing {
+	maxsz := crossSize - 1
+	mp := make(map[grid.Delta]*Wing)
+	mp[grid.LEFT] = &Wing{grid.LEFT, 0, min(maxsz, c.X), false}
+	mp[grid.RIGHT] = &Wing{grid.RIGHT, 0, min(maxsz, b.W-(c.X+1)), false}
+	mp[grid.UP] = &Wing{grid.UP, 0, min(maxsz, c.Y), false}
+	mp[grid.DOWN] = &Wing{grid.DOWN, 0, min(maxsz, b.H-(c.Y+1)), false}
+	return mp
+}
+
+// String draws the board in a frame, with X for painted cells and · for
+// clear ones. Clues above 35 switch every cell to the wide grid.CellFormat.
+func (b *Board) String() string {
+	largest := 0
+	for _, cross := range b.AllCrosses {
+		largest = max(largest, cross.Size)
+	}
+	f := grid.FormatFor(largest)
+	out := "+" + strings.Repeat("-", b.W*f.Len()) + "+\n"
+	for y, row := range b.Grid {
+		out += "|"
+		for x := range row {
+			if b.Crosses[y][x] != nil {
+				out += f.Num(b.Crosses[y][x].Size)
+			} else {
+				out += f.Mark(b.Get(grid.Coord{X: x, Y: y}).Ch())
+			}
+		}
+		out += "|"
+		if y != b.H-1 {
+			out += "\n"
+		}
+	}
+	out += "\n+" + strings.Repeat("-", b.W*f.Len()) + "+"
+	return out
+}
+
+func (b *Board) StringVerbose() string {
+	out := b.String()
+	out += "\n\nCrosses:\n"
+	for _, cross := range b.AllCrosses {
+		if cross == nil {
+			continue
+		}
+		out += fmt.Sprintf("%s\n", cross.StringVerbose())
+	}
+	return out
+}
+
+// PostMark runs the rules that follow from v having just been written to c.
+func (b *Board) PostMark(c grid.Coord, v grid.Cell) error {
+	// Clear adjacent cells to paint
+	if v == grid.PAINTED {
+		b.BeginStep("PostMark", c)
+		var err error
+		b.EachNeighbor(c, func(n grid.Coord, v grid.Cell) bool {
+			_, err = b.MarkClear(n)
+			return err != nil
+		})
+		b.EndStep()
+		if err != nil {
+			return err
+		}
+	}
+	// We skip updates of adjacent crosses if we haven't finished initializing the board; else,
+	// the wing range updates would be inaccurate
+	if !b.InitDone() {
+		return nil
+	}
+	//TODO: alternatively, cascade a "dirty" mark to affected crosses, then update the dirty ones?
+	//helps efficiency of batch updates?
+	for _, dir := range grid.DIRECTIONS {
+		coord := c.Plus(dir)
+		for b.IsValid(coord) && !b.IsPainted(coord) {
+			if err := b.UpdateWingRange(b.CrossAt(coord), dir.Reverse()); err != nil {
+				return err
+			}
+			coord = coord.Plus(dir)
+		}
+	}
+	return nil
+}
+
+func (b *Board) CheckAllWingCaps(c *Cross) error {
+	for _, w := range c.Wings {
+		if w.Min == w.Max && !w.IsCapped {
+			if err := b.FinishWing(c, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Mark this wing as capped, and mark the cross as capped if each of its wings is capped.
+func (c *Cross) MarkWingCapped(w *Wing) {
+	w.IsCapped = true
+	for _, wg := range c.Wings {
+		if !wg.IsCapped {
+			return
+		}
+	}
+	c.IsCapped = true
+}
+
+// saveWing records how to undo changes to the range and cap of w.
+func (b *Board) saveWing(w *Wing) {
+	if !b.Recording() {
+		return
+	}
+	old := *w
+	b.OnRollback(func() { *w = old })
+}
+
+// saveCross records how to undo changes to the cap of c and to each of its
+// wings.
+func (b *Board) saveCross(c *Cross) {
+	if !b.Recording() {
+		return
+	}
+	capped := c.IsCapped
+	b.OnRollback(func() { c.IsCapped = capped })
+	for _, w := range c.Wings {
+		b.saveWing(w)
+	}
+}
+
+// If the wing's min and max are wider than the arguments, tighten the wing's range. Returns a
+// ContradictionError if that leaves the wing with no possible length.
+func (b *Board) LimitWing(c *Cross, w *Wing, min, max int) error {
+	if w.Min < min || w.Max > max {
+		b.saveWing(w)
+	}
+	changed := false
+	if w.Min < min {
+		w.Min = min
+		changed = true
+	}
+	if w.Max > max {
+		w.Max = max
+		changed = true
+	}
+	if changed {
+		b.SetDirty()
+		b.StepWing(c.Root, w.Dir, w.Min, w.Max)
+	}
+	return checkWing(c, w, "LimitWing")
+}
+
+// checkWing returns a ContradictionError if the wing's range is empty.
+func checkWing(c *Cross, w *Wing, rule string) error {
+	if w.Max < 0 || w.Min > w.Max {
+		return board.Contradiction(c.Root, rule, "the %d needs a wing %s of length [%d,%d]", c.Size, w.Dir, w.Min, w.Max)
+	}
+	return nil
+}
+
+// This function completes each wing of the cross, using each wing's current Min as its Max size.
+func (b *Board) FinishCross(cross *Cross) error {
+	b.BeginStep("FinishCross", cross.Root)
+	defer b.EndStep()
+	for _, wing := range cross.Wings {
+		if wing.Max != wing.Min {
+			b.saveWing(wing)
+			wing.Max = wing.Min
+			b.StepWing(cross.Root, wing.Dir, wing.Min, wing.Max)
+		}
+		if err := b.FinishWing(cross, wing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run this function when we know the wing must have size exactly equal to its Min. FinishWing will
+// fill in the clear cells and the painted "cap."
+func (b *Board) FinishWing(cross *Cross, w *Wing) error {
+	b.BeginStep("FinishWing", cross.Root)
+	defer b.EndStep()
+	coord := cross.Root
+	for i := 1; i <= w.Min; i++ {
+		coord = coord.Plus(w.Dir)
+		if !b.IsValid(coord) {
+			return board.Contradiction(cross.Root, "FinishWing", "wing %s of length %d runs off the board after %d cells", w.Dir, w.Min, i-1)
+		}
+		if _, err := b.MarkClear(coord); err != nil {
+			return err
+		}
+	}
+	cap := cross.Root.Plus(w.Dir.Times(w.Min + 1))
+	if b.IsValid(cap) {
+		if _, err := b.MarkPainted(cap); err != nil {
+			return err
+		}
+	}
+	b.saveCross(cross)
+	cross.MarkWingCapped(w)
+	return nil
+}
+
+func (b *Board) UpdateWingRange(cross *Cross, dir grid.Delta) error {
+	if cross == nil {
+		return nil
+	}
+	wing := cross.Wings[dir]
+	if wing.IsCapped {
+		return nil
+	}
+	b.BeginStep("UpdateWingRange", cross.Root)
+	defer b.EndStep()
+
+	// Calculate range of possible sizes of this wing based on other wings' ranges
+	myWingMax := cross.Size - 1
+	myWingMin := cross.Size - 1
+	for od, ow := range cross.Wings {
+		if od == dir {
+			continue
+		}
+		myWingMax -= ow.Min
+		myWingMin -= ow.Max
+	}
+	if myWingMax < 0 || myWingMin > myWingMax {
+		return board.Contradiction(cross.Root, "UpdateWingRange", "the %d would need a wing %s of length [%d,%d]", cross.Size, dir, myWingMin, myWingMax)
+	}
+	if err := b.LimitWing(cross, wing, myWingMin, myWingMax); err != nil {
+		return err
+	}
+
+	// Update min and max to match reality (i.e., if there's a painted cell along dir, decrease Max
+	// so that the wing can't extend past that painted cell; if there is a contiguous block of
+	// clear cells larger than Min, increate Min accordingly).
+	coord := cross.Root.Plus(dir)
+	wingsz := 1
+
+	// Stop increasing Min once we've seen the first unknown cell. Track with the allClear flag.
+	allClear := true
+	myWingMin = wing.Min
+	myWingMax = wing.Max
+	for b.IsValid(coord) && wingsz <= wing.Max {
+		if b.IsClear(coord) {
+			if allClear {
+				myWingMin = wingsz
+			}
+		} else if b.IsPainted(coord) {
+			myWingMax = wingsz - 1
+			break
+		} else if b.IsUnknown(coord) {
+			if wingsz <= wing.Min && allClear {
+				if _, err := b.MarkClear(coord); err != nil {
+					return err
+				}
+			}
+			allClear = false
+		}
+		wingsz++
+		coord = coord.Plus(dir)
+	}
+	if err := b.LimitWing(cross, wing, myWingMin, myWingMax); err != nil {
+		return err
+	}
+	if wing.Min == wing.Max && !wing.IsCapped {
+		return b.FinishWing(cross, wing)
+	}
+	return nil
+}
+
+func (b *Board) UpdateWingRanges() error {
+	for _, cross := range b.AllCrosses {
+		if cross.IsCapped {
+			continue
+		}
+		for _, dir := range grid.DIRECTIONS {
+			if err := b.UpdateWingRange(cross, dir); err != nil {
+				return err
+			}
+		}
+
+		// If our wing minimums are enough to fill the cross, the corss is done.
+		sz := 1
+		for _, wing := range cross.Wings {
+			sz += wing.Min
+		}
+		if sz == cross.Size {
+			if err := b.FinishCross(cross); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Board) RestrictWingsForExtending() error {
+	for _, c := range b.AllCrosses {
+		for dir, w := range c.Wings {
+			if w.IsCapped {
+				continue
+			}
+			if err := b.RestrictWingForExtending(c, dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Board) RestrictWingForExtending(c *Cross, dir grid.Delta) error {
+	b.BeginStep("RestrictWingForExtending", c.Root)
+	defer b.EndStep()
+	w := c.Wings[dir]
+	b.saveWing(w)
+	oldMin, oldMax := w.Min, w.Max
+	defer func() {
+		if w.Min != oldMin || w.Max != oldMax {
+			b.StepWing(c.Root, dir, w.Min, w.Max)
+		}
+	}()
+	// reduce max because max would extend
+	for w.Max >= w.Min {
+		nextCell := c.Root.Plus(dir.Times(c.Wings[dir].Max + 1))
+		if !b.IsClear(nextCell) {
+			break
+		}
+		c.Wings[dir].Max--
+		b.SetDirty()
+	}
+	// increase min because min would extend
+	for {
+		nextCell := c.Root.Plus(dir.Times(c.Wings[dir].Min + 1))
+		if !b.IsClear(nextCell) {
+			break
+		}
+		c.Wings[dir].Min++
+		b.SetDirty()
+	}
+	return checkWing(c, w, "RestrictWingForExtending")
+}
+
+// TODO: I think we can unify some of these range checks?
+// If extending cross C's wing would cause it to merge with cross D, and cross D can't extend that
+// far, we need to reduce C's wing's Max so that it can't merge with D anymore.
+func (b *Board) CheckCrossMerging() error {
+	for _, cross := range b.AllCrosses {
+		if cross.IsCapped {
+			continue
+		}
+	oneWing:
+		for dir, w := range cross.Wings {
+			if w.IsCapped {
+				continue
+			}
+			oppWing := cross.Wings[dir.Reverse()]
+			oppWingEnd := cross.Root.Plus(dir.Reverse().Times(oppWing.Min))
+			for trywinglen := w.Min; trywinglen <= w.Max; trywinglen++ {
+				neighbors := make([]*Cross, 0)
+				tryneighbor := cross.Root.Plus(dir.Times(trywinglen + 1))
+				for b.IsValid(tryneighbor) && b.IsClear(tryneighbor) {
+					if b.CrossAt(tryneighbor) != nil {
+						neighbors = append(neighbors, b.CrossAt(tryneighbor))
+					}
+					tryneighbor = tryneighbor.Plus(dir)
+				}
+				for _, nc := range neighbors {
+					ncWing := nc.Wings[dir.Reverse()]
+					dist := nc.Root.MHDist(oppWingEnd)
+					if ncWing.Max < dist {
+						b.BeginStep("CheckCrossMerging", cross.Root, nc.Root)
+						b.saveWing(w)
+						w.Max = trywinglen - 1
+						b.StepWing(cross.Root, dir, w.Min, w.Max)
+						b.EndStep()
+						b.SetDirty()
+						if err := checkWing(cross, w, "CheckCrossMerging"); err != nil {
+							return err
+						}
+						break oneWing
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ClearPaintedNeighbors is board.RectBinBoard.ClearPaintedNeighbors, marking through Mark. PostMark
+// already clears the neighbors of every cell it paints.
+func (b *Board) ClearPaintedNeighbors() error {
+	return b.RectBinBoard.ClearPaintedNeighbors(b.Mark)
+}
+
+// ClearMiniDominators is board.RectBinBoard.ClearMiniDominators, marking through Mark.
+func (b *Board) ClearMiniDominators() error {
+	return b.RectBinBoard.ClearMiniDominators(b.Mark)
+}
+
+// ClearDominators is board.RectBinBoard.ClearDominators, marking through Mark.
+func (b *Board) ClearDominators() error {
+	return b.RectBinBoard.ClearDominators(b.Mark)
+}
+
+// Detects crosses that are connected along one axis and cross-enforces limitations
+func (b *Board) UpdateSharedRanges() error {
+	coords := grid.NewCoordSet()
+	for x := 0; x < b.W; x++ {
+		coords.Clear()
+		for y := 0; y < b.H; y++ {
+			c := grid.Coord{X: x, Y: y}
+			if !b.IsClear(c) {
+				if err := b.ShareRangesVertical(coords); err != nil {
+					return err
+				}
+				coords.Clear()
+			} else if b.CrossAt(c) != nil {
+				coords.Add(c)
+			}
+		}
+		if err := b.ShareRangesVertical(coords); err != nil {
+			return err
+		}
+	}
+	coords.Clear()
+	for y := 0; y < b.H; y++ {
+		coords.Clear()
+		for x := 0; x < b.W; x++ {
+			c := grid.Coord{X: x, Y: y}
+			if !b.IsClear(c) {
+				if err := b.ShareRangesHorizontal(coords); err != nil {
+					return err
+				}
+				coords.Clear()
+			} else if b.CrossAt(c) != nil {
+				coords.Add(c)
+			}
+		}
+		if err := b.ShareRangesHorizontal(coords); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Board) ShareRangesVertical(s *set.Set[grid.Coord]) error {
+	return b.ShareRanges(s, grid.LEFT, grid.RIGHT, grid.UP, grid.DOWN)
+}
+
+func (b *Board) ShareRangesHorizontal(s *set.Set[grid.Coord]) error {
+	return b.ShareRanges(s, grid.UP, grid.DOWN, grid.LEFT, grid.RIGHT)
+}
+
+/*
+two crosses share an axis
+no cross can:
+  - expand past the lowest axis max in the group
+  - have a minimum below the highest min in the group
+
+find the lowest shared-axis max
+find the highest shared-axis min
+
+each cross must:
+  - have its own cross-axis required between its two cross-wings
+*/
+
+func (b *Board) ApplyAxisRange(c *Cross, axisMin, axisMax int, dir1, dir2 grid.Delta) error {
+	min2 := axisMin - c.Wings[dir1].Max
+	min1 := axisMin - c.Wings[dir2].Max
+	max2 := axisMax - c.Wings[dir1].Min
+	max1 := axisMax - c.Wings[dir2].Min
+	if err := b.LimitWing(c, c.Wings[dir1], min1, max1); err != nil {
+		return err
+	}
+	return b.LimitWing(c, c.Wings[dir2], min2, max2)
+}
+
+// TODO: change this to track axis mins and maxes on cross struct
+func (b *Board) ShareRanges(s *set.Set[grid.Coord], cross1 grid.Delta, cross2 grid.Delta, shared1 grid.Delta, shared2 grid.Delta) error {
+	if s.Size() < 2 {
+		return nil
+	}
+	b.BeginStep("ShareRanges", s.Sorted()...)
+	defer b.EndStep()
+	first := true
+	axisSharedMin := 0
+	axisSharedMax := 0
+	for k := range s.M {
+		c := b.CrossAt(k)
+		sharedMin := (c.Size - 1) - (c.Wings[cross1].Max + c.Wings[cross2].Max)
+		sharedMax := (c.Size - 1) - (c.Wings[cross1].Min + c.Wings[cross2].Min)
+		sharedBoardSize := b.H
+		if shared1 == grid.LEFT || shared2 == grid.LEFT {
+			sharedBoardSize = b.W
+		}
+		if sharedMax > sharedBoardSize {
+			sharedMax = sharedBoardSize
+		}
+		// fmt.Printf("Cross %s min along shared is %d; max along shared is %d\n", c, sharedMin, sharedMax)
+		if first || sharedMin > axisSharedMin {
+			axisSharedMin = sharedMin
+		}
+		if first || sharedMax < axisSharedMax {
+			axisSharedMax = sharedMax
+		}
+		first = false
+	}
+	// fmt.Printf("High shared min is %d; low shared max is %d\n", axisSharedMin, axisSharedMax)
+	for k := range s.M {
+		c := b.CrossAt(k)
+		if err := b.ApplyAxisRange(c, axisSharedMin, axisSharedMax, shared1, shared2); err != nil {
+			return err
+		}
+
+		crossAxisMin := c.Size - (1 + c.Wings[shared1].Max + c.Wings[shared2].Max)
+		crossAxisMax := c.Size - (1 + c.Wings[shared1].Min + c.Wings[shared2].Min)
+		if err := b.ApplyAxisRange(c, crossAxisMin, crossAxisMax, cross1, cross2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Solve applies the rules until none of them makes progress. Returns a ContradictionError if the
+// board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	b.SetDirty()
+	for b.IsDirty() {
+		b.ClearDirty()
+		for _, rule := range []func() error{
+			b.UpdateWingRanges,
+			b.RestrictWingsForExtending,
+			b.CheckCrossMerging,
+			b.UpdateSharedRanges,
+			b.ClearMiniDominators,
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			if err := rule(); err != nil {
+				return err
+			}
+		}
+		if b.IsDirty() {
+			continue
+		}
+		if err := board.Stopped(ctx); err != nil {
+			return err
+		}
+		if err := b.ClearDominators(); err != nil {
+			return err
+		}
+	}
+	return b.Validate()
+}
+
+// BoardFromLines reads a puzzle in which every digit or lowercase letter (see
+// grid.CharToNum) is a number and anything else is an empty cell, or a
+// tokenized grid (see grid.IsTokenGrid) for clues above 35. A single line
+// holding a puzz.link URL is read with BoardFromURL. Every problem Lint
+// finds is returned, joined with errors.Join.
+func BoardFromLines(input []string) (*Board, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("puzzle is empty")
+	}
+	if len(input) == 1 && pzpr.IsURL(input[0]) {
+		return BoardFromURL(input[0])
+	}
+	if errs := Lint(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	nums, err := grid.LinesToNumGrid(input)
+	if err != nil {
+		return nil, err
+	}
+	w, _ := grid.Width(input, nums)
+	rg := newBoard(board.NewRectBinBoard(w, len(nums)))
+	for y, row := range nums {
+		for x, val := range row {
+			if val == 0 {
+				continue
+			}
+			if err := rg.addCross(grid.Coord{X: x, Y: y}, val); err != nil {
+				return nil, err
+			}
+		}
+	}
+	rg.Inited = true
+	return rg, nil
+}
+
+// Lines returns the puzzle's clues in the text format BoardFromLines reads.
+func (b *Board) Lines() []string {
+	nums := grid.MakeNumGrid(b.W, b.H)
+	for _, cross := range b.AllCrosses {
+		nums[cross.Root.Y][cross.Root.X] = cross.Size
+	}
+	return grid.NumGridToLines(nums, grid.UNKNOWN)
+}
+
+// newBoard returns a board with no crosses on top of rect.
+func newBoard(rect *board.RectBinBoard) *Board {
+	return &Board{
+		RectBinBoard: *rect,
+		Crosses:      MakeCrosses(rect.W, rect.H),
+		AllCrosses:   make([]*Cross, 0),
+	}
+}
+
+// addCross places a cross of the given size at c while the board is being
+// loaded.
+func (b *Board) addCross(c grid.Coord, size int) error {
+	b.Crosses[c.Y][c.X] = &Cross{
+		Root:     c,
+		Size:     size,
+		Wings:    b.MakeWings(c, size),
+		IsCapped: false,
+	}
+	b.AllCrosses = append(b.AllCrosses, b.Crosses[c.Y][c.X])
+	if _, err := b.MarkClear(c); err != nil {
+		return err
+	}
+	return b.CheckAllWingCaps(b.Crosses[c.Y][c.X])
+}
+
+func (c *Cross) NumPossibilities() uint64 {
+	ct := uint64(0)
+	for left := c.Wings[grid.LEFT].Min; left <= c.Wings[grid.LEFT].Max; left++ {
+		for up := c.Wings[grid.UP].Min; up <= c.Wings[grid.UP].Max; up++ {
+			for right := c.Wings[grid.RIGHT].Min; right <= c.Wings[grid.RIGHT].Max; right++ {
+				down := c.Size - (left + up + right + 1)
+				if down >= c.Wings[grid.DOWN].Min && down <= c.Wings[grid.DOWN].Max {
+					ct++
+				}
+			}
+		}
+	}
+	return ct
+}
+
+func (b *Board) NumPossibilities() *big.Int {
+	tot := big.NewInt(1)
+	for _, c := range b.AllCrosses {
+		if !c.IsCapped {
+			tot.Mul(tot, big.NewInt(int64(c.NumPossibilities())))
+		}
+	}
+	return tot
+}
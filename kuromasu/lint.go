@@ -0,0 +1,64 @@
+package kuromasu
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place:
+// rows of a different width (see grid.Width), clues of 0, clues larger
+// than the W+H-1 cells a cross can see, and a clue of 1 next to another
+// clue, which would have to be painted. A puzz.link URL is left to BoardFromURL.
+func Lint(lines []string) []error {
+	if len(lines) == 0 {
+		return []error{fmt.Errorf("puzzle is empty")}
+	}
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return nil
+	}
+	nums, err := grid.LinesToNumGrid(lines)
+	if err != nil {
+		return []error{err}
+	}
+	w, errs := grid.Width(lines, nums)
+	h := len(nums)
+	clueAt := func(c grid.Coord) int {
+		if c.Y < 0 || c.Y >= h || c.X < 0 || c.X >= min(w, len(nums[c.Y])) {
+			return 0
+		}
+		return nums[c.Y][c.X]
+	}
+	tokens := grid.IsTokenGrid(lines)
+	isZero := func(x, y int) bool {
+		if tokens {
+			return grid.SplitTokens(lines[y])[x] == "0"
+		}
+		return lines[y][x] == '0'
+	}
+	for y, row := range nums {
+		for x := range row {
+			c := grid.Coord{X: x, Y: y}
+			v := clueAt(c)
+			if v == 0 {
+				if isZero(x, y) {
+					errs = append(errs, grid.LineErrorf(y+1, x+1, "clue 0; a cross always sees itself"))
+				}
+				continue
+			}
+			if v > w+h-1 {
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "clue %d is more than the %d cells a cross on a %dx%d board can see", v, w+h-1, w, h))
+			}
+			if v == 1 {
+				for _, dir := range grid.DIRECTIONS {
+					if n := c.Plus(dir); clueAt(n) != 0 {
+						errs = append(errs, grid.LineErrorf(y+1, x+1, "clue 1 needs its neighbors painted, but line %d, column %d holds a clue", n.Y+1, n.X+1))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,16 @@
+package kuromasu
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the kuromasu rules in increasing order of difficulty.
+func (b *Board) Techniques() []board.Technique {
+	return []board.Technique{
+		board.DirtyTechnique(&b.RectBoard, "ClearPaintedNeighbors", 1, b.ClearPaintedNeighbors),
+		board.DirtyTechnique(&b.RectBoard, "UpdateWingRanges", 1, b.UpdateWingRanges),
+		board.DirtyTechnique(&b.RectBoard, "ClearMiniDominators", 1, b.ClearMiniDominators),
+		board.DirtyTechnique(&b.RectBoard, "RestrictWingsForExtending", 2, b.RestrictWingsForExtending),
+		board.DirtyTechnique(&b.RectBoard, "UpdateSharedRanges", 3, b.UpdateSharedRanges),
+		board.DirtyTechnique(&b.RectBoard, "CheckCrossMerging", 3, b.CheckCrossMerging),
+		board.DirtyTechnique(&b.RectBoard, "ClearAllDominators", 4, b.ClearDominators),
+	}
+}
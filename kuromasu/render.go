@@ -0,0 +1,19 @@
+package kuromasu
+
+import "github.com/bismuthsalamander/mutantcheckerboard/render"
+
+// Scene describes the board for the renderers: each cell's shade, with the
+// crosses as clues.
+func (b *Board) Scene() *render.Scene {
+	s := render.NewScene(b.W, b.H)
+	s.Meta = b.Meta
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		cell := s.At(c)
+		cell.Shade = b.Get(c)
+		if cross := b.CrossAt(c); cross != nil {
+			cell.Value = cross.Size
+			cell.Clue = true
+		}
+	}
+	return s
+}
@@ -0,0 +1,26 @@
+package kuromasu
+
+import (
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// PickUnknown chooses the cell for board.SearchBin to branch on. Unknown
+// cells next to a clear cell are preferred because either value has
+// immediate consequences for the clear region.
+func (b *Board) PickUnknown() grid.Coord {
+	first := grid.Coord{X: -1, Y: -1}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsUnknown(c) {
+			continue
+		}
+		if first.X < 0 {
+			first = c
+		}
+		for _, dir := range grid.DIRECTIONS {
+			if b.IsClear(c.Plus(dir)) {
+				return c
+			}
+		}
+	}
+	return first
+}
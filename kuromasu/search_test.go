@@ -0,0 +1,18 @@
+package kuromasu
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckBin(t, BoardFromLines, []boardtest.Case{
+		{Name: "no clues", Lines: []string{"____", "____", "____", "____"}},
+		{Name: "one clue", Lines: []string{"____", "_5__", "____", "____"}},
+		{Name: "unique", Lines: []string{"3___", "__5_", "____", "___2"}},
+		{Name: "corner pair", Lines: []string{"2___", "____", "____", "___7"}},
+		{Name: "four corners", Lines: []string{"7__7", "____", "____", "7__7"}},
+		{Name: "no solution", Lines: []string{"3__2", "____", "____", "2__3"}},
+	})
+}
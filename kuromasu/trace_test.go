@@ -0,0 +1,64 @@
+package kuromasu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+)
+
+// TestTrace checks the steps of solving a small puzzle, in both trace
+// formats, against the text they are written as.
+func TestTrace(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{
+			"UpdateWingRange at (0,0); mark (1,0) clear; wing (0,0) RIGHT [1,2]",
+			"UpdateWingRange at (0,0); mark (0,1) clear; wing (0,0) DOWN [1,2]",
+			"UpdateWingRange at (0,0); wing (0,0) DOWN [2,2]",
+			"UpdateWingRange at (0,2); wing (0,2) UP [2,2]",
+			"FinishCross at (0,0); wing (0,0) RIGHT [1,1]",
+			"FinishWing at (0,0); mark (2,0) painted",
+			"PostMark at (2,0); mark (2,1) clear",
+			"UpdateWingRange at (0,2); wing (0,2) RIGHT [0,0]",
+			"FinishWing at (0,2); mark (1,2) painted",
+			"PostMark at (1,2); mark (2,2) clear; mark (1,1) clear",
+		}},
+		{"json", []string{
+			`{"rule":"UpdateWingRange","cells":[{"x":0,"y":0}],"marked":[{"at":{"x":1,"y":0},"value":2}],"wings":[{"root":{"x":0,"y":0},"dir":{"x":1,"y":0},"min":1,"max":2}]}`,
+			`{"rule":"UpdateWingRange","cells":[{"x":0,"y":0}],"marked":[{"at":{"x":0,"y":1},"value":2}],"wings":[{"root":{"x":0,"y":0},"dir":{"x":0,"y":1},"min":1,"max":2}]}`,
+			`{"rule":"UpdateWingRange","cells":[{"x":0,"y":0}],"wings":[{"root":{"x":0,"y":0},"dir":{"x":0,"y":1},"min":2,"max":2}]}`,
+			`{"rule":"UpdateWingRange","cells":[{"x":0,"y":2}],"wings":[{"root":{"x":0,"y":2},"dir":{"x":0,"y":-1},"min":2,"max":2}]}`,
+			`{"rule":"FinishCross","cells":[{"x":0,"y":0}],"wings":[{"root":{"x":0,"y":0},"dir":{"x":1,"y":0},"min":1,"max":1}]}`,
+			`{"rule":"FinishWing","cells":[{"x":0,"y":0}],"marked":[{"at":{"x":2,"y":0},"value":1}]}`,
+			`{"rule":"PostMark","cells":[{"x":2,"y":0}],"marked":[{"at":{"x":2,"y":1},"value":2}]}`,
+			`{"rule":"UpdateWingRange","cells":[{"x":0,"y":2}],"wings":[{"root":{"x":0,"y":2},"dir":{"x":1,"y":0},"min":0,"max":0}]}`,
+			`{"rule":"FinishWing","cells":[{"x":0,"y":2}],"marked":[{"at":{"x":1,"y":2},"value":1}]}`,
+			`{"rule":"PostMark","cells":[{"x":1,"y":2}],"marked":[{"at":{"x":2,"y":2},"value":2},{"at":{"x":1,"y":1},"value":2}]}`,
+		}},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			b, err := BoardFromLines([]string{"4__", "___", "3__"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.EnableTrace()
+			if err := b.Solve(); err != nil {
+				t.Fatal(err)
+			}
+			if solved, err := b.IsSolved(); !solved {
+				t.Fatalf("not solved: %v\n%s", err, b)
+			}
+			var out bytes.Buffer
+			if err := board.WriteTrace(&out, b.Steps(), tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := out.String(), strings.Join(tt.want, "\n")+"\n"; got != want {
+				t.Errorf("trace is\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
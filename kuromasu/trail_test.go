@@ -0,0 +1,86 @@
+package kuromasu
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+)
+
+// TestRollback checks that rolling back nested checkpoints restores the
+// cells and the wing ranges of every cross.
+func TestRollback(t *testing.T) {
+	b, err := BoardFromLines([]string{"4__", "___", "3__"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := b.Clone()
+
+	b.Checkpoint()
+	if err := b.UpdateWingRanges(); err != nil {
+		t.Fatal(err)
+	}
+	outer := b.Clone()
+	if sameState(b, before) {
+		t.Fatal("UpdateWingRanges changed nothing, so the test proves nothing")
+	}
+
+	b.Checkpoint()
+	if err := b.Solve(); err != nil {
+		t.Fatal(err)
+	}
+	b.Rollback()
+	if !sameState(b, outer) {
+		t.Errorf("inner rollback left\n%s\nwant\n%s", dump(b), dump(outer))
+	}
+
+	b.Rollback()
+	if !sameState(b, before) {
+		t.Errorf("outer rollback left\n%s\nwant\n%s", dump(b), dump(before))
+	}
+}
+
+// TestRollbackSearch checks that a search made under a checkpoint, which
+// replaces the whole board with the solution it found, is undone too.
+func TestRollbackSearch(t *testing.T) {
+	b, err := BoardFromLines([]string{"3___", "__5_", "____", "___2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := b.Clone()
+	b.Checkpoint()
+	if ok, err := board.SearchBin(context.Background(), b); !ok {
+		t.Fatal(err)
+	}
+	b.Rollback()
+	if !sameState(b, before) {
+		t.Errorf("rollback left\n%s\nwant\n%s", dump(b), dump(before))
+	}
+}
+
+func sameState(a, b *Board) bool {
+	if !reflect.DeepEqual(a.Grid, b.Grid) || !reflect.DeepEqual(a.Guess, b.Guess) || len(a.AllCrosses) != len(b.AllCrosses) {
+		return false
+	}
+	for i, ca := range a.AllCrosses {
+		cb := b.AllCrosses[i]
+		if ca.Root != cb.Root || ca.IsCapped != cb.IsCapped || len(ca.Wings) != len(cb.Wings) {
+			return false
+		}
+		for dir, w := range ca.Wings {
+			if cb.Wings[dir] == nil || *w != *cb.Wings[dir] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func dump(b *Board) string {
+	out := b.String() + "\n"
+	for _, c := range b.AllCrosses {
+		out += c.StringVerbose() + "\n"
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package kuromasu
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// BoardFromURL reads a puzzle from a pzprjs "kurodoko" URL.
+func BoardFromURL(s string) (*Board, error) {
+	u, err := pzpr.ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "kurodoko" {
+		return nil, fmt.Errorf("puzzle URL is for %q, not kurodoko", u.Type)
+	}
+	nums, _, err := pzpr.DecodeNumber16(u.Body, u.Cols*u.Rows)
+	if err != nil {
+		return nil, err
+	}
+	b := newBoard(board.NewRectBinBoard(u.Cols, u.Rows))
+	for i, n := range nums {
+		c := grid.Coord{X: i % u.Cols, Y: i / u.Cols}
+		if n == pzpr.Question {
+			return nil, fmt.Errorf("cell %s holds a question mark, which is not supported", c)
+		} else if n == pzpr.Empty {
+			continue
+		}
+		if err := b.addCross(c, n); err != nil {
+			return nil, err
+		}
+	}
+	b.Inited = true
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// URL returns the puzzle's clues as a puzz.link URL.
+func (b *Board) URL() string {
+	nums := make([]int, 0, b.W*b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if cross := b.CrossAt(c); cross != nil {
+			nums = append(nums, cross.Size)
+		} else {
+			nums = append(nums, pzpr.Empty)
+		}
+	}
+	u := pzpr.URL{Type: "kurodoko", Cols: b.W, Rows: b.H, Body: pzpr.EncodeNumber16(nums)}
+	return u.String()
+}
@@ -0,0 +1,99 @@
+package nonogram
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable painted(x,y) is true
+// when that cell is painted, and cells already known are unit clauses.
+//
+// Every run of every line has a variable for each cell it could start on,
+// exactly one of which is true. A run that starts on a cell paints the
+// cells it covers and puts the next run at least one cell past its end,
+// and a painted cell must be covered by some run of its row and some run
+// of its column. The formula grows with the number of cells times the
+// number of runs.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, painted := b.encode()
+	return b.SetFromModel(m, painted)
+}
+
+// encode builds the formula CNF returns, along with the painted variable
+// of every cell.
+func (b *Board) encode() (*cnf.Formula, [][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d", TypeName, b.W, b.H)}
+	painted := b.CellVars(f, "painted")
+	p := func(c grid.Coord) int {
+		return painted[c.Y][c.X]
+	}
+	b.eachLine(func(row bool, idx int) error {
+		cells := b.lineCells(row, idx)
+		runs := b.clues(row, idx)
+		n := len(cells)
+		// start[i][s] is the variable for run i starting on cell s, or 0
+		// where it cannot.
+		start := make([][]int, len(runs))
+		name := "rowstart"
+		if !row {
+			name = "colstart"
+		}
+		// Run i starts after the runs before it, with a cell between
+		// each, and leaves room for the runs after it.
+		lo, hi := 0, n+1
+		for _, r := range runs {
+			hi -= r + 1
+		}
+		for i, r := range runs {
+			hi += r + 1
+			start[i] = make([]int, n)
+			lits := make([]int, 0, hi-r-lo+1)
+			for s := lo; s <= hi-r-1; s++ {
+				start[i][s] = f.NewVar(fmt.Sprintf("%s(%d,%d,%d)", name, idx, i, s))
+				lits = append(lits, start[i][s])
+				for _, c := range cells[s : s+r] {
+					f.Add(-start[i][s], p(c))
+				}
+			}
+			f.ExactlyOne(lits)
+			lo += r + 1
+		}
+		for i := 0; i+1 < len(runs); i++ {
+			for s, v := range start[i] {
+				if v == 0 {
+					continue
+				}
+				next := []int{-v}
+				for t := s + runs[i] + 1; t < n; t++ {
+					if start[i+1][t] != 0 {
+						next = append(next, start[i+1][t])
+					}
+				}
+				f.Add(next...)
+			}
+		}
+		for j, c := range cells {
+			cover := []int{-p(c)}
+			for i, r := range runs {
+				for s := max(0, j-r+1); s <= j; s++ {
+					if start[i][s] != 0 {
+						cover = append(cover, start[i][s])
+					}
+				}
+			}
+			f.Add(cover...)
+		}
+		return nil
+	})
+	return f, painted
+}
@@ -0,0 +1,28 @@
+package nonogram_test
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/nonogram"
+)
+
+func ExampleBoardFromLines() {
+	b, err := nonogram.BoardFromLines([]string{"1,1", "3", "0", "---", "2", "1", "2"})
+	if err != nil {
+		panic(err)
+	}
+	if err := b.Solve(); err != nil {
+		panic(err)
+	}
+	solved, _ := b.IsSolved()
+	fmt.Println(solved)
+	fmt.Println(b)
+	// Output:
+	// true
+	// +---+
+	// |X·X| 1,1
+	// |XXX| 3
+	// |···| 0
+	// +---+
+	//  212
+}
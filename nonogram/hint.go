@@ -0,0 +1,35 @@
+package nonogram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Explain describes why step s made mark m, for board.HintBin.
+func (b *Board) Explain(s board.Step, m board.CellValue) string {
+	if len(s.Cells) == 2 {
+		row, idx := b.lineOf(s.Cells[0], s.Cells[1])
+		line, runs := lineName(row, idx), formatRuns(b.clues(row, idx))
+		painted := grid.Cell(m.Value) == grid.PAINTED
+		switch s.Rule {
+		case "SettleOverlaps":
+			if painted {
+				return fmt.Sprintf("a run of %s covers it whether the runs %s are pushed all the way left or all the way right", line, runs)
+			}
+			return fmt.Sprintf("no run of %s can reach it, with the runs %s pushed all the way left or all the way right", line, runs)
+		case "MarkCellsFromPerms", "SettleLines":
+			if painted {
+				return fmt.Sprintf("every placement of the runs %s that still fits %s paints it", runs, line)
+			}
+			return fmt.Sprintf("no placement of the runs %s that still fits %s paints it", runs, line)
+		}
+	}
+	cells := make([]string, 0, len(s.Cells))
+	for _, c := range s.Cells {
+		cells = append(cells, c.String())
+	}
+	return fmt.Sprintf("follows from %s at %s", s.Rule, strings.Join(cells, " "))
+}
@@ -0,0 +1,74 @@
+package nonogram
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// BoardFromDocument reads a puzzle from the runs of its rows and columns,
+// then fills in any given cells. Cells are taken as they are, without
+// running the rules.
+func BoardFromDocument(d *puzzle.Document) (*Board, error) {
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	if len(d.Rows) == 0 || len(d.Columns) == 0 {
+		return nil, fmt.Errorf("document must have the runs of its rows and columns")
+	}
+	rowClues := make([][]int, len(d.Rows))
+	colClues := make([][]int, len(d.Columns))
+	for i, section := range [][][]int{d.Rows, d.Columns} {
+		out, name, size := rowClues, "row", d.Width
+		if i == 1 {
+			out, name, size = colClues, "column", d.Height
+		}
+		for j, runs := range section {
+			need := len(runs) - 1
+			for _, r := range runs {
+				if r <= 0 {
+					return nil, fmt.Errorf("%s %d has run %d", name, j, r)
+				}
+				need += r
+			}
+			if need > size {
+				return nil, fmt.Errorf("%s %d has runs %s, which need %d cells; it has %d", name, j, formatRuns(runs), need, size)
+			}
+			out[j] = append([]int{}, runs...)
+		}
+	}
+	b := newBoard(rowClues, colClues)
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	if err := d.SetBinCells(&b.RectBinBoard); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Document returns the puzzle with its current cells.
+func (b *Board) Document() *puzzle.Document {
+	d := &puzzle.Document{
+		Type:    TypeName,
+		Width:   b.W,
+		Height:  b.H,
+		Author:  b.Meta.Author,
+		Source:  b.Meta.Source,
+		Rows:    copyClues(b.RowClues),
+		Columns: copyClues(b.ColClues),
+		Cells:   b.Cells(),
+	}
+	d.Solved, _ = b.IsSolved()
+	return d
+}
+
+// copyClues copies the runs of some lines.
+func copyClues(clues [][]int) [][]int {
+	out := make([][]int, len(clues))
+	for i, runs := range clues {
+		out[i] = append([]int{}, runs...)
+	}
+	return out
+}
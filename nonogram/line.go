@@ -0,0 +1,253 @@
+package nonogram
+
+import "github.com/bismuthsalamander/mutantcheckerboard/grid"
+
+// A line is one row or column of cells, and its runs are the lengths of
+// its blocks of painted cells, in order. The functions here work on one
+// line at a time, in time proportional to its length times its number of
+// runs.
+
+// fits holds, for a line and its runs, which runs can be placed in which
+// parts of the line without breaking a known cell. left[i][p] is true if
+// the first i runs fit in the first p cells, and right[i][p] if runs i and
+// up fit in the cells from p on; in both, no run may spill over into the
+// rest of the line, but cells left over at either end are clear.
+type fits struct {
+	line  []grid.Cell
+	runs  []int
+	left  [][]bool
+	right [][]bool
+	// clears[p] counts the clear cells before p, so that a run's cells
+	// can be checked at once.
+	clears []int
+}
+
+// newFits fills in the fits table of line and runs.
+func newFits(line []grid.Cell, runs []int) *fits {
+	n, k := len(line), len(runs)
+	f := &fits{line: line, runs: runs, left: make([][]bool, k+1), right: make([][]bool, k+1), clears: make([]int, n+1)}
+	for p, v := range line {
+		f.clears[p+1] = f.clears[p]
+		if v == grid.CLEAR {
+			f.clears[p+1]++
+		}
+	}
+	for i := range f.left {
+		f.left[i] = make([]bool, n+1)
+		f.right[i] = make([]bool, n+1)
+	}
+
+	f.left[0][0] = true
+	for i := 0; i <= k; i++ {
+		for p := 1; p <= n; p++ {
+			// Cell p-1 is left over, or run i-1 ends on it.
+			ok := line[p-1] != grid.PAINTED && f.left[i][p-1]
+			if !ok && i > 0 {
+				s := p - runs[i-1]
+				ok = s >= 0 && f.open(s, p) && ((s == 0 && i == 1) || (s > 0 && line[s-1] != grid.PAINTED && f.left[i-1][s-1]))
+			}
+			f.left[i][p] = ok
+		}
+	}
+
+	f.right[k][n] = true
+	for i := k; i >= 0; i-- {
+		for p := n - 1; p >= 0; p-- {
+			// Cell p is left over, or run i starts on it.
+			ok := line[p] != grid.PAINTED && f.right[i][p+1]
+			if !ok && i < k {
+				e := p + runs[i]
+				ok = e <= n && f.open(p, e) && ((e == n && i == k-1) || (e < n && line[e] != grid.PAINTED && f.right[i+1][e+1]))
+			}
+			f.right[i][p] = ok
+		}
+	}
+	return f
+}
+
+// open reports whether none of the cells from s up to e are clear.
+func (f *fits) open(s, e int) bool {
+	return f.clears[e]-f.clears[s] == 0
+}
+
+// ok reports whether the runs fit the line at all.
+func (f *fits) ok() bool {
+	return f.right[0][0]
+}
+
+// canStart reports whether run i can start at cell s in some placement of
+// all the runs that fits the line.
+func (f *fits) canStart(i, s int) bool {
+	e := s + f.runs[i]
+	return s >= 0 && e <= len(f.line) && f.open(s, e) && f.fitsBefore(i, s) && f.fitsAfter(i, e)
+}
+
+// fitsBefore reports whether the runs before run i fit the cells before
+// cell s, where run i starts.
+func (f *fits) fitsBefore(i, s int) bool {
+	return (s == 0 && i == 0) || (s > 0 && f.line[s-1] != grid.PAINTED && f.left[i][s-1])
+}
+
+// fitsAfter reports whether the runs after run i fit the cells after cell
+// e, just past where run i ends.
+func (f *fits) fitsAfter(i, e int) bool {
+	n := len(f.line)
+	return (e == n && i == len(f.runs)-1) || (e < n && f.line[e] != grid.PAINTED && f.right[i+1][e+1])
+}
+
+// settle returns, for every cell of the line, whether some placement of
+// the runs that fits the line paints it and whether some placement leaves
+// it clear. ok is false if no placement fits.
+func settle(line []grid.Cell, runs []int) (canPaint, canClear []bool, ok bool) {
+	f := newFits(line, runs)
+	if !f.ok() {
+		return nil, nil, false
+	}
+	n := len(line)
+	canPaint = make([]bool, n)
+	canClear = make([]bool, n)
+	// cover[p] counts the runs that can start at p and still reach the
+	// cells after it; a running sum turns it into painted cells.
+	cover := make([]int, n+1)
+	for i, r := range runs {
+		for s := 0; s+r <= n; s++ {
+			if f.canStart(i, s) {
+				cover[s]++
+				cover[s+r]--
+			}
+		}
+	}
+	sum := 0
+	for p := 0; p < n; p++ {
+		sum += cover[p]
+		canPaint[p] = sum > 0
+		if line[p] == grid.PAINTED {
+			continue
+		}
+		for i := 0; i <= len(runs); i++ {
+			if f.left[i][p] && f.right[i][p+1] {
+				canClear[p] = true
+				break
+			}
+		}
+	}
+	return canPaint, canClear, true
+}
+
+// extremes returns the start of each run in the leftmost and in the
+// rightmost placement of the runs that fits the line. ok is false if no
+// placement fits.
+func extremes(line []grid.Cell, runs []int) (first, last []int, ok bool) {
+	f := newFits(line, runs)
+	if !f.ok() {
+		return nil, nil, false
+	}
+	n, k := len(line), len(runs)
+	first = make([]int, k)
+	last = make([]int, k)
+	// Each run takes the first start after the runs already placed that
+	// leaves no painted cell behind it and room for the runs after it.
+	p := 0
+	for i, r := range runs {
+		s := p
+		for !(f.open(s, s+r) && f.fitsAfter(i, s+r)) {
+			s++
+		}
+		first[i] = s
+		p = s + r + 1
+	}
+	// And the same from the other end.
+	q := n
+	for i := k - 1; i >= 0; i-- {
+		r := runs[i]
+		s := q - r
+		for !(f.open(s, s+r) && f.fitsBefore(i, s)) {
+			s--
+		}
+		last[i] = s
+		q = s - 1
+	}
+	return first, last, true
+}
+
+// runsOf returns the runs of painted cells in line, treating unknown cells
+// as clear.
+func runsOf(line []grid.Cell) []int {
+	runs := make([]int, 0)
+	n := 0
+	for _, v := range line {
+		if v == grid.PAINTED {
+			n++
+		} else if n > 0 {
+			runs = append(runs, n)
+			n = 0
+		}
+	}
+	if n > 0 {
+		runs = append(runs, n)
+	}
+	return runs
+}
+
+// countPlacements returns the number of ways to place runs in a line of n
+// cells, or limit+1 if there are more than limit.
+func countPlacements(n int, runs []int, limit int) int {
+	used := len(runs) - 1
+	for _, r := range runs {
+		used += r
+	}
+	if len(runs) == 0 {
+		return 1
+	}
+	if used > n {
+		return 0
+	}
+	// Spread the n-used spare cells over the len(runs)+1 gaps: the
+	// binomial coefficient C(spare+k, k), built up so that it stops once
+	// it passes limit.
+	spare, k := n-used, len(runs)
+	ways := 1
+	for j := 1; j <= k; j++ {
+		ways = ways * (spare + j) / j
+		if ways > limit {
+			return limit + 1
+		}
+	}
+	return ways
+}
+
+// placements returns every way to place runs in a line of n cells, each as
+// the line's cells, in order from the leftmost placement.
+func placements(n int, runs []int) [][]grid.Cell {
+	out := make([][]grid.Cell, 0)
+	line := make([]grid.Cell, n)
+	var walk func(i, from int)
+	walk = func(i, from int) {
+		if i == len(runs) {
+			p := make([]grid.Cell, n)
+			for j, v := range line {
+				p[j] = v
+				if v != grid.PAINTED {
+					p[j] = grid.CLEAR
+				}
+			}
+			out = append(out, p)
+			return
+		}
+		rest := len(runs) - i - 1
+		for _, r := range runs[i+1:] {
+			rest += r
+		}
+		for s := from; s+runs[i]+rest <= n; s++ {
+			for p := s; p < s+runs[i]; p++ {
+				line[p] = grid.PAINTED
+			}
+			walk(i+1, s+runs[i]+1)
+			for p := s; p < s+runs[i]; p++ {
+				line[p] = grid.UNKNOWN
+			}
+		}
+	}
+	walk(0, 0)
+	return out
+}
@@ -0,0 +1,54 @@
+package nonogram
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place: a
+// missing separator, lines of runs that cannot be read, runs too long for
+// their line, and row and column runs that paint different numbers of
+// cells.
+func Lint(lines []string) []error {
+	rows, cols, colsAt, ok := board.SplitAtSeparator(lines)
+	if !ok {
+		return []error{fmt.Errorf("must have the row runs and the column runs, split by a line of dashes; have %d lines and no separator", len(lines))}
+	}
+	errs := make([]error, 0)
+	if len(rows) == 0 {
+		errs = append(errs, grid.LineErrorf(1, 0, "no row runs before the separator"))
+	}
+	if len(cols) == 0 {
+		errs = append(errs, grid.LineErrorf(colsAt, 0, "no column runs after the separator"))
+	}
+	rowClues, rowErrs := readClues(rows, 0)
+	colClues, colErrs := readClues(cols, colsAt)
+	errs = append(append(errs, rowErrs...), colErrs...)
+	if len(errs) > 0 {
+		return errs
+	}
+	painted := [2]int{}
+	for i, section := range [][][]int{rowClues, colClues} {
+		size, at, name := len(cols), 0, "row"
+		if i == 1 {
+			size, at, name = len(rows), colsAt, "column"
+		}
+		for y, runs := range section {
+			need := len(runs) - 1
+			for _, r := range runs {
+				need += r
+				painted[i] += r
+			}
+			if need > size {
+				errs = append(errs, grid.LineErrorf(at+y+1, 0, "runs %s need %d cells; a %s has %d", formatRuns(runs), need, name, size))
+			}
+		}
+	}
+	if painted[0] != painted[1] {
+		errs = append(errs, fmt.Errorf("row runs paint %d cells and column runs %d; they must match", painted[0], painted[1]))
+	}
+	return errs
+}
@@ -0,0 +1,575 @@
+// Package nonogram solves nonograms, also called paint by numbers. Every row
+// and column lists the lengths of its runs of painted cells, in order, with
+// at least one clear cell between two runs.
+//
+// As in towers, each line keeps the placements of its runs that are still
+// possible, and the lists are trimmed against the cells and the cells
+// filled in from the lists. Lines with too many placements to list are
+// left to a line solver that finds the same deductions without listing
+// them, in time proportional to the line's length times its number of
+// runs; see settle.
+package nonogram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// PermLimit is the most placements a line may have for its list to be
+// kept in RowPerms or ColPerms.
+const PermLimit = 1 << 12
+
+// Board is a nonogram. RowClues and ColClues hold the runs of each row, top
+// to bottom, and each column, left to right; an empty line has no runs.
+// Perms holds every placement of the runs of each line, rows first and then
+// columns, or nil for a line with more than PermLimit. None of them change
+// as the puzzle is solved, so clones share them. RowPerms and ColPerms hold
+// the indexes into Perms of the placements of each row and column that are
+// still possible, or nil where Perms is.
+type Board struct {
+	board.RectBinBoard
+	RowClues [][]int
+	ColClues [][]int
+	Perms    [][][]grid.Cell
+	RowPerms []*[]int
+	ColPerms []*[]int
+}
+
+// lineCells returns the cells of row or column idx, in order.
+func (b *Board) lineCells(row bool, idx int) []grid.Coord {
+	out := make([]grid.Coord, 0, max(b.W, b.H))
+	if row {
+		for c := (grid.Coord{X: 0, Y: idx}); b.IsValid(c); c.X++ {
+			out = append(out, c)
+		}
+	} else {
+		for c := (grid.Coord{X: idx, Y: 0}); b.IsValid(c); c.Y++ {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// line returns the current values of the cells of row or column idx.
+func (b *Board) line(row bool, idx int) []grid.Cell {
+	cells := b.lineCells(row, idx)
+	out := make([]grid.Cell, len(cells))
+	for i, c := range cells {
+		out[i] = b.Get(c)
+	}
+	return out
+}
+
+// clues returns the runs of row or column idx.
+func (b *Board) clues(row bool, idx int) []int {
+	if row {
+		return b.RowClues[idx]
+	}
+	return b.ColClues[idx]
+}
+
+// perms returns the list of placements of row or column idx that are still
+// possible, the placements themselves, and the list's slot in RowPerms or
+// ColPerms.
+func (b *Board) perms(row bool, idx int) (*[]int, [][]grid.Cell, []*[]int) {
+	if row {
+		return b.RowPerms[idx], b.Perms[idx], b.RowPerms
+	}
+	return b.ColPerms[idx], b.Perms[b.H+idx], b.ColPerms
+}
+
+// lineName names row or column idx for messages.
+func lineName(row bool, idx int) string {
+	if row {
+		return fmt.Sprintf("row %d", idx)
+	}
+	return fmt.Sprintf("column %d", idx)
+}
+
+// lineOf returns the line whose first and last cells are first and last,
+// as the steps of the line rules name them.
+func (b *Board) lineOf(first, last grid.Coord) (row bool, idx int) {
+	if first.Y == last.Y && (first.X != last.X || b.W == 1) {
+		return true, first.Y
+	}
+	return false, first.X
+}
+
+// eachLine calls fn for every row and then every column, stopping at the
+// first error.
+func (b *Board) eachLine(fn func(row bool, idx int) error) error {
+	for y := 0; y < b.H; y++ {
+		if err := fn(true, y); err != nil {
+			return err
+		}
+	}
+	for x := 0; x < b.W; x++ {
+		if err := fn(false, x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsSolved reports whether every cell is known and every line has its
+// runs. The error says what is still wrong.
+func (b *Board) IsSolved() (bool, error) {
+	res, coord := b.IsComplete()
+	if !res {
+		return false, fmt.Errorf("cell %s is unknown", coord)
+	}
+	err := b.eachLine(func(row bool, idx int) error {
+		have, want := runsOf(b.line(row, idx)), b.clues(row, idx)
+		if !sameRuns(have, want) {
+			return fmt.Errorf("%s has runs %s, not %s", lineName(row, idx), formatRuns(have), formatRuns(want))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sameRuns reports whether a and b list the same runs.
+func sameRuns(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatRuns writes runs as they appear in a text puzzle, with 0 for a
+// line without any.
+func formatRuns(runs []int) string {
+	if len(runs) == 0 {
+		return "0"
+	}
+	out := make([]string, len(runs))
+	for i, r := range runs {
+		out[i] = strconv.Itoa(r)
+	}
+	return strings.Join(out, ",")
+}
+
+// Validate returns an error if the board's current contents already break a
+// rule, even though some cells may still be unknown: a line whose runs no
+// longer fit its cells, or whose list of placements has run out. For a
+// complete board, Validate returns nil exactly when IsSolved returns true.
+func (b *Board) Validate() error {
+	return b.eachLine(func(row bool, idx int) error {
+		at := b.lineCells(row, idx)[0]
+		if list, _, _ := b.perms(row, idx); list != nil && len(*list) == 0 {
+			return board.Contradiction(at, "Validate", "%s has no placement of %s left", lineName(row, idx), formatRuns(b.clues(row, idx)))
+		}
+		if f := newFits(b.line(row, idx), b.clues(row, idx)); !f.ok() {
+			return board.Contradiction(at, "Validate", "runs %s no longer fit %s", formatRuns(b.clues(row, idx)), lineName(row, idx))
+		}
+		return nil
+	})
+}
+
+// Clone returns a copy of the board whose cells can be changed without
+// affecting the original. The clues and placements never change after
+// loading, so they are shared; the lists of placements still possible are
+// copied because the trimming rule replaces them.
+func (b *Board) Clone() *Board {
+	return &Board{
+		RectBinBoard: *b.RectBinBoard.Clone(),
+		RowClues:     b.RowClues,
+		ColClues:     b.ColClues,
+		Perms:        b.Perms,
+		RowPerms:     copyPermLists(b.RowPerms),
+		ColPerms:     copyPermLists(b.ColPerms),
+	}
+}
+
+// setPerms replaces lists[i], one of RowPerms or ColPerms, with perms and
+// records how to undo it. The old list is left untouched, so Clone and
+// Rollback never see a list that changed under them.
+func (b *Board) setPerms(lists []*[]int, i int, perms *[]int) {
+	old := lists[i]
+	b.OnRollback(func() { lists[i] = old })
+	lists[i] = perms
+}
+
+func copyPermLists(lists []*[]int) []*[]int {
+	out := make([]*[]int, len(lists))
+	for i, l := range lists {
+		if l == nil {
+			continue
+		}
+		tmp := append([]int(nil), (*l)...)
+		out[i] = &tmp
+	}
+	return out
+}
+
+// Mark writes v to c and runs the rules that follow from it.
+func (b *Board) Mark(c grid.Coord, v grid.Cell) (bool, error) {
+	res, err := b.Set(c, v)
+	if !res {
+		return res, err
+	}
+	b.SetDirty()
+	return res, b.PostMark(c, v)
+}
+
+func (b *Board) MarkPainted(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.PAINTED)
+}
+
+func (b *Board) MarkClear(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.CLEAR)
+}
+
+// PostMark runs the rules that follow from v having just been written to c.
+// A single cell settles nothing by itself in a nonogram; the line rules
+// pick it up on their next pass.
+func (b *Board) PostMark(c grid.Coord, v grid.Cell) error {
+	return nil
+}
+
+// String draws the board in a frame, with X for painted cells and · for
+// clear ones. Each row's runs follow it, and the columns' runs are written
+// down under the frame, one run per line.
+func (b *Board) String() string {
+	out := "+" + strings.Repeat("-", b.W) + "+\n"
+	for y := 0; y < b.H; y++ {
+		out += "|"
+		for x := 0; x < b.W; x++ {
+			out += string(b.Get(grid.Coord{X: x, Y: y}).Ch())
+		}
+		out += "| " + formatRuns(b.RowClues[y]) + "\n"
+	}
+	out += "+" + strings.Repeat("-", b.W) + "+"
+	// Runs above 9 need more than one character; they are written as
+	// letters, as by grid.IntToCh, to keep the columns lined up.
+	depth := 0
+	for _, runs := range b.ColClues {
+		depth = max(depth, len(runs), 1)
+	}
+	for i := 0; i < depth; i++ {
+		out += "\n "
+		for _, runs := range b.ColClues {
+			switch {
+			case len(runs) == 0 && i == 0:
+				out += "0"
+			case i < len(runs):
+				out += string(grid.IntToCh(runs[i]))
+			default:
+				out += " "
+			}
+		}
+	}
+	return out
+}
+
+// SettleOverlaps pushes each line's runs as far left and as far right as
+// they go. Cells covered by a run in both placements are painted, and cells
+// that no run can reach between them are clear. This is the overlap method
+// solvers use first on large puzzles; it costs little per line.
+func (b *Board) SettleOverlaps() error {
+	return b.eachLine(func(row bool, idx int) error {
+		cells := b.lineCells(row, idx)
+		runs := b.clues(row, idx)
+		first, last, ok := extremes(b.line(row, idx), runs)
+		if !ok {
+			return board.Contradiction(cells[0], "SettleOverlaps", "runs %s do not fit %s", formatRuns(runs), lineName(row, idx))
+		}
+		reach := make([]bool, len(cells))
+		for i, r := range runs {
+			for p := first[i]; p < last[i]+r; p++ {
+				reach[p] = true
+			}
+			for p := last[i]; p < first[i]+r; p++ {
+				if err := b.settleCell(cells, p, grid.PAINTED, "SettleOverlaps"); err != nil {
+					return err
+				}
+			}
+		}
+		for p, ok := range reach {
+			if !ok {
+				if err := b.settleCell(cells, p, grid.CLEAR, "SettleOverlaps"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// settleCell marks cell p of a line with v, if it is unknown, as a step of
+// rule on that line.
+func (b *Board) settleCell(cells []grid.Coord, p int, v grid.Cell, rule string) error {
+	if !b.IsUnknown(cells[p]) {
+		return nil
+	}
+	b.BeginStep(rule, cells[0], cells[len(cells)-1])
+	_, err := b.Mark(cells[p], v)
+	b.EndStep()
+	return err
+}
+
+// TrimPermsFromCells removes the entries in RowPerms and ColPerms that
+// disagree with a known cell.
+func (b *Board) TrimPermsFromCells() error {
+	return b.eachLine(func(row bool, idx int) error {
+		list, all, lists := b.perms(row, idx)
+		if list == nil {
+			return nil
+		}
+		line := b.line(row, idx)
+		newPerms := make([]int, 0, len(*list))
+		for _, pi := range *list {
+			isPermOk := true
+			for p, v := range line {
+				if v != grid.UNKNOWN && all[pi][p] != v {
+					isPermOk = false
+					break
+				}
+			}
+			if isPermOk {
+				newPerms = append(newPerms, pi)
+			}
+		}
+		if len(newPerms) == len(*list) {
+			return nil
+		}
+		cells := b.lineCells(row, idx)
+		b.BeginStep("TrimPermsFromCells", cells[0], cells[len(cells)-1])
+		b.setPerms(lists, idx, &newPerms)
+		b.StepPerms(row, idx, len(newPerms))
+		b.EndStep()
+		b.SetDirty()
+		if len(newPerms) == 0 {
+			return board.Contradiction(cells[0], "TrimPermsFromCells", "no placement of %s fits %s", formatRuns(b.clues(row, idx)), lineName(row, idx))
+		}
+		return nil
+	})
+}
+
+// MarkCellsFromPerms marks the cells that every placement still in RowPerms
+// or ColPerms agrees on.
+func (b *Board) MarkCellsFromPerms() error {
+	return b.eachLine(func(row bool, idx int) error {
+		list, all, _ := b.perms(row, idx)
+		if list == nil || len(*list) == 0 {
+			return nil
+		}
+		cells := b.lineCells(row, idx)
+		for p := range cells {
+			v := all[(*list)[0]][p]
+			agree := true
+			for _, pi := range *list {
+				if all[pi][p] != v {
+					agree = false
+					break
+				}
+			}
+			if agree {
+				if err := b.settleCell(cells, p, v, "MarkCellsFromPerms"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// SettleLines settles the lines with too many placements to list: a cell
+// that every placement of the line's runs paints is painted, and one that
+// none paints is clear. This finds what listing the placements would,
+// without listing them; see settle.
+func (b *Board) SettleLines() error {
+	return b.eachLine(func(row bool, idx int) error {
+		if list, _, _ := b.perms(row, idx); list != nil {
+			return nil
+		}
+		cells := b.lineCells(row, idx)
+		canPaint, canClear, ok := settle(b.line(row, idx), b.clues(row, idx))
+		if !ok {
+			return board.Contradiction(cells[0], "SettleLines", "runs %s do not fit %s", formatRuns(b.clues(row, idx)), lineName(row, idx))
+		}
+		for p := range cells {
+			var err error
+			if !canClear[p] {
+				err = b.settleCell(cells, p, grid.PAINTED, "SettleLines")
+			} else if !canPaint[p] {
+				err = b.settleCell(cells, p, grid.CLEAR, "SettleLines")
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Solve applies the rules until none of them makes progress. Returns a
+// ContradictionError if the board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	b.SetDirty()
+	for b.IsDirty() {
+		b.ClearDirty()
+		for _, rule := range []func() error{
+			b.SettleOverlaps,
+			b.TrimPermsFromCells,
+			b.MarkCellsFromPerms,
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			if err := rule(); err != nil {
+				return err
+			}
+		}
+		if b.IsDirty() {
+			continue
+		}
+		if err := board.Stopped(ctx); err != nil {
+			return err
+		}
+		if err := b.SettleLines(); err != nil {
+			return err
+		}
+	}
+	return b.Validate()
+}
+
+// BoardFromLines reads a puzzle as the runs of each row, one row per line,
+// then a line of dashes, then the runs of each column. Runs are separated
+// by commas or spaces, and a line without runs is written 0:
+//
+//	1,1
+//	3
+//	0
+//	---
+//	2
+//	1
+//	2
+//
+// Every problem Lint finds is returned, joined with errors.Join.
+func BoardFromLines(input []string) (*Board, error) {
+	if errs := Lint(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	rows, cols, colsAt, _ := board.SplitAtSeparator(input)
+	rowClues, _ := readClues(rows, 0)
+	colClues, _ := readClues(cols, colsAt)
+	return newBoard(rowClues, colClues), nil
+}
+
+// Lines returns the puzzle's runs in the text format BoardFromLines reads.
+func (b *Board) Lines() []string {
+	lines := make([]string, 0, b.H+b.W+1)
+	for _, runs := range b.RowClues {
+		lines = append(lines, formatRuns(runs))
+	}
+	lines = append(lines, "---")
+	for _, runs := range b.ColClues {
+		lines = append(lines, formatRuns(runs))
+	}
+	return lines
+}
+
+// readClues reads the runs of a section of a text puzzle whose first line
+// is line at+1 of the file, returning one grid.LineError for every line it
+// cannot read.
+func readClues(lines []string, at int) ([][]int, []error) {
+	out := make([][]int, len(lines))
+	errs := make([]error, 0)
+	for y, l := range lines {
+		runs, col, err := parseRuns(l)
+		if err != nil {
+			errs = append(errs, grid.LineErrorf(at+y+1, col, "%s", err))
+			continue
+		}
+		out[y] = runs
+	}
+	return out, errs
+}
+
+// parseRuns reads one line of runs. On error it also returns the column the
+// problem starts at, counted from 1, or 0 for the whole line.
+func parseRuns(l string) ([]int, int, error) {
+	runs := make([]int, 0)
+	zeroAt := 0
+	for i := 0; i < len(l); {
+		if l[i] == ',' || l[i] == ' ' || l[i] == '\t' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(l) && l[j] != ',' && l[j] != ' ' && l[j] != '\t' {
+			j++
+		}
+		n, err := strconv.Atoi(l[i:j])
+		if err != nil || n < 0 {
+			return nil, i + 1, fmt.Errorf("run %q is not a length", l[i:j])
+		}
+		if n == 0 {
+			zeroAt = i + 1
+		} else {
+			runs = append(runs, n)
+		}
+		i = j
+	}
+	if zeroAt > 0 && len(runs) > 0 {
+		return nil, zeroAt, fmt.Errorf("0 marks a line without runs; it cannot stand with others")
+	}
+	if zeroAt == 0 && len(runs) == 0 {
+		return nil, 0, fmt.Errorf("line has no runs; write 0 for a line without any")
+	}
+	return runs, 0, nil
+}
+
+// newBoard returns an unsolved board with the given runs for each row and
+// column, listing the placements of every line with at most PermLimit.
+func newBoard(rowClues, colClues [][]int) *Board {
+	b := &Board{
+		RectBinBoard: *board.NewRectBinBoard(len(colClues), len(rowClues)),
+		RowClues:     rowClues,
+		ColClues:     colClues,
+		Perms:        make([][][]grid.Cell, len(rowClues)+len(colClues)),
+		RowPerms:     make([]*[]int, len(rowClues)),
+		ColPerms:     make([]*[]int, len(colClues)),
+	}
+	b.eachLine(func(row bool, idx int) error {
+		n, lists, slot := b.W, b.RowPerms, idx
+		if !row {
+			n, lists, slot = b.H, b.ColPerms, b.H+idx
+		}
+		runs := b.clues(row, idx)
+		if countPlacements(n, runs, PermLimit) > PermLimit {
+			return nil
+		}
+		b.Perms[slot] = placements(n, runs)
+		list := make([]int, len(b.Perms[slot]))
+		for i := range list {
+			list[i] = i
+		}
+		lists[idx] = &list
+		return nil
+	})
+	b.Inited = true
+	return b
+}
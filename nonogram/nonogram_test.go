@@ -0,0 +1,161 @@
+package nonogram
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+func TestParseRuns(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		want []int
+		col  int
+	}{
+		{"0", []int{}, 0},
+		{"3", []int{3}, 0},
+		{"1,1", []int{1, 1}, 0},
+		{"2 3", []int{2, 3}, 0},
+		{" 1, 2 ", []int{1, 2}, 0},
+		{"", nil, 0},
+		{"0,1", nil, 1},
+		{"1,0", nil, 3},
+		{"1,x", nil, 3},
+		{"-1", nil, 1},
+	} {
+		runs, col, err := parseRuns(tt.line)
+		if tt.want == nil {
+			if err == nil || col != tt.col {
+				t.Errorf("parseRuns(%q) = %v, %d, %v; want an error at column %d", tt.line, runs, col, err, tt.col)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(runs, tt.want) {
+			t.Errorf("parseRuns(%q) = %v, %v; want %v", tt.line, runs, err, tt.want)
+		}
+	}
+}
+
+func TestBoardFromLinesErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		lines     []string
+		line, col int
+	}{
+		{"run longer than its row", []string{"5", "0", "---", "1", "1", "1", "1"}, 1, 0},
+		{"runs longer than their column", []string{"1", "1", "---", "1,1", "0"}, 4, 0},
+		{"line without runs", []string{"1", " ", "---", "1", "0"}, 2, 0},
+		{"zero among runs", []string{"1,0", "---", "1", "0"}, 1, 3},
+		{"no column runs", []string{"1", "---"}, 2, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BoardFromLines(tt.lines)
+			var le *grid.LineError
+			if !errors.As(err, &le) || le.Line != tt.line || le.Col != tt.col {
+				t.Errorf("BoardFromLines(%q) = %v; want an error at line %d, column %d", tt.lines, err, tt.line, tt.col)
+			}
+		})
+	}
+
+	for _, lines := range [][]string{
+		{"1", "1"},
+		{"2", "0", "---", "1", "0"},
+	} {
+		if _, err := BoardFromLines(lines); err == nil {
+			t.Errorf("BoardFromLines(%q) found nothing wrong", lines)
+		}
+	}
+}
+
+// TestSettle checks the cells a line's runs decide on their own, where
+// every placement of the runs agrees.
+func TestSettle(t *testing.T) {
+	const U, P, C = grid.UNKNOWN, grid.PAINTED, grid.CLEAR
+	for _, tt := range []struct {
+		name string
+		line []grid.Cell
+		runs []int
+		want []grid.Cell
+	}{
+		{"no runs", []grid.Cell{U, U, U}, []int{}, []grid.Cell{C, C, C}},
+		{"run fills the line", []grid.Cell{U, U, U, U}, []int{4}, []grid.Cell{P, P, P, P}},
+		{"runs fill the line", []grid.Cell{U, U, U, U, U}, []int{1, 3}, []grid.Cell{P, C, P, P, P}},
+		{"overlap", []grid.Cell{U, U, U, U}, []int{3}, []grid.Cell{U, P, P, U}},
+		{"nothing in common", []grid.Cell{U, U, U, U}, []int{1}, []grid.Cell{U, U, U, U}},
+		{"painted cell", []grid.Cell{U, U, P, U, U, U}, []int{2}, []grid.Cell{C, U, P, U, C, C}},
+		{"clear cell", []grid.Cell{U, C, U, U, U}, []int{2}, []grid.Cell{C, C, U, P, U}},
+		{"two runs split by a clear cell", []grid.Cell{U, U, C, U, U}, []int{2, 2}, []grid.Cell{P, P, C, P, P}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			canPaint, canClear, ok := settle(tt.line, tt.runs)
+			if !ok {
+				t.Fatal("settle found no placement")
+			}
+			got := make([]grid.Cell, len(tt.line))
+			for p := range got {
+				switch {
+				case canPaint[p] && !canClear[p]:
+					got[p] = P
+				case canClear[p] && !canPaint[p]:
+					got[p] = C
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("settle settles %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettleNoFit(t *testing.T) {
+	const U, P, C = grid.UNKNOWN, grid.PAINTED, grid.CLEAR
+	for _, tt := range []struct {
+		name string
+		line []grid.Cell
+		runs []int
+	}{
+		{"run too long", []grid.Cell{U, U, U}, []int{4}},
+		{"runs too long", []grid.Cell{U, U, U}, []int{2, 1}},
+		{"painted cell without runs", []grid.Cell{U, P, U}, []int{}},
+		{"run cut by a clear cell", []grid.Cell{U, C, U}, []int{2}},
+		{"run too long for its paint", []grid.Cell{P, P, P, U}, []int{2}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, ok := settle(tt.line, tt.runs); ok {
+				t.Errorf("settle found a placement of %v in %v", tt.runs, tt.line)
+			}
+			if _, _, ok := extremes(tt.line, tt.runs); ok {
+				t.Errorf("extremes found a placement of %v in %v", tt.runs, tt.line)
+			}
+		})
+	}
+}
+
+func TestCountPlacements(t *testing.T) {
+	for _, tt := range []struct {
+		n     int
+		runs  []int
+		limit int
+		want  int
+	}{
+		{4, []int{}, 10, 1},
+		{4, []int{4}, 10, 1},
+		{4, []int{5}, 10, 0},
+		{4, []int{2, 2}, 10, 0},
+		{4, []int{1}, 10, 4},
+		{5, []int{1, 1}, 10, 6},
+		{10, []int{1}, 3, 4},
+	} {
+		got := countPlacements(tt.n, tt.runs, tt.limit)
+		if got != tt.want {
+			t.Errorf("countPlacements(%d, %v, %d) = %d; want %d", tt.n, tt.runs, tt.limit, got, tt.want)
+		}
+		if got <= tt.limit {
+			if all := placements(tt.n, tt.runs); len(all) != got {
+				t.Errorf("placements(%d, %v) lists %d; countPlacements says %d", tt.n, tt.runs, len(all), got)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package nonogram
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// TypeName is the name the puzzle type is registered under.
+const TypeName = "nonogram"
+
+func init() {
+	puzzle.Register(puzzle.Type{
+		Name:        TypeName,
+		Description: "nonogram: paint the runs listed for each row and column, in order",
+		New:         func() puzzle.Puzzle { return &puzzleAdapter{} },
+		Detect:      looksLike,
+	})
+}
+
+// puzzleAdapter adapts Board to the puzzle.Puzzle interface.
+type puzzleAdapter struct {
+	*Board
+}
+
+// Parse reads a puzzle in the text format of BoardFromLines, after an
+// optional puzzle.Header.
+func (p *puzzleAdapter) Parse(lines []string) (err error) {
+	p.Board, err = puzzle.ParseText(TypeName, lines, BoardFromLines)
+	return err
+}
+
+func (p *puzzleAdapter) ParseDocument(d *puzzle.Document) (err error) {
+	p.Board, err = BoardFromDocument(d)
+	return err
+}
+
+func (p *puzzleAdapter) Clone() puzzle.Puzzle {
+	return &puzzleAdapter{p.Board.Clone()}
+}
+
+func (p *puzzleAdapter) SearchContext(ctx context.Context) (bool, error) {
+	return board.SearchBin(ctx, p.Board)
+}
+
+func (p *puzzleAdapter) CountSolutionsContext(ctx context.Context, limit int) (int, []puzzle.Puzzle, error) {
+	n, witnesses, err := board.CountBin(ctx, p.Board, limit)
+	return n, puzzle.ToPuzzles(witnesses, func(b *Board) puzzle.Puzzle { return &puzzleAdapter{b} }), err
+}
+
+func (p *puzzleAdapter) Hint() (*board.Hint, error) {
+	return board.HintBin(p.Board)
+}
+
+func (p *puzzleAdapter) RateContext(ctx context.Context) (*board.Rating, error) {
+	return board.RateBin(ctx, p.Board)
+}
+
+// Lint lists the problems in a text puzzle after an optional
+// puzzle.Header; see the package function Lint.
+func (p *puzzleAdapter) Lint(lines []string) []error {
+	return puzzle.LintText(lines, Lint)
+}
+
+// looksLike reports whether lines could be a nonogram: lines of runs
+// split by a line of dashes, with a comma somewhere, since a ripple effect
+// file of digits can otherwise look the same.
+func looksLike(lines []string) bool {
+	rows, cols, _, ok := board.SplitAtSeparator(lines)
+	if !ok || len(rows) == 0 || len(cols) == 0 {
+		return false
+	}
+	comma := false
+	for _, l := range append(append([]string{}, rows...), cols...) {
+		if _, _, err := parseRuns(l); err != nil {
+			return false
+		}
+		comma = comma || strings.Contains(l, ",")
+	}
+	return comma
+}
@@ -0,0 +1,13 @@
+package nonogram
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the nonogram rules in increasing order of difficulty.
+func (b *Board) Techniques() []board.Technique {
+	return []board.Technique{
+		board.DirtyTechnique(&b.RectBoard, "SettleOverlaps", 1, b.SettleOverlaps),
+		board.DirtyTechnique(&b.RectBoard, "TrimPermsFromCells", 2, b.TrimPermsFromCells),
+		board.DirtyTechnique(&b.RectBoard, "MarkCellsFromPerms", 2, b.MarkCellsFromPerms),
+		board.DirtyTechnique(&b.RectBoard, "SettleLines", 3, b.SettleLines),
+	}
+}
@@ -0,0 +1,30 @@
+package nonogram
+
+import "github.com/bismuthsalamander/mutantcheckerboard/render"
+
+// Scene describes the board for the renderers: each cell's shade, with the
+// runs of the columns above the grid and of the rows to its left. A line
+// without runs shows a 0.
+func (b *Board) Scene() *render.Scene {
+	s := render.NewScene(b.W, b.H)
+	s.Meta = b.Meta
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		s.At(c).Shade = b.Get(c)
+	}
+	s.TopRuns = sceneRuns(b.ColClues)
+	s.LeftRuns = sceneRuns(b.RowClues)
+	return s
+}
+
+// sceneRuns copies the runs of some lines for a Scene, with 0 for a line
+// without any.
+func sceneRuns(clues [][]int) [][]int {
+	out := make([][]int, len(clues))
+	for i, runs := range clues {
+		out[i] = append([]int{}, runs...)
+		if len(runs) == 0 {
+			out[i] = []int{0}
+		}
+	}
+	return out
+}
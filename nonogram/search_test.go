@@ -0,0 +1,19 @@
+package nonogram
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckBin(t, BoardFromLines, []boardtest.Case{
+		{Name: "unique", Lines: []string{"2", "1,1", "4", "1", "---", "2", "1,1", "3", "2"}},
+		{Name: "frame", Lines: []string{"4", "0", "0", "4", "---", "1,1", "1,1", "1,1", "1,1"}},
+		{Name: "checkers", Lines: []string{"1,1", "1,1", "1,1", "1,1", "---", "2", "2", "2", "2"}},
+		{Name: "permutations", Lines: []string{"1", "1", "1", "1", "---", "1", "1", "1", "1"}},
+		{Name: "empty", Lines: []string{"0", "0", "0", "---", "0", "0", "0", "0"}},
+		{Name: "full", Lines: []string{"4", "4", "4", "---", "3", "3", "3", "3"}},
+		{Name: "no solution", Lines: []string{"2", "0", "0", "2", "---", "1,1", "0", "0", "1,1"}},
+	})
+}
@@ -0,0 +1,168 @@
+package nurikabe
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable painted(x,y) is true
+// when that cell is painted, and cells already known are unit clauses.
+//
+// Each number i has a variable island(i,x,y) for every cell closer to it
+// than its size. A clear cell belongs to exactly one island, a clear
+// neighbor of an island cell belongs to the same island, and island i
+// holds exactly its number of cells, all reachable from the numbered cell
+// within the island. No 2x2 block is all painted, and the painted cells
+// connect by bounded reachability from the first of them in reading
+// order, as in hitori. The formula therefore grows with the square of the
+// number of cells.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, painted := b.encode()
+	return b.SetFromModel(m, painted)
+}
+
+// encode builds the formula CNF returns, along with the painted variable
+// of every cell.
+func (b *Board) encode() (*cnf.Formula, [][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d", TypeName, b.W, b.H)}
+	painted := b.CellVars(f, "painted")
+	p := func(c grid.Coord) int {
+		return painted[c.Y][c.X]
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if r, d := c.Plus(grid.RIGHT), c.Plus(grid.DOWN); b.IsValid(r) && b.IsValid(d) {
+			dr := d.Plus(grid.RIGHT)
+			f.Add(-p(c), -p(r), -p(d), -p(dr))
+		}
+	}
+
+	// island[i] maps each cell island i can reach to its variable.
+	island := make([]map[grid.Coord]int, len(b.AllClues))
+	inAny := make(map[grid.Coord][]int)
+	total := 0
+	for i, root := range b.AllClues {
+		n := b.ClueAt(root)
+		total += n
+		island[i] = make(map[grid.Coord]int)
+		for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+			if c.MHDist(root) >= n || (c != root && b.ClueAt(c) != 0) {
+				continue
+			}
+			a := f.NewVar(fmt.Sprintf("island(%d,%d,%d)", i, c.X, c.Y))
+			island[i][c] = a
+			inAny[c] = append(inAny[c], a)
+			f.Add(-a, -p(c))
+		}
+		f.Add(island[i][root])
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		f.Add(append([]int{p(c)}, inAny[c]...)...)
+		f.AtMostOne(inAny[c])
+	}
+	for i, root := range b.AllClues {
+		n := b.ClueAt(root)
+		lits := make([]int, 0, len(island[i]))
+		for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+			a, ok := island[i][c]
+			if !ok {
+				continue
+			}
+			lits = append(lits, a)
+			for _, dir := range grid.DIRECTIONS {
+				nb := c.Plus(dir)
+				if !b.IsValid(nb) {
+					continue
+				}
+				if an, ok := island[i][nb]; ok {
+					f.Add(-a, p(nb), an)
+				} else {
+					f.Add(-a, p(nb))
+				}
+			}
+		}
+		f.Exactly(lits, n)
+
+		// Reachability within the island from its numbered cell.
+		reach := make(map[grid.Coord]int)
+		reach[root] = f.True()
+		for k := 1; k < n; k++ {
+			next := make(map[grid.Coord]int)
+			for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+				a, ok := island[i][c]
+				if !ok || c.MHDist(root) > k {
+					continue
+				}
+				z := f.NewVar("")
+				f.Add(-z, a)
+				from := []int{-z}
+				if r, ok := reach[c]; ok {
+					from = append(from, r)
+				}
+				for _, dir := range grid.DIRECTIONS {
+					if r, ok := reach[c.Plus(dir)]; ok {
+						from = append(from, r)
+					}
+				}
+				f.Add(from...)
+				next[c] = z
+			}
+			reach = next
+		}
+		for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+			a, ok := island[i][c]
+			if !ok {
+				continue
+			}
+			if r, ok := reach[c]; ok {
+				f.Add(-a, r)
+			} else {
+				f.Add(-a)
+			}
+		}
+	}
+
+	// The sea starts at the first painted cell: some(x,y) says a cell at
+	// or before (x,y) in reading order is painted.
+	reach := grid.MakeNumGrid(b.W, b.H)
+	before := -f.True()
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		z := f.NewVar("")
+		f.Add(-z, p(c))
+		f.Add(-z, -before)
+		reach[c.Y][c.X] = z
+		some := f.NewVar(fmt.Sprintf("some(%d,%d)", c.X, c.Y))
+		f.Add(-p(c), some)
+		f.Add(-before, some)
+		before = some
+	}
+	for k := 1; k < b.W*b.H-total; k++ {
+		next := grid.MakeNumGrid(b.W, b.H)
+		for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+			z := f.NewVar("")
+			f.Add(-z, p(c))
+			from := []int{-z, reach[c.Y][c.X]}
+			for _, dir := range grid.DIRECTIONS {
+				if n := c.Plus(dir); b.IsValid(n) {
+					from = append(from, reach[n.Y][n.X])
+				}
+			}
+			f.Add(from...)
+			next[c.Y][c.X] = z
+		}
+		reach = next
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		f.Add(-p(c), reach[c.Y][c.X])
+	}
+	return f, painted
+}
@@ -0,0 +1,40 @@
+package nurikabe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Explain describes why step s made mark m, for board.HintBin.
+func (b *Board) Explain(s board.Step, m board.CellValue) string {
+	switch s.Rule {
+	case "AvoidPools":
+		return fmt.Sprintf("the other three cells of the 2x2 block at %s are painted", s.Cells[0])
+	case "FinishIslands":
+		return fmt.Sprintf("it borders the island of the %d at %s, which is complete", b.ClueAt(s.Cells[0]), s.Cells[0])
+	case "SeparateIslands":
+		return fmt.Sprintf("it would join the islands of %s and %s", s.Cells[0], s.Cells[1])
+	case "ExtendIslands":
+		if n := b.ClueAt(s.Cells[0]); n != 0 {
+			return fmt.Sprintf("it is the only way for the island of the %d at %s to grow", n, s.Cells[0])
+		}
+		return fmt.Sprintf("it is the only way for the clear cells at %s to reach a number", s.Cells[0])
+	case "PaintUnreachable":
+		return "no island can reach it"
+	case "PaintMiniDominators":
+		return fmt.Sprintf("it is the only liberty of painted cell %s", s.Cells[0])
+	case "PaintAllDominators":
+		if grid.Cell(m.Value) == grid.CLEAR {
+			return fmt.Sprintf("painting it would leave it cut off from painted cell %s", s.Cells[0])
+		}
+		return fmt.Sprintf("clearing it would cut %s off from painted cell %s", s.Cells[1], s.Cells[0])
+	}
+	cells := make([]string, 0, len(s.Cells))
+	for _, c := range s.Cells {
+		cells = append(cells, c.String())
+	}
+	return fmt.Sprintf("follows from %s at %s", s.Rule, strings.Join(cells, " "))
+}
@@ -0,0 +1,51 @@
+package nurikabe
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// BoardFromDocument reads a puzzle from its numbers, then fills in any
+// given cells. Cells are taken as they are, without running the rules.
+func BoardFromDocument(d *puzzle.Document) (*Board, error) {
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	clues := grid.MakeNumGrid(d.Width, d.Height)
+	for y, row := range d.Numbers {
+		for x, n := range row {
+			if n < 0 {
+				return nil, fmt.Errorf("cell (%d,%d) has number %d", x, y, n)
+			}
+		}
+		copy(clues[y], row)
+	}
+	b, err := newBoard(clues)
+	if err != nil {
+		return nil, err
+	}
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	if err := d.SetBinCells(&b.RectBinBoard); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Document returns the puzzle with its current cells.
+func (b *Board) Document() *puzzle.Document {
+	d := &puzzle.Document{
+		Type:    TypeName,
+		Width:   b.W,
+		Height:  b.H,
+		Author:  b.Meta.Author,
+		Source:  b.Meta.Source,
+		Numbers: grid.CopyNumGrid(b.Clues),
+		Cells:   b.Cells(),
+	}
+	d.Solved, _ = b.IsSolved()
+	return d
+}
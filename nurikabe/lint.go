@@ -0,0 +1,71 @@
+package nurikabe
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place:
+// rows of a different width (see grid.Width), numbers of 0, two numbers
+// side by side (which would share an island), numbers whose islands cannot
+// fit on the board, and a puzzle without numbers. A puzz.link URL is left
+// to BoardFromURL.
+func Lint(lines []string) []error {
+	if len(lines) == 0 {
+		return []error{fmt.Errorf("puzzle is empty")}
+	}
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return nil
+	}
+	nums, err := grid.LinesToNumGrid(lines)
+	if err != nil {
+		return []error{err}
+	}
+	w, errs := grid.Width(lines, nums)
+	h := len(nums)
+	clueAt := func(c grid.Coord) int {
+		if c.Y < 0 || c.Y >= h || c.X < 0 || c.X >= min(w, len(nums[c.Y])) {
+			return 0
+		}
+		return nums[c.Y][c.X]
+	}
+	tokens := grid.IsTokenGrid(lines)
+	isZero := func(x, y int) bool {
+		if tokens {
+			return grid.SplitTokens(lines[y])[x] == "0"
+		}
+		return lines[y][x] == '0'
+	}
+	total, clues := 0, 0
+	for y, row := range nums {
+		for x := range row {
+			c := grid.Coord{X: x, Y: y}
+			v := clueAt(c)
+			if v == 0 {
+				if x < w && isZero(x, y) {
+					errs = append(errs, grid.LineErrorf(y+1, x+1, "number 0; an island holds at least its numbered cell"))
+				}
+				continue
+			}
+			total += v
+			clues++
+			if v > w*h {
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "island of %d cannot fit on a %dx%d board", v, w, h))
+			}
+			for _, dir := range []grid.Delta{grid.LEFT, grid.UP} {
+				if n := c.Plus(dir); clueAt(n) != 0 {
+					errs = append(errs, grid.LineErrorf(y+1, x+1, "number next to the number at line %d, column %d would share its island", n.Y+1, n.X+1))
+				}
+			}
+		}
+	}
+	if clues == 0 {
+		errs = append(errs, fmt.Errorf("puzzle has no numbers; the sea would fill the board with 2x2 pools"))
+	} else if total > w*h {
+		errs = append(errs, fmt.Errorf("islands add up to %d cells; the board has %d", total, w*h))
+	}
+	return errs
+}
@@ -0,0 +1,667 @@
+// Package nurikabe solves nurikabe. Every number is the size of an island
+// of clear cells holding exactly that number; islands do not touch, and
+// every clear cell belongs to one. The painted cells are the sea: they must
+// be connected, and no 2x2 block may be all sea.
+//
+// The sea is connected the way kuromasu's clear cells are, so its rules are
+// kuromasu's turned around, built on board.RectBinBoard.Dominators over the
+// cells that are not clear.
+package nurikabe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+	"github.com/bismuthsalamander/mutantcheckerboard/set"
+)
+
+// Board is a nurikabe puzzle. Clues holds the number in each cell, 0 for
+// none, and AllClues the numbered cells in reading order. Neither changes
+// as the puzzle is solved, so clones share them.
+type Board struct {
+	board.RectBinBoard
+	Clues    [][]int
+	AllClues []grid.Coord
+}
+
+// ClueAt returns the number in c, or 0 if c has none.
+func (b *Board) ClueAt(c grid.Coord) int {
+	return b.Clues[c.Y][c.X]
+}
+
+// isClear passes only clear cells, for board.RectBinBoard.Flood.
+func isClear(v grid.Cell) bool {
+	return v == grid.CLEAR
+}
+
+// Island returns the clear cells connected to c, which must be clear, and
+// the numbered cells among them.
+func (b *Board) Island(c grid.Coord) (*set.Set[grid.Coord], []grid.Coord) {
+	cells := b.Flood(c, isClear)
+	clues := make([]grid.Coord, 0, 1)
+	for _, k := range cells.Sorted() {
+		if b.ClueAt(k) != 0 {
+			clues = append(clues, k)
+		}
+	}
+	return cells, clues
+}
+
+// IsSolved reports whether every cell is known and every rule holds. The
+// error says what is still wrong.
+func (b *Board) IsSolved() (bool, error) {
+	res, coord := b.IsComplete()
+	if !res {
+		return false, fmt.Errorf("cell %s is unknown", coord)
+	}
+
+	// Does every island hold one number, and have that many cells?
+	seen := grid.NewCoordSet()
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsClear(c) || seen.Has(c) {
+			continue
+		}
+		cells, clues := b.Island(c)
+		seen.AddAll(cells)
+		if len(clues) != 1 {
+			return false, fmt.Errorf("island at %s holds %d numbers", c, len(clues))
+		}
+		if n := b.ClueAt(clues[0]); cells.Size() != n {
+			return false, fmt.Errorf("island of the %d at %s has %d cells", n, clues[0], cells.Size())
+		}
+	}
+
+	// Is the sea contiguous?
+	var start grid.Coord
+	found := false
+	b.EachCell(func(cd grid.Coord, v grid.Cell) bool {
+		if v == grid.PAINTED {
+			start = cd
+			found = true
+			return true
+		}
+		return false
+	})
+	if found {
+		reached := b.Flood(start, func(v grid.Cell) bool { return v == grid.PAINTED })
+		for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+			if !reached.Has(c) && b.IsPainted(c) {
+				return false, fmt.Errorf("cannot reach painted cell %s from %s", c, start)
+			}
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Validate returns an error if the board's current contents already break a
+// rule, even though some cells may still be unknown: a painted number, a
+// 2x2 pool of painted cells, an island with two numbers or more cells than
+// its number, or painted cells that can no longer be joined through cells
+// that are not clear. For a complete board, Validate returns nil exactly
+// when IsSolved returns true.
+func (b *Board) Validate() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.IsPainted(c) && b.ClueAt(c) != 0 {
+			return board.Contradiction(c, "Validate", "the %d is painted", b.ClueAt(c))
+		}
+		if b.isPool(c) {
+			return board.Contradiction(c, "Validate", "2x2 block of painted cells")
+		}
+	}
+	seen := grid.NewCoordSet()
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsClear(c) || seen.Has(c) {
+			continue
+		}
+		cells, clues := b.Island(c)
+		seen.AddAll(cells)
+		if len(clues) > 1 {
+			return board.Contradiction(clues[1], "Validate", "island joins the %d at %s and the %d at %s", b.ClueAt(clues[0]), clues[0], b.ClueAt(clues[1]), clues[1])
+		}
+		if len(clues) == 1 && cells.Size() > b.ClueAt(clues[0]) {
+			return board.Contradiction(clues[0], "Validate", "island of the %d has %d cells", b.ClueAt(clues[0]), cells.Size())
+		}
+	}
+
+	// Flood fill through cells that are not clear from the first painted
+	// cell; every other painted cell must be reached.
+	var start grid.Coord
+	found := false
+	b.EachCell(func(c grid.Coord, v grid.Cell) bool {
+		if v == grid.PAINTED {
+			start = c
+			found = true
+			return true
+		}
+		return false
+	})
+	if !found {
+		return nil
+	}
+	reached := b.Flood(start, board.NotClear)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.IsPainted(c) && !reached.Has(c) {
+			return board.Contradiction(c, "Validate", "painted cell is cut off from painted cell %s", start)
+		}
+	}
+	return nil
+}
+
+// poolAt returns the cells of the 2x2 block whose top left cell is c, or
+// nil if the block runs off the board.
+func (b *Board) poolAt(c grid.Coord) []grid.Coord {
+	block := []grid.Coord{c, c.Plus(grid.RIGHT), c.Plus(grid.DOWN), c.Plus(grid.RIGHT).Plus(grid.DOWN)}
+	if !b.IsValid(block[3]) {
+		return nil
+	}
+	return block
+}
+
+// isPool reports whether the 2x2 block whose top left cell is c is all
+// painted.
+func (b *Board) isPool(c grid.Coord) bool {
+	block := b.poolAt(c)
+	if block == nil {
+		return false
+	}
+	for _, k := range block {
+		if !b.IsPainted(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the board whose cells can be changed without
+// affecting the original.
+func (b *Board) Clone() *Board {
+	return &Board{
+		RectBinBoard: *b.RectBinBoard.Clone(),
+		Clues:        b.Clues,
+		AllClues:     b.AllClues,
+	}
+}
+
+// Mark writes v to c and runs the rules that follow from it.
+func (b *Board) Mark(c grid.Coord, v grid.Cell) (bool, error) {
+	res, err := b.Set(c, v)
+	if !res {
+		return res, err
+	}
+	b.SetDirty()
+	return res, b.PostMark(c, v)
+}
+
+func (b *Board) MarkPainted(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.PAINTED)
+}
+
+func (b *Board) MarkClear(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.CLEAR)
+}
+
+// PostMark runs the rules that follow from v having just been written to c:
+// a painted cell that makes three of a 2x2 block painted clears the
+// fourth.
+func (b *Board) PostMark(c grid.Coord, v grid.Cell) error {
+	if v != grid.PAINTED {
+		return nil
+	}
+	for _, corner := range []grid.Coord{c, c.Plus(grid.LEFT), c.Plus(grid.UP), c.Plus(grid.LEFT).Plus(grid.UP)} {
+		if err := b.avoidPool(corner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// avoidPool clears the last unknown cell of the 2x2 block whose top left
+// cell is c if the other three are painted.
+func (b *Board) avoidPool(c grid.Coord) error {
+	block := b.poolAt(c)
+	if block == nil {
+		return nil
+	}
+	var last grid.Coord
+	unknown := 0
+	for _, k := range block {
+		if b.IsUnknown(k) {
+			unknown++
+			last = k
+		} else if !b.IsPainted(k) {
+			return nil
+		}
+	}
+	if unknown == 0 {
+		return board.Contradiction(c, "AvoidPools", "2x2 block of painted cells")
+	}
+	if unknown > 1 {
+		return nil
+	}
+	b.BeginStep("AvoidPools", c)
+	defer b.EndStep()
+	_, err := b.MarkClear(last)
+	return err
+}
+
+// String draws the board in a frame, with X for painted cells, · for clear
+// ones and the numbers. Numbers above 35 switch every cell to the wide
+// grid.CellFormat.
+func (b *Board) String() string {
+	largest := 0
+	for _, c := range b.AllClues {
+		largest = max(largest, b.ClueAt(c))
+	}
+	f := grid.FormatFor(largest)
+	out := "+" + strings.Repeat("-", b.W*f.Len()) + "+\n"
+	for y, row := range b.Clues {
+		out += "|"
+		for x, n := range row {
+			if n != 0 {
+				out += f.Num(n)
+			} else {
+				out += f.Mark(b.Get(grid.Coord{X: x, Y: y}).Ch())
+			}
+		}
+		out += "|"
+		if y != b.H-1 {
+			out += "\n"
+		}
+	}
+	out += "\n+" + strings.Repeat("-", b.W*f.Len()) + "+"
+	return out
+}
+
+// AvoidPools looks for 2x2 blocks with three painted cells and clears the
+// fourth. PostMark already does this whenever a cell is painted, so this
+// only finds work on boards whose cells were filled in without it, such as
+// a partially solved state loaded for a hint.
+func (b *Board) AvoidPools() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if err := b.avoidPool(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinishIslands looks for islands that have as many cells as their number
+// and paints their unknown neighbors.
+func (b *Board) FinishIslands() error {
+	for _, root := range b.AllClues {
+		cells, clues := b.Island(root)
+		if len(clues) > 1 {
+			return board.Contradiction(root, "FinishIslands", "island joins the %d at %s and the %d at %s", b.ClueAt(clues[0]), clues[0], b.ClueAt(clues[1]), clues[1])
+		}
+		n := b.ClueAt(root)
+		if cells.Size() > n {
+			return board.Contradiction(root, "FinishIslands", "island of the %d has %d cells", n, cells.Size())
+		}
+		if cells.Size() < n {
+			continue
+		}
+		for _, c := range cells.Sorted() {
+			for _, dir := range grid.DIRECTIONS {
+				if k := c.Plus(dir); b.IsUnknown(k) {
+					b.BeginStep("FinishIslands", root)
+					_, err := b.MarkPainted(k)
+					b.EndStep()
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SeparateIslands looks for unknown cells next to two different numbered
+// islands and paints them, since clearing them would join the islands.
+func (b *Board) SeparateIslands() error {
+	owner := b.owners()
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsUnknown(c) {
+			continue
+		}
+		var first grid.Coord
+		found := false
+		for _, dir := range grid.DIRECTIONS {
+			n := c.Plus(dir)
+			if !b.IsValid(n) {
+				continue
+			}
+			o, ok := owner[n]
+			if !ok {
+				continue
+			}
+			if !found {
+				first, found = o, true
+			} else if o != first {
+				b.BeginStep("SeparateIslands", first, o)
+				_, err := b.MarkPainted(c)
+				b.EndStep()
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// owners maps every clear cell of a numbered island to the numbered cell.
+func (b *Board) owners() map[grid.Coord]grid.Coord {
+	owner := make(map[grid.Coord]grid.Coord)
+	for _, root := range b.AllClues {
+		if _, ok := owner[root]; ok {
+			continue
+		}
+		cells, _ := b.Island(root)
+		for _, c := range cells.Sorted() {
+			owner[c] = root
+		}
+	}
+	return owner
+}
+
+// ExtendIslands looks for unfinished islands with a single unknown neighbor
+// and clears it: the island can only grow that way. Clear cells without a
+// number must still join a numbered island, so they count as unfinished
+// islands too.
+func (b *Board) ExtendIslands() error {
+	done := grid.NewCoordSet()
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsClear(c) || done.Has(c) {
+			continue
+		}
+		cells, clues := b.Island(c)
+		done.AddAll(cells)
+		if len(clues) > 1 || (len(clues) == 1 && cells.Size() >= b.ClueAt(clues[0])) {
+			continue
+		}
+		libs := grid.NewCoordSet()
+		for _, k := range cells.Sorted() {
+			for _, dir := range grid.DIRECTIONS {
+				if n := k.Plus(dir); b.IsUnknown(n) {
+					libs.Add(n)
+				}
+			}
+		}
+		if libs.Size() == 0 {
+			if len(clues) == 0 {
+				return board.Contradiction(c, "ExtendIslands", "clear cells without a number are walled in")
+			}
+			return board.Contradiction(clues[0], "ExtendIslands", "island of the %d is walled in at %d cells", b.ClueAt(clues[0]), cells.Size())
+		}
+		if libs.Size() == 1 {
+			at := c
+			if len(clues) == 1 {
+				at = clues[0]
+			}
+			b.BeginStep("ExtendIslands", at)
+			_, err := b.MarkClear(libs.GetOne())
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PaintUnreachable paints every unknown cell that no island can reach. An
+// island grows from its numbered cell through unknown cells and clear cells
+// without a number, one cell per step, until it has as many cells as its
+// number, but never onto a cell next to another numbered island. A clear
+// cell that no island can reach is a contradiction.
+func (b *Board) PaintUnreachable() error {
+	owner := b.owners()
+	reachable := grid.NewCoordSet()
+	for _, root := range b.AllClues {
+		cells, _ := b.Island(root)
+		reachable.AddAll(cells)
+		left := b.ClueAt(root) - cells.Size()
+		frontier := cells.Sorted()
+		seen := cells.Copy()
+		for step := 0; step < left && len(frontier) > 0; step++ {
+			next := make([]grid.Coord, 0)
+			for _, c := range frontier {
+				for _, dir := range grid.DIRECTIONS {
+					k := c.Plus(dir)
+					if !b.IsValid(k) || b.IsPainted(k) || seen.Has(k) || b.touchesOther(k, root, owner) {
+						continue
+					}
+					if _, ok := owner[k]; ok {
+						continue
+					}
+					seen.Add(k)
+					reachable.Add(k)
+					next = append(next, k)
+				}
+			}
+			frontier = next
+		}
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if reachable.Has(c) {
+			continue
+		}
+		if b.IsClear(c) {
+			return board.Contradiction(c, "PaintUnreachable", "no island can reach clear cell")
+		}
+		if b.IsUnknown(c) {
+			b.BeginStep("PaintUnreachable", c)
+			_, err := b.MarkPainted(c)
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// touchesOther reports whether c is next to a cell of a numbered island
+// other than the one numbered at root.
+func (b *Board) touchesOther(c, root grid.Coord, owner map[grid.Coord]grid.Coord) bool {
+	for _, dir := range grid.DIRECTIONS {
+		if o, ok := owner[c.Plus(dir)]; ok && o != root {
+			return true
+		}
+	}
+	return false
+}
+
+// PaintMiniDominators looks for painted cells with one liberty and paints
+// the liberty. Limited case of PaintAllDominators below.
+func (b *Board) PaintMiniDominators() error {
+	var err error
+	b.EachCell(func(c grid.Coord, v grid.Cell) bool {
+		if v != grid.PAINTED {
+			return false
+		}
+		liberties, lib := b.Liberties(c, board.NotClear)
+		if liberties == 1 {
+			b.BeginStep("PaintMiniDominators", c)
+			_, err = b.MarkPainted(lib)
+			b.EndStep()
+		} else if liberties == 0 && b.hasOtherPainted(c) {
+			err = board.Contradiction(c, "PaintMiniDominators", "painted cell is walled in by clear cells")
+		}
+		return err != nil
+	})
+	return err
+}
+
+// hasOtherPainted returns true iff some painted cell other than c exists.
+func (b *Board) hasOtherPainted(c grid.Coord) bool {
+	found := false
+	b.EachCell(func(o grid.Coord, v grid.Cell) bool {
+		found = v == grid.PAINTED && o != c
+		return found
+	})
+	return found
+}
+
+// PaintAllDominators paints every unknown cell that dominates a painted
+// cell as seen from start, another painted cell; see
+// board.RectBinBoard.Dominators. Clearing it would cut the two apart.
+// Unknown cells that cannot be reached from start at all are cleared, since
+// painting them would leave them cut off. Call it from two different
+// painted cells to find every dominator.
+func (b *Board) PaintAllDominators(start grid.Coord) error {
+	reached := b.Flood(start, board.NotClear)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if reached.Has(c) {
+			continue
+		}
+		if b.IsPainted(c) {
+			return board.Contradiction(c, "PaintAllDominators", "painted cell is cut off from painted cell %s", start)
+		}
+		if b.IsUnknown(c) {
+			b.BeginStep("PaintAllDominators", start, c)
+			_, err := b.MarkClear(c)
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	doms := b.Dominators(start, board.NotClear)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsPainted(c) || doms[c.Y][c.X] == nil || doms[c.Y][c.X].Size() < 3 {
+			continue
+		}
+		for _, k := range doms[c.Y][c.X].Sorted() {
+			if k != start && k != c && b.IsUnknown(k) {
+				b.BeginStep("PaintAllDominators", start, c)
+				_, err := b.MarkPainted(k)
+				b.EndStep()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PaintDominators runs PaintAllDominators from the first two painted cells
+// on the board.
+func (b *Board) PaintDominators() error {
+	done := 0
+	for c := b.TopLeft(); b.IsValid(c) && done < 2; c = b.Next(c) {
+		if b.IsPainted(c) {
+			if err := b.PaintAllDominators(c); err != nil {
+				return err
+			}
+			done++
+		}
+	}
+	return nil
+}
+
+// Solve applies the rules until none of them makes progress. Returns a
+// ContradictionError if the board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	b.SetDirty()
+	for b.IsDirty() {
+		b.ClearDirty()
+		for _, rule := range []func() error{
+			b.FinishIslands,
+			b.SeparateIslands,
+			b.ExtendIslands,
+			b.PaintMiniDominators,
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			if err := rule(); err != nil {
+				return err
+			}
+		}
+		if b.IsDirty() {
+			continue
+		}
+		for _, rule := range []func() error{
+			b.PaintUnreachable,
+			b.PaintDominators,
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			if err := rule(); err != nil {
+				return err
+			}
+		}
+	}
+	return b.Validate()
+}
+
+// BoardFromLines reads a puzzle in which every digit or lowercase letter (see
+// grid.CharToNum) is the number of an island and anything else is an empty
+// cell, or a tokenized grid (see grid.IsTokenGrid) for numbers above 35. A
+// single line holding a puzz.link URL is read with BoardFromURL. Every
+// problem Lint finds is returned, joined with errors.Join.
+func BoardFromLines(input []string) (*Board, error) {
+	if len(input) == 1 && pzpr.IsURL(input[0]) {
+		return BoardFromURL(input[0])
+	}
+	if errs := Lint(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	nums, err := grid.LinesToNumGrid(input)
+	if err != nil {
+		return nil, err
+	}
+	w, _ := grid.Width(input, nums)
+	clues := grid.MakeNumGrid(w, len(nums))
+	for y, row := range nums {
+		copy(clues[y], row)
+	}
+	return newBoard(clues)
+}
+
+// Lines returns the puzzle's numbers in the text format BoardFromLines
+// reads.
+func (b *Board) Lines() []string {
+	return grid.NumGridToLines(b.Clues, grid.UNKNOWN)
+}
+
+// newBoard returns a board with the given numbers, 0 for none, with the
+// numbered cells clear.
+func newBoard(clues [][]int) (*Board, error) {
+	b := &Board{
+		RectBinBoard: *board.NewRectBinBoard(len(clues[0]), len(clues)),
+		Clues:        clues,
+		AllClues:     make([]grid.Coord, 0),
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.ClueAt(c) == 0 {
+			continue
+		}
+		b.AllClues = append(b.AllClues, c)
+		if _, err := b.MarkClear(c); err != nil {
+			return nil, err
+		}
+	}
+	b.Inited = true
+	return b, nil
+}
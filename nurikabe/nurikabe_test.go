@@ -0,0 +1,71 @@
+package nurikabe
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+func TestRules(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		paint []grid.Coord
+		rule  func(*Board) error
+		at    grid.Coord
+		want  grid.Cell
+	}{
+		{"finished island", []string{"1..", "...", "..."}, nil, (*Board).FinishIslands, grid.Coord{X: 1, Y: 0}, grid.PAINTED},
+		{"between islands", []string{"2.2", "...", "..."}, nil, (*Board).SeparateIslands, grid.Coord{X: 1, Y: 0}, grid.PAINTED},
+		{"one way out", []string{"2..", "...", "..."}, []grid.Coord{{X: 1, Y: 0}}, (*Board).ExtendIslands, grid.Coord{X: 0, Y: 1}, grid.CLEAR},
+		{"out of reach", []string{"2..."}, nil, (*Board).PaintUnreachable, grid.Coord{X: 3, Y: 0}, grid.PAINTED},
+		{"within reach", []string{"2..."}, nil, (*Board).PaintUnreachable, grid.Coord{X: 1, Y: 0}, grid.UNKNOWN},
+		{"pool", []string{"...", "...", "..3"}, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}}, (*Board).AvoidPools, grid.Coord{X: 1, Y: 1}, grid.CLEAR},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.paint {
+				b.Set(c, grid.PAINTED)
+			}
+			if err := tt.rule(b); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.Get(tt.at); got != tt.want {
+				t.Errorf("%s is %s; want %s\n%s", tt.at, grid.CellName(got), grid.CellName(tt.want), b)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		paint []grid.Coord
+		clear []grid.Coord
+	}{
+		{"pool", []string{"...", "...", "..3"}, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}, nil},
+		{"joined islands", []string{"1.1", "...", "..."}, nil, []grid.Coord{{X: 1, Y: 0}}},
+		{"island too large", []string{"2..", "...", "..."}, nil, []grid.Coord{{X: 1, Y: 0}, {X: 2, Y: 0}}},
+		{"split sea", []string{"...", "...", "..1"}, []grid.Coord{{X: 0, Y: 0}, {X: 2, Y: 0}}, []grid.Coord{{X: 1, Y: 0}, {X: 0, Y: 1}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.paint {
+				b.Set(c, grid.PAINTED)
+			}
+			for _, c := range tt.clear {
+				b.Set(c, grid.CLEAR)
+			}
+			if err := b.Validate(); err == nil {
+				t.Errorf("Validate found nothing wrong with\n%s", b)
+			}
+		})
+	}
+}
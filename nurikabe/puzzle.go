@@ -0,0 +1,66 @@
+package nurikabe
+
+import (
+	"context"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// TypeName is the name the puzzle type is registered under. A nurikabe
+// file looks just like a kuromasu one, so the type has no Detect function;
+// a text file must name it in a puzzle.Header, or the caller must.
+const TypeName = "nurikabe"
+
+func init() {
+	puzzle.Register(puzzle.Type{
+		Name:        TypeName,
+		Description: "nurikabe: paint a connected sea around islands of the given sizes, with no 2x2 pools",
+		Pzpr:        "nurikabe",
+		New:         func() puzzle.Puzzle { return &puzzleAdapter{} },
+	})
+}
+
+// puzzleAdapter adapts Board to the puzzle.Puzzle interface.
+type puzzleAdapter struct {
+	*Board
+}
+
+// Parse reads a puzzle in the text format of BoardFromLines, after an
+// optional puzzle.Header.
+func (p *puzzleAdapter) Parse(lines []string) (err error) {
+	p.Board, err = puzzle.ParseText(TypeName, lines, BoardFromLines)
+	return err
+}
+
+func (p *puzzleAdapter) ParseDocument(d *puzzle.Document) (err error) {
+	p.Board, err = BoardFromDocument(d)
+	return err
+}
+
+func (p *puzzleAdapter) Clone() puzzle.Puzzle {
+	return &puzzleAdapter{p.Board.Clone()}
+}
+
+func (p *puzzleAdapter) SearchContext(ctx context.Context) (bool, error) {
+	return board.SearchBin(ctx, p.Board)
+}
+
+func (p *puzzleAdapter) CountSolutionsContext(ctx context.Context, limit int) (int, []puzzle.Puzzle, error) {
+	n, witnesses, err := board.CountBin(ctx, p.Board, limit)
+	return n, puzzle.ToPuzzles(witnesses, func(b *Board) puzzle.Puzzle { return &puzzleAdapter{b} }), err
+}
+
+func (p *puzzleAdapter) Hint() (*board.Hint, error) {
+	return board.HintBin(p.Board)
+}
+
+func (p *puzzleAdapter) RateContext(ctx context.Context) (*board.Rating, error) {
+	return board.RateBin(ctx, p.Board)
+}
+
+// Lint lists the problems in a text puzzle after an optional
+// puzzle.Header; see the package function Lint.
+func (p *puzzleAdapter) Lint(lines []string) []error {
+	return puzzle.LintText(lines, Lint)
+}
@@ -0,0 +1,16 @@
+package nurikabe
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the nurikabe rules in increasing order of difficulty.
+func (b *Board) Techniques() []board.Technique {
+	return []board.Technique{
+		board.DirtyTechnique(&b.RectBoard, "FinishIslands", 1, b.FinishIslands),
+		board.DirtyTechnique(&b.RectBoard, "SeparateIslands", 1, b.SeparateIslands),
+		board.DirtyTechnique(&b.RectBoard, "AvoidPools", 1, b.AvoidPools),
+		board.DirtyTechnique(&b.RectBoard, "ExtendIslands", 2, b.ExtendIslands),
+		board.DirtyTechnique(&b.RectBoard, "PaintMiniDominators", 2, b.PaintMiniDominators),
+		board.DirtyTechnique(&b.RectBoard, "PaintUnreachable", 3, b.PaintUnreachable),
+		board.DirtyTechnique(&b.RectBoard, "PaintAllDominators", 4, b.PaintDominators),
+	}
+}
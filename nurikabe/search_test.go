@@ -0,0 +1,17 @@
+package nurikabe
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckBin(t, BoardFromLines, []boardtest.Case{
+		{Name: "unique", Lines: []string{"....", "...4", "....", "...3"}},
+		{Name: "two", Lines: []string{"3...", "...2", "....", "...3"}},
+		{Name: "one island", Lines: []string{"...6", "....", "....", "...."}},
+		{Name: "two islands", Lines: []string{"..3.", "....", "4...", "...."}},
+		{Name: "no solution", Lines: []string{"....", "5..3", "....", "2..."}},
+	})
+}
@@ -0,0 +1,55 @@
+package nurikabe
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// BoardFromURL reads a puzzle from a pzprjs "nurikabe" URL.
+func BoardFromURL(s string) (*Board, error) {
+	u, err := pzpr.ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "nurikabe" {
+		return nil, fmt.Errorf("puzzle URL is for %q, not nurikabe", u.Type)
+	}
+	nums, _, err := pzpr.DecodeNumber16(u.Body, u.Cols*u.Rows)
+	if err != nil {
+		return nil, err
+	}
+	clues := grid.MakeNumGrid(u.Cols, u.Rows)
+	for i, n := range nums {
+		c := grid.Coord{X: i % u.Cols, Y: i / u.Cols}
+		if n == pzpr.Question {
+			return nil, fmt.Errorf("cell %s holds a question mark, which is not supported", c)
+		} else if n == pzpr.Empty {
+			continue
+		}
+		clues[c.Y][c.X] = n
+	}
+	b, err := newBoard(clues)
+	if err != nil {
+		return nil, err
+	}
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// URL returns the puzzle's numbers as a puzz.link URL.
+func (b *Board) URL() string {
+	nums := make([]int, 0, b.W*b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if n := b.ClueAt(c); n != 0 {
+			nums = append(nums, n)
+		} else {
+			nums = append(nums, pzpr.Empty)
+		}
+	}
+	u := pzpr.URL{Type: "nurikabe", Cols: b.W, Rows: b.H, Body: pzpr.EncodeNumber16(nums)}
+	return u.String()
+}
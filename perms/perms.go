@@ -1,4 +1,6 @@
-package main
+// Package perms enumerates permutations. Results are memoized, so callers
+// must not modify the slices they get back.
+package perms
 
 // permuter is a struct that manages state for the recursive permutation
 // function.
@@ -0,0 +1,164 @@
+package puzzle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+)
+
+// Header is the optional block of "key: value" lines at the top of a text
+// puzzle file, such as
+//
+//	type: towers
+//	size: 4x4
+//	author: A. Setter
+//	source: Monthly Puzzle Pack, no. 12
+//
+// Every key is optional and may be given once. The type may be a
+// registered name or a pzprjs name; the size is columns by rows, or a single
+// number for a square grid. Blank lines between the header and the grid are
+// skipped.
+type Header struct {
+	Type   string
+	Size   string
+	Author string
+	Source string
+}
+
+// SplitHeader separates the header at the start of lines from the grid
+// after it. Lines that do not start with a header come back unchanged,
+// with an empty Header.
+func SplitHeader(lines []string) (Header, []string, error) {
+	var h Header
+	i := 0
+	for ; i < len(lines); i++ {
+		key, val, ok := strings.Cut(lines[i], ":")
+		if !ok {
+			break
+		}
+		var field *string
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "type":
+			field = &h.Type
+		case "size":
+			field = &h.Size
+		case "author":
+			field = &h.Author
+		case "source":
+			field = &h.Source
+		}
+		if field == nil {
+			break
+		}
+		if len(*field) > 0 {
+			return h, nil, fmt.Errorf("line %d: %s given twice", i+1, strings.TrimSpace(key))
+		}
+		*field = strings.TrimSpace(val)
+	}
+	if i == 0 {
+		return h, lines, nil
+	}
+	for i < len(lines) && len(lines[i]) == 0 {
+		i++
+	}
+	return h, lines[i:], nil
+}
+
+// ParseSize reads the header's size as columns and rows. ok is false if
+// the header has no size.
+func (h Header) ParseSize() (w int, ht int, ok bool, err error) {
+	if len(h.Size) == 0 {
+		return 0, 0, false, nil
+	}
+	cols, rows, square := strings.Cut(strings.ToLower(h.Size), "x")
+	w, err = strconv.Atoi(strings.TrimSpace(cols))
+	if err == nil && square {
+		ht, err = strconv.Atoi(strings.TrimSpace(rows))
+	} else {
+		ht = w
+	}
+	if err != nil || w <= 0 || ht <= 0 {
+		return 0, 0, false, fmt.Errorf("size %q is not a number of columns and rows such as 6x8", h.Size)
+	}
+	return w, ht, true, nil
+}
+
+// LookupType returns the puzzle type the header names, by its registered
+// name or its pzprjs name. ok is false if the header has no type.
+func (h Header) LookupType() (Type, bool, error) {
+	if len(h.Type) == 0 {
+		return Type{}, false, nil
+	}
+	if t, ok := Lookup(h.Type); ok {
+		return t, true, nil
+	}
+	if t, ok := LookupPzpr(h.Type); ok {
+		return t, true, nil
+	}
+	return Type{}, false, fmt.Errorf("unrecognized puzzle type \"%s\" in header; see --list-types", h.Type)
+}
+
+// Apply checks a board that a puzzle of type typeName loaded from the
+// lines after the header against the header's type and size, and copies
+// the rest of the header into the board's Meta.
+func (h Header) Apply(typeName string, b *board.RectBoard) error {
+	t, ok, err := h.LookupType()
+	if err != nil {
+		return err
+	}
+	if ok && t.Name != typeName {
+		return fmt.Errorf("header says the puzzle is %s, not %s", t.Name, typeName)
+	}
+	w, ht, ok, err := h.ParseSize()
+	if err != nil {
+		return err
+	}
+	if ok && (w != b.W || ht != b.H) {
+		return fmt.Errorf("header says the grid is %dx%d; it is %dx%d", w, ht, b.W, b.H)
+	}
+	b.Meta = board.Meta{Author: h.Author, Source: h.Source}
+	return nil
+}
+
+// HeaderOf returns the header that describes b as a puzzle of type
+// typeName.
+func HeaderOf(typeName string, b *board.RectBoard) Header {
+	return Header{
+		Type:   typeName,
+		Size:   fmt.Sprintf("%dx%d", b.W, b.H),
+		Author: b.Meta.Author,
+		Source: b.Meta.Source,
+	}
+}
+
+// String formats the header as the lines SplitHeader reads, leaving out
+// empty fields.
+func (h Header) String() string {
+	var sb strings.Builder
+	for _, f := range []struct{ key, val string }{
+		{"type", h.Type},
+		{"size", h.Size},
+		{"author", h.Author},
+		{"source", h.Source},
+	} {
+		if len(f.val) > 0 {
+			fmt.Fprintf(&sb, "%s: %s\n", f.key, f.val)
+		}
+	}
+	return sb.String()
+}
+
+// Detect returns the registered types, sorted by name, whose Detect
+// function accepts lines. It is meant for text files without a header;
+// more than one result means the content alone cannot settle the type.
+func Detect(lines []string) []Type {
+	out := make([]Type, 0)
+	for _, t := range Types() {
+		if t.Detect != nil && t.Detect(lines) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
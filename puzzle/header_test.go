@@ -0,0 +1,146 @@
+package puzzle_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+func TestSplitHeader(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		lines   []string
+		want    puzzle.Header
+		body    []string
+		wantErr bool
+	}{
+		{"no header", []string{"3___", "____"}, puzzle.Header{}, []string{"3___", "____"}, false},
+		{
+			"every key",
+			[]string{"type: towers", "size: 4x4", "author: A. Setter", "source: Monthly Puzzle Pack, no. 12", "4"},
+			puzzle.Header{Type: "towers", Size: "4x4", Author: "A. Setter", Source: "Monthly Puzzle Pack, no. 12"},
+			[]string{"4"},
+			false,
+		},
+		{"blank lines", []string{"Type:  hitori ", "", "", "12", "21"}, puzzle.Header{Type: "hitori"}, []string{"12", "21"}, false},
+		{"unknown key", []string{"author: me", "time: 5m", "12"}, puzzle.Header{Author: "me"}, []string{"time: 5m", "12"}, false},
+		{"key twice", []string{"size: 4", "size: 5", "12"}, puzzle.Header{}, nil, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h, body, err := puzzle.SplitHeader(tt.lines)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v; want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if h != tt.want || !slices.Equal(body, tt.body) {
+				t.Errorf("got %+v and %q; want %+v and %q", h, body, tt.want, tt.body)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for _, tt := range []struct {
+		size    string
+		w, h    int
+		ok      bool
+		wantErr bool
+	}{
+		{"", 0, 0, false, false},
+		{"6x8", 6, 8, true, false},
+		{"6 X 8", 6, 8, true, false},
+		{"5", 5, 5, true, false},
+		{"0x3", 0, 0, false, true},
+		{"ax3", 0, 0, false, true},
+		{"4x", 0, 0, false, true},
+	} {
+		w, h, ok, err := puzzle.Header{Size: tt.size}.ParseSize()
+		if w != tt.w || h != tt.h || ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("size %q: got %d, %d, %v, %v; want %d, %d, %v, error %v", tt.size, w, h, ok, err, tt.w, tt.h, tt.ok, tt.wantErr)
+		}
+	}
+}
+
+func TestLookupType(t *testing.T) {
+	for _, tt := range []struct {
+		typ     string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"kuromasu", "kuromasu", false},
+		{"kurodoko", "kuromasu", false},
+		{"skyscrapers", "towers", false},
+		{"sudoku", "", true},
+	} {
+		typ, ok, err := puzzle.Header{Type: tt.typ}.LookupType()
+		if typ.Name != tt.want || ok != (tt.want != "") || (err != nil) != tt.wantErr {
+			t.Errorf("type %q: got %q, %v, %v; want %q", tt.typ, typ.Name, ok, err, tt.want)
+		}
+	}
+}
+
+func TestHeaderRoundTrip(t *testing.T) {
+	b := board.NewRectBinBoard(4, 3)
+	b.Meta = board.Meta{Author: "A. Setter", Source: "Monthly Puzzle Pack"}
+	want := puzzle.HeaderOf("kuromasu", &b.RectBoard)
+	lines := strings.Split(want.String(), "\n")
+	h, body, err := puzzle.SplitHeader(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != want || len(body) != 0 {
+		t.Errorf("%q reads back as %+v and %q", want.String(), h, body)
+	}
+	got := board.NewRectBinBoard(4, 3)
+	if err := h.Apply("kuromasu", &got.RectBoard); err != nil {
+		t.Fatal(err)
+	}
+	if got.Meta != b.Meta {
+		t.Errorf("Apply set Meta %+v; want %+v", got.Meta, b.Meta)
+	}
+	if err := h.Apply("towers", &got.RectBoard); err == nil {
+		t.Errorf("Apply accepted a header for another type")
+	}
+	if err := h.Apply("kuromasu", &board.NewRectBinBoard(3, 4).RectBoard); err == nil {
+		t.Errorf("Apply accepted a header for another size")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, tt := range samples {
+		t.Run(tt.typeName, func(t *testing.T) {
+			if typ, _ := puzzle.Lookup(tt.typeName); typ.Detect == nil {
+				t.Skip("type needs a header")
+			}
+			names := make([]string, 0)
+			for _, typ := range puzzle.Detect(tt.lines) {
+				names = append(names, typ.Name)
+			}
+			if !slices.Contains(names, tt.typeName) {
+				t.Errorf("Detect returned %v; want it to include %s", names, tt.typeName)
+			}
+		})
+	}
+}
+
+func TestParseWithHeader(t *testing.T) {
+	for _, tt := range samples {
+		t.Run(tt.typeName, func(t *testing.T) {
+			lines := append([]string{"type: " + tt.typeName, "author: A. Setter", ""}, tt.lines...)
+			p := parse(t, tt.typeName, lines)
+			d, ok := p.(puzzle.Described)
+			if !ok {
+				t.Skip("type carries no metadata")
+			}
+			if got := d.Metadata().Author; got != "A. Setter" {
+				t.Errorf("author %q; want \"A. Setter\"", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package puzzle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Document is the JSON form of a puzzle, whether blank, partly solved or
+// solved. Every puzzle type uses the same schema and reads the fields that
+// apply to it, ignoring the rest:
+//
+//	type        the registered type name, such as "kuromasu"
+//	width       number of columns
+//	height      number of rows
+//	order       towers: the largest height
+//	crosses     kuromasu: the numbered cells, as {"at", "size"}
+//	observers   towers: the clues outside the grid, as {"start", "dir",
+//	            "count"}; an observer at start looks in direction dir
+//	regions     ripple, heyawake, starbattle: a height by width grid of
+//	            region numbers
+//	counts      heyawake: the number of painted cells in each region, by
+//	            region number; -1 for a region without one
+//	numbers     hitori: a height by width grid of the number in each cell;
+//	            nurikabe: the same with 0 for a cell without a number
+//	stars       starbattle: the number of stars in each row, column and
+//	            region; 0 means 1
+//	rows        nonogram: the runs of painted cells in each row, top to
+//	            bottom, as a list of lengths; [] for a row without any
+//	columns     nonogram: the same for each column, left to right
+//	cells       optional height by width grid of values; 0 is unknown, and
+//	            on painted/clear puzzles 1 is painted and 2 is clear
+//	candidates  optional height by width grid of the values each cell may
+//	            still take; an empty list leaves the cell's candidates alone
+//	wings       kuromasu, optional: the length range of each wing, as
+//	            {"root", "dir", "min", "max"}
+//	solved      written only: whether the board is solved
+//	author      optional: who made the puzzle
+//	source      optional: where the puzzle was published
+//
+// Coordinates and directions are objects with x (across) and y (down)
+// members, counted from zero, as in the trace. When a puzzle is written
+// back out, cells holds everything solved so far, and candidates and wings
+// hold the solver's pencil marks.
+type Document struct {
+	Type       string            `json:"type"`
+	Width      int               `json:"width"`
+	Height     int               `json:"height"`
+	Order      int               `json:"order,omitempty"`
+	Crosses    []CrossClue       `json:"crosses,omitempty"`
+	Observers  []ObserverClue    `json:"observers,omitempty"`
+	Regions    [][]int           `json:"regions,omitempty"`
+	Numbers    [][]int           `json:"numbers,omitempty"`
+	Counts     []int             `json:"counts,omitempty"`
+	Stars      int               `json:"stars,omitempty"`
+	Rows       [][]int           `json:"rows,omitempty"`
+	Columns    [][]int           `json:"columns,omitempty"`
+	Cells      [][]int           `json:"cells,omitempty"`
+	Candidates [][][]int         `json:"candidates,omitempty"`
+	Wings      []board.WingRange `json:"wings,omitempty"`
+	Solved     bool              `json:"solved,omitempty"`
+	Author     string            `json:"author,omitempty"`
+	Source     string            `json:"source,omitempty"`
+}
+
+// CrossClue is a numbered cell of a kuromasu puzzle.
+type CrossClue struct {
+	At   grid.Coord `json:"at"`
+	Size int        `json:"size"`
+}
+
+// ObserverClue is a towers observer that looks from Start in direction Dir
+// and sees Count towers.
+type ObserverClue struct {
+	Start grid.Coord `json:"start"`
+	Dir   grid.Delta `json:"dir"`
+	Count int        `json:"count"`
+}
+
+// Documenter is a puzzle that can be read from and written to a Document.
+type Documenter interface {
+	ParseDocument(d *Document) error
+	Document() *Document
+}
+
+// ReadDocument decodes a Document from r and checks that its grids match
+// its size.
+func ReadDocument(r io.Reader) (*Document, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var d Document
+	if err := dec.Decode(&d); err != nil {
+		return nil, err
+	}
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// LoadDocument reads a Document from the named file.
+func LoadDocument(fn string) (*Document, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDocument(f)
+}
+
+// Write encodes d to w as indented JSON.
+func (d *Document) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// Check returns an error if d has no size, or if one of its grids or clues
+// does not fit that size.
+func (d *Document) Check() error {
+	if d.Width <= 0 || d.Height <= 0 {
+		return fmt.Errorf("document must have a positive width and height; have %dx%d", d.Width, d.Height)
+	}
+	if err := checkRows("regions", len(d.Regions), d.Height, func(y int) int { return len(d.Regions[y]) }, d.Width); err != nil {
+		return err
+	}
+	if err := checkRows("numbers", len(d.Numbers), d.Height, func(y int) int { return len(d.Numbers[y]) }, d.Width); err != nil {
+		return err
+	}
+	if err := checkRows("cells", len(d.Cells), d.Height, func(y int) int { return len(d.Cells[y]) }, d.Width); err != nil {
+		return err
+	}
+	if err := checkRows("candidates", len(d.Candidates), d.Height, func(y int) int { return len(d.Candidates[y]) }, d.Width); err != nil {
+		return err
+	}
+	if len(d.Rows) != 0 && len(d.Rows) != d.Height {
+		return fmt.Errorf("rows has %d entries; document has height %d", len(d.Rows), d.Height)
+	}
+	if len(d.Columns) != 0 && len(d.Columns) != d.Width {
+		return fmt.Errorf("columns has %d entries; document has width %d", len(d.Columns), d.Width)
+	}
+	for _, c := range d.Crosses {
+		if !d.contains(c.At) {
+			return fmt.Errorf("cross %s is off the board", c.At)
+		}
+	}
+	for _, w := range d.Wings {
+		if !d.contains(w.Root) {
+			return fmt.Errorf("wing root %s is off the board", w.Root)
+		}
+	}
+	return nil
+}
+
+// SetBinCells writes the cells of d to b, a board of painted and clear
+// cells, as they are, without running any rules, and gives b the author and
+// source of d.
+func (d *Document) SetBinCells(b *board.RectBinBoard) error {
+	for y, row := range d.Cells {
+		for x, v := range row {
+			if v == grid.UNKNOWN {
+				continue
+			}
+			if v != grid.PAINTED && v != grid.CLEAR {
+				return fmt.Errorf("cell (%d,%d) has value %d; want %d (painted) or %d (clear)", x, y, v, grid.PAINTED, grid.CLEAR)
+			}
+			if _, err := b.Set(grid.Coord{X: x, Y: y}, grid.Cell(v)); err != nil {
+				return err
+			}
+		}
+	}
+	b.Meta = board.Meta{Author: d.Author, Source: d.Source}
+	b.SetDirty()
+	return nil
+}
+
+// checkRows returns an error unless a grid called name is absent (rows is
+// 0) or has h rows of w entries each.
+func checkRows(name string, rows, h int, rowLen func(y int) int, w int) error {
+	if rows == 0 {
+		return nil
+	}
+	if rows != h {
+		return fmt.Errorf("%s has %d rows; document has height %d", name, rows, h)
+	}
+	for y := 0; y < h; y++ {
+		if rowLen(y) != w {
+			return fmt.Errorf("%s row %d has %d entries; document has width %d", name, y, rowLen(y), w)
+		}
+	}
+	return nil
+}
+
+func (d *Document) contains(c grid.Coord) bool {
+	return c.X >= 0 && c.Y >= 0 && c.X < d.Width && c.Y < d.Height
+}
@@ -0,0 +1,149 @@
+package puzzle_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// roundTrip writes p as JSON, reads it back into a new puzzle of the same
+// type and returns that puzzle along with both encodings.
+func roundTrip(t *testing.T, typeName string, p puzzle.Puzzle) (puzzle.Puzzle, string, string) {
+	t.Helper()
+	var first bytes.Buffer
+	if err := p.(puzzle.Documenter).Document().Write(&first); err != nil {
+		t.Fatal(err)
+	}
+	d, err := puzzle.ReadDocument(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, first.String())
+	}
+	if d.Type != typeName {
+		t.Errorf("document type is %q; want %q", d.Type, typeName)
+	}
+	typ, _ := puzzle.Lookup(typeName)
+	q := typ.New()
+	if err := q.(puzzle.Documenter).ParseDocument(d); err != nil {
+		t.Fatal(err)
+	}
+	var second bytes.Buffer
+	if err := q.(puzzle.Documenter).Document().Write(&second); err != nil {
+		t.Fatal(err)
+	}
+	return q, first.String(), second.String()
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	for _, tt := range samples {
+		t.Run(tt.typeName, func(t *testing.T) {
+			p := parse(t, tt.typeName, tt.lines)
+			q, first, second := roundTrip(t, tt.typeName, p)
+			if q.String() != p.String() {
+				t.Errorf("read back as\n%s\nwant\n%s", q, p)
+			}
+			if first != second {
+				t.Errorf("written again as\n%s\nwant\n%s", second, first)
+			}
+		})
+	}
+}
+
+func TestDocumentRoundTripSolved(t *testing.T) {
+	for _, tt := range samples {
+		t.Run(tt.typeName, func(t *testing.T) {
+			p := parse(t, tt.typeName, tt.lines)
+			if ok, err := p.(puzzle.Searcher).SearchContext(context.Background()); !ok {
+				t.Fatal(err)
+			}
+			q, first, second := roundTrip(t, tt.typeName, p)
+			if solved, err := q.IsSolved(); !solved {
+				t.Errorf("read back unsolved: %v\n%s", err, q)
+			}
+			if q.String() != p.String() {
+				t.Errorf("read back as\n%s\nwant\n%s", q, p)
+			}
+			if first != second {
+				t.Errorf("written again as\n%s\nwant\n%s", second, first)
+			}
+		})
+	}
+}
+
+func TestCoordJSON(t *testing.T) {
+	out, err := json.Marshal(grid.Coord{X: 1, Y: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"x":1,"y":2}` {
+		t.Errorf("Coord encodes as %s; want {\"x\":1,\"y\":2}", out)
+	}
+	for _, in := range []string{`{"x":1,"y":2}`, `{"X":1,"Y":2}`} {
+		var c grid.Coord
+		if err := json.Unmarshal([]byte(in), &c); err != nil || c != (grid.Coord{X: 1, Y: 2}) {
+			t.Errorf("%s decodes as %v (%v); want (1,2)", in, c, err)
+		}
+	}
+}
+
+// TestLinesRoundTrip checks that every type writes its puzzle back in the
+// text format it reads, which its loaders lint.
+func TestLinesRoundTrip(t *testing.T) {
+	for _, tt := range samples {
+		t.Run(tt.typeName, func(t *testing.T) {
+			p := parse(t, tt.typeName, tt.lines)
+			lines := p.(interface{ Lines() []string }).Lines()
+			if q := parse(t, tt.typeName, lines); q.String() != p.String() {
+				t.Errorf("%q reads back as\n%s\nwant\n%s", lines, q, p)
+			}
+		})
+	}
+}
+
+// TestLoadersLint checks that a puzzle read from a URL or a document goes
+// through its type's Lint, as one read from text does.
+func TestLoadersLint(t *testing.T) {
+	towersURL := pzpr.URL{Type: "skyscrapers", Cols: 3, Rows: 3, Body: pzpr.EncodeNumber16([]int{9})}
+	for _, tt := range []struct {
+		name     string
+		typeName string
+		url      string
+		doc      string
+	}{
+		{"kuromasu url", "kuromasu", "https://puzz.link/p?kurodoko/2/2/9i", ""},
+		{"kuromasu document", "kuromasu", "", `{"type":"kuromasu","width":2,"height":2,"crosses":[{"at":{"x":0,"y":0},"size":99}]}`},
+		{"towers url", "towers", towersURL.String(), ""},
+		{"towers document", "towers", "", `{"type":"towers","width":3,"height":3,"order":3,"observers":[{"start":{"x":0,"y":0},"dir":{"x":0,"y":1},"count":9}]}`},
+		{"regions document", "regions", "", `{"type":"regions","width":2,"height":1,"regions":[[0,0]],"cells":[[1,1]]}`},
+		{"starbattle document", "starbattle", "", `{"type":"starbattle","width":2,"height":2,"stars":2,"regions":[[0,0],[1,1]]}`},
+		{"nurikabe document", "nurikabe", "", `{"type":"nurikabe","width":2,"height":1,"numbers":[[1,1]]}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, _ := puzzle.Lookup(tt.typeName)
+			p := typ.New()
+			var err error
+			if len(tt.url) > 0 {
+				err = p.Parse([]string{tt.url})
+			} else {
+				d, derr := puzzle.ReadDocument(strings.NewReader(tt.doc))
+				if derr != nil {
+					t.Fatal(derr)
+				}
+				err = p.(puzzle.Documenter).ParseDocument(d)
+			}
+			if err == nil {
+				t.Fatalf("loaded without a problem:\n%s", p)
+			}
+			var le *grid.LineError
+			if errors.As(err, &le) {
+				t.Errorf("problem %v has a line, but there are no lines in the input", le)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package puzzle defines the interface every puzzle type implements and the
+// registry that puzzle types add themselves to, so that a program can solve
+// any registered type by name.
+package puzzle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Puzzle is what every puzzle type offers to the command line, and to
+// anything else that wants to solve puzzles without knowing which type it
+// has. Parse reads the puzzle from the lines of its input file into an empty
+// Puzzle; Solve applies the deduction rules and returns a
+// board.ContradictionError if the puzzle turns out to have no solution.
+// SolveContext does the same but gives up, leaving the puzzle partly solved,
+// once ctx is done; see board.ErrTimedOut.
+type Puzzle interface {
+	Parse(lines []string) error
+	Solve() error
+	SolveContext(ctx context.Context) error
+	IsSolved() (bool, error)
+	String() string
+	Clone() Puzzle
+}
+
+// The modes other than plain solving are optional. A puzzle type supports a
+// mode by implementing the matching interface.
+
+// Searcher is a puzzle that can guess and backtrack when Solve stalls. See
+// board.Search.
+type Searcher interface {
+	SearchContext(ctx context.Context) (bool, error)
+}
+
+// Counter is a puzzle whose solutions can be enumerated. See
+// board.CountSolutions.
+type Counter interface {
+	CountSolutionsContext(ctx context.Context, limit int) (int, []Puzzle, error)
+}
+
+// Rater is a puzzle that can grade itself by the techniques it needs; see
+// board.Rate.
+type Rater interface {
+	RateContext(ctx context.Context) (*board.Rating, error)
+}
+
+// Hinter is a board that can take a partially solved state and find the
+// next deduction from it.
+type Hinter interface {
+	ApplyState([]string) error
+	Hint() (*board.Hint, error)
+}
+
+// Tracer is a puzzle that can record the steps it takes.
+type Tracer interface {
+	EnableTrace()
+	Steps() []board.Step
+}
+
+// Linker is a puzzle that can be written as a pzprjs URL.
+type Linker interface {
+	URL() string
+}
+
+// Encoder is a puzzle that can be written as a SAT problem in CNF, and
+// filled in from a SAT solver's model of that problem.
+type Encoder interface {
+	CNF() *cnf.Formula
+	ApplyModel(m cnf.Model) error
+}
+
+// Linter is a puzzle type that can check the lines of a text puzzle for
+// problems without solving it, such as a clue no solution can satisfy, and
+// report all of them at once rather than only the first. Problems with a
+// place in the file are grid.LineErrors.
+type Linter interface {
+	Lint(lines []string) []error
+}
+
+// Checkpointer is a puzzle whose changes can be taken back: Rollback undoes
+// everything since the matching Checkpoint. See board.Trail.
+type Checkpointer interface {
+	Checkpoint()
+	Rollback() bool
+	Commit() bool
+}
+
+// Described is a puzzle that carries a board.Meta, such as one read from a
+// text file with a Header or from a Document with an author.
+type Described interface {
+	Metadata() *board.Meta
+}
+
+// Type is an entry in the registry of puzzle types. New returns an
+// empty Puzzle, ready to be Parsed. Pzpr is the name pzprjs and puzz.link
+// use for the type in URLs, if they know it. Detect, if set, reports
+// whether the lines of a text file without a header look like a puzzle of
+// this type; see the package function Detect.
+type Type struct {
+	Name        string
+	Description string
+	Pzpr        string
+	New         func() Puzzle
+	Detect      func(lines []string) bool
+}
+
+var puzzleTypes = make(map[string]Type)
+
+// Register adds a puzzle type to the registry. Puzzle types register
+// themselves from an init function, so the name must not be taken already.
+func Register(t Type) {
+	if _, ok := puzzleTypes[t.Name]; ok {
+		panic(fmt.Errorf("puzzle type %q registered twice", t.Name))
+	}
+	puzzleTypes[t.Name] = t
+}
+
+// Lookup returns the registered puzzle type with the given name.
+func Lookup(name string) (Type, bool) {
+	t, ok := puzzleTypes[name]
+	return t, ok
+}
+
+// LookupPzpr returns the registered puzzle type that pzprjs calls name.
+func LookupPzpr(name string) (Type, bool) {
+	for _, t := range puzzleTypes {
+		if len(t.Pzpr) > 0 && t.Pzpr == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// Types returns every registered puzzle type, sorted by name.
+func Types() []Type {
+	out := make([]Type, 0, len(puzzleTypes))
+	for _, t := range puzzleTypes {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// ToPuzzles converts the witnesses returned by a typed CountSolutions,
+// wrapping each one with wrap.
+func ToPuzzles[T any](boards []T, wrap func(T) Puzzle) []Puzzle {
+	out := make([]Puzzle, 0, len(boards))
+	for _, b := range boards {
+		out = append(out, wrap(b))
+	}
+	return out
+}
+
+// ParseText is the Parse of a puzzle type whose text format load reads:
+// it splits off an optional Header, loads the lines after it and checks
+// the header against the board, copying the rest of it into the board's
+// Meta. Line numbers in errors count from the top of lines.
+func ParseText[P interface{ Rect() *board.RectBoard }](typeName string, lines []string, load func([]string) (P, error)) (P, error) {
+	var none P
+	h, body, err := SplitHeader(lines)
+	if err != nil {
+		return none, err
+	}
+	b, err := load(body)
+	if err != nil {
+		return none, grid.ShiftLines(err, len(lines)-len(body))
+	}
+	if err := h.Apply(typeName, b.Rect()); err != nil {
+		return none, err
+	}
+	return b, nil
+}
+
+// LintText is the Lint of a puzzle type whose text format lint checks,
+// after an optional Header. Line numbers in errors count from the top of
+// lines.
+func LintText(lines []string, lint func([]string) []error) []error {
+	_, body, err := SplitHeader(lines)
+	if err != nil {
+		return []error{err}
+	}
+	errs := lint(body)
+	for _, e := range errs {
+		grid.ShiftLines(e, len(lines)-len(body))
+	}
+	return errs
+}
@@ -0,0 +1,122 @@
+package puzzle_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+
+	_ "github.com/bismuthsalamander/mutantcheckerboard/heyawake"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/hitori"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/kuromasu"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/nonogram"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/nurikabe"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/ripple"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/starbattle"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/towers"
+)
+
+// samples holds a small puzzle of every registered type.
+var samples = []struct {
+	typeName string
+	lines    []string
+}{
+	{"heyawake", []string{"aabb", "aabb", "ccdd", "ccdd", "----", "0.0.", "....", "0.2.", "...."}},
+	{"hitori", []string{"3144", "4241", "1234", "3424"}},
+	{"kuromasu", []string{"3___", "__5_", "____", "___2"}},
+	{"nonogram", []string{"2", "1,1", "4", "1", "---", "2", "1,1", "3", "2"}},
+	{"nurikabe", []string{"....", "...4", "....", "...3"}},
+	{"regions", []string{"AABB", "AABB", "CCDD", "CCDD", "....", "....", "....", "...."}},
+	{"starbattle", []string{"abbb", "abcc", "ddcc", "dddc"}},
+	{"towers", []string{"4", " 3214 ", "3    2", "2    2", "1    2", "4    1", " 2221 "}},
+}
+
+func parse(t *testing.T, typeName string, lines []string) puzzle.Puzzle {
+	t.Helper()
+	typ, ok := puzzle.Lookup(typeName)
+	if !ok {
+		t.Fatalf("puzzle type %q is not registered", typeName)
+	}
+	p := typ.New()
+	if err := p.Parse(lines); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// TestRateSamples pins the rating every type gives its sample, so that a
+// change to a type's techniques or their costs shows up here.
+func TestRateSamples(t *testing.T) {
+	want := map[string]struct {
+		grade   string
+		score   int
+		hardest string
+	}{
+		"heyawake":   {"diabolical", 13, "Search"},
+		"hitori":     {"hard", 7, "ClearAllDominators"},
+		"kuromasu":   {"diabolical", 10, "Search"},
+		"nonogram":   {"medium", 4, "TrimPermsFromCells"},
+		"nurikabe":   {"diabolical", 33, "Search"},
+		"regions":    {"diabolical", 100, "Search"},
+		"starbattle": {"hard", 9, "ClearBlockers"},
+		"towers":     {"medium", 7, "TrimAllowedFromPerms"},
+	}
+	for _, tt := range samples {
+		t.Run(tt.typeName, func(t *testing.T) {
+			w, ok := want[tt.typeName]
+			if !ok {
+				t.Fatalf("no rating given for %s", tt.typeName)
+			}
+			p := parse(t, tt.typeName, tt.lines)
+			r, err := p.(puzzle.Rater).RateContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !r.Solved {
+				t.Errorf("not solved:\n%s", p)
+			}
+			if solved, err := p.IsSolved(); !solved {
+				t.Errorf("rating left the board unsolved: %v\n%s", err, p)
+			}
+			if r.Grade != w.grade || r.Score != w.score || r.Hardest != w.hardest {
+				t.Errorf("rated %s (score %d, hardest %s); want %s (score %d, hardest %s)", r.Grade, r.Score, r.Hardest, w.grade, w.score, w.hardest)
+			}
+			if (r.SearchDepth > 0) != (w.hardest == "Search") {
+				t.Errorf("search depth is %d, but the hardest technique is %s", r.SearchDepth, r.Hardest)
+			}
+		})
+	}
+}
+
+// TestHintSamples checks that every type finds a hint on its sample exactly
+// when its techniques make some progress there, and leaves the puzzle as it
+// was.
+func TestHintSamples(t *testing.T) {
+	for _, tt := range samples {
+		t.Run(tt.typeName, func(t *testing.T) {
+			p := parse(t, tt.typeName, tt.lines)
+			r, err := p.Clone().(puzzle.Rater).RateContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			progress := r.Order[0] != "Search"
+			before := p.String()
+			h, err := p.(puzzle.Hinter).Hint()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (h != nil) != progress {
+				t.Fatalf("hint is %+v, but the techniques used were %v", h, r.Order)
+			}
+			if h == nil {
+				return
+			}
+			if h.Rule == "" || h.Reason == "" {
+				t.Errorf("hint %+v lacks a rule or a reason", h)
+			}
+			if p.String() != before {
+				t.Errorf("Hint changed the puzzle to\n%s\nfrom\n%s", p, before)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+// Package pzpr reads and writes the puzzle URLs used by pzprjs and
+// puzz.link, such as
+//
+//	https://puzz.link/p?kurodoko/6/6/g5h3k
+//
+// A URL names the pzprjs puzzle type, the number of columns and rows, and
+// a body that packs the clues into printable characters. This package only
+// handles the URL strings; it never goes to the network.
+package pzpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Prefix is what String puts in front of the type, size and body.
+const Prefix = "https://puzz.link/p?"
+
+// The values DecodeNumber16 returns for a cell that has no number and for
+// a cell that holds a question mark.
+const (
+	Empty    = -1
+	Question = -2
+)
+
+// URL is a parsed puzzle URL.
+type URL struct {
+	Type string
+	Cols int
+	Rows int
+	Body string
+}
+
+// IsURL reports whether s looks like a pzprjs URL rather than a line of a
+// text puzzle.
+func IsURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "p?")
+}
+
+// ParseURL splits a puzzle URL into its parts. Everything up to the first
+// "?" is ignored, so puzz.link and pzv.jp URLs are both accepted.
+func ParseURL(s string) (*URL, error) {
+	s = strings.TrimSpace(s)
+	q := strings.Index(s, "?")
+	if q < 0 {
+		return nil, fmt.Errorf("puzzle URL %q has no \"?\"", s)
+	}
+	parts := strings.Split(s[q+1:], "/")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("puzzle URL %q needs a type, a width and a height", s)
+	}
+	cols, err := strconv.Atoi(parts[1])
+	if err != nil || cols <= 0 {
+		return nil, fmt.Errorf("puzzle URL %q has bad width %q", s, parts[1])
+	}
+	rows, err := strconv.Atoi(parts[2])
+	if err != nil || rows <= 0 {
+		return nil, fmt.Errorf("puzzle URL %q has bad height %q", s, parts[2])
+	}
+	return &URL{
+		Type: parts[0],
+		Cols: cols,
+		Rows: rows,
+		Body: strings.Join(parts[3:], "/"),
+	}, nil
+}
+
+func (u *URL) String() string {
+	return fmt.Sprintf("%s%s/%d/%d/%s", Prefix, u.Type, u.Cols, u.Rows, u.Body)
+}
+
+// DecodeNumber16 reads n numbers from the start of body in pzprjs's
+// "number16" encoding and returns them along with the rest of body. Cells
+// without a number are Empty and question marks are Question. If body runs
+// out first, the remaining cells are Empty.
+func DecodeNumber16(body string, n int) ([]int, string, error) {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = Empty
+	}
+	c, i := 0, 0
+	for ; i < len(body) && c < n; i++ {
+		ch := body[i]
+		switch {
+		case ch >= '0' && ch <= '9', ch >= 'a' && ch <= 'f':
+			v, _ := strconv.ParseInt(body[i:i+1], 16, 0)
+			out[c] = int(v)
+		case ch == '-':
+			v, err := hexAt(body, i+1, 2)
+			if err != nil {
+				return nil, "", err
+			}
+			out[c] = v
+			i += 2
+		case ch == '+' || ch == '=' || ch == '%':
+			v, err := hexAt(body, i+1, 3)
+			if err != nil {
+				return nil, "", err
+			}
+			if ch == '=' {
+				v += 4096
+			} else if ch == '%' {
+				v += 8192
+			}
+			out[c] = v
+			i += 3
+		case ch == '.':
+			out[c] = Question
+		case ch >= 'g' && ch <= 'z':
+			// g to z skip 1 to 20 cells; c++ below accounts for one.
+			c += int(ch - 'g')
+		default:
+			return nil, "", fmt.Errorf("unexpected %q at position %d of puzzle URL body", ch, i)
+		}
+		c++
+	}
+	return out, body[i:], nil
+}
+
+func hexAt(body string, start, width int) (int, error) {
+	if start+width > len(body) {
+		return 0, fmt.Errorf("puzzle URL body ends inside a number")
+	}
+	v, err := strconv.ParseInt(body[start:start+width], 16, 0)
+	if err != nil {
+		return 0, fmt.Errorf("bad number %q in puzzle URL body", body[start:start+width])
+	}
+	return int(v), nil
+}
+
+// EncodeNumber16 is the inverse of DecodeNumber16. A negative number other
+// than Question is written as an empty cell.
+func EncodeNumber16(nums []int) string {
+	var sb strings.Builder
+	gap := 0
+	for _, n := range nums {
+		var s string
+		switch {
+		case n == Question:
+			s = "."
+		case n >= 0 && n < 16:
+			s = strconv.FormatInt(int64(n), 16)
+		case n >= 16 && n < 256:
+			s = "-" + strconv.FormatInt(int64(n), 16)
+		case n >= 256 && n < 4096:
+			s = fmt.Sprintf("+%03x", n)
+		case n >= 4096 && n < 8192:
+			s = fmt.Sprintf("=%03x", n-4096)
+		case n >= 8192 && n < 12288:
+			s = fmt.Sprintf("%%%03x", n-8192)
+		default:
+			gap++
+		}
+		if gap > 0 && (s != "" || gap == 20) {
+			sb.WriteString(strconv.FormatInt(int64(15+gap), 36))
+			gap = 0
+		}
+		sb.WriteString(s)
+	}
+	if gap > 0 {
+		sb.WriteString(strconv.FormatInt(int64(15+gap), 36))
+	}
+	return sb.String()
+}
+
+// DecodeNumber36 reads n numbers from the start of body in the encoding
+// pzprjs uses for hitori, which has a number in nearly every cell: one
+// base-36 digit per cell, or "-" and two digits for numbers from 36 up,
+// and "%" for a question mark. Any other character is an empty cell. The
+// rest of body is returned; if body runs out first, the remaining cells are
+// Empty.
+func DecodeNumber36(body string, n int) ([]int, string, error) {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = Empty
+	}
+	c, i := 0, 0
+	for ; i < len(body) && c < n; i++ {
+		ch := body[i]
+		switch {
+		case ch >= '0' && ch <= '9', ch >= 'a' && ch <= 'z':
+			v, _ := strconv.ParseInt(body[i:i+1], 36, 0)
+			out[c] = int(v)
+		case ch == '-':
+			if i+3 > len(body) {
+				return nil, "", fmt.Errorf("puzzle URL body ends inside a number")
+			}
+			v, err := strconv.ParseInt(body[i+1:i+3], 36, 0)
+			if err != nil {
+				return nil, "", fmt.Errorf("bad number %q in puzzle URL body", body[i+1:i+3])
+			}
+			out[c] = int(v)
+			i += 2
+		case ch == '%':
+			out[c] = Question
+		}
+		c++
+	}
+	return out, body[i:], nil
+}
+
+// EncodeNumber36 is the inverse of DecodeNumber36. Numbers it cannot
+// write, such as Empty, are written as ".".
+func EncodeNumber36(nums []int) string {
+	var sb strings.Builder
+	for _, n := range nums {
+		switch {
+		case n == Question:
+			sb.WriteString("%")
+		case n >= 0 && n < 36:
+			sb.WriteString(strconv.FormatInt(int64(n), 36))
+		case n >= 36 && n < 36*36:
+			fmt.Fprintf(&sb, "-%s", strconv.FormatInt(int64(n), 36))
+		default:
+			sb.WriteString(".")
+		}
+	}
+	return sb.String()
+}
+
+// DecodeBorder reads the region borders of a cols by rows grid from the
+// start of body and returns the region of every cell, numbered from 0 in
+// reading order, along with the rest of body. The borders come as bits,
+// five to a base-32 character: first the borders to the right of each
+// cell but the last in its row, row by row, then the borders below each
+// cell but those in the last row.
+func DecodeBorder(body string, cols, rows int) ([][]int, string, error) {
+	nv := (cols - 1) * rows
+	nh := cols * (rows - 1)
+	vert, body, err := decodeBits(body, nv)
+	if err != nil {
+		return nil, "", err
+	}
+	horiz, body, err := decodeBits(body, nh)
+	if err != nil {
+		return nil, "", err
+	}
+	ids := make([][]int, rows)
+	for y := range ids {
+		ids[y] = make([]int, cols)
+		for x := range ids[y] {
+			ids[y][x] = -1
+		}
+	}
+	next := 0
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if ids[y][x] >= 0 {
+				continue
+			}
+			// Flood the region from (x, y) across every side without a
+			// border.
+			stack := [][2]int{{x, y}}
+			ids[y][x] = next
+			for len(stack) > 0 {
+				cx, cy := stack[len(stack)-1][0], stack[len(stack)-1][1]
+				stack = stack[:len(stack)-1]
+				try := func(nx, ny int, open bool) {
+					if open && ids[ny][nx] < 0 {
+						ids[ny][nx] = next
+						stack = append(stack, [2]int{nx, ny})
+					}
+				}
+				if cx > 0 {
+					try(cx-1, cy, !vert[cy*(cols-1)+cx-1])
+				}
+				if cx < cols-1 {
+					try(cx+1, cy, !vert[cy*(cols-1)+cx])
+				}
+				if cy > 0 {
+					try(cx, cy-1, !horiz[(cy-1)*cols+cx])
+				}
+				if cy < rows-1 {
+					try(cx, cy+1, !horiz[cy*cols+cx])
+				}
+			}
+			next++
+		}
+	}
+	return ids, body, nil
+}
+
+// EncodeBorder is the inverse of DecodeBorder: it writes a border between
+// every two neighboring cells whose region numbers differ.
+func EncodeBorder(ids [][]int) string {
+	rows := len(ids)
+	cols := len(ids[0])
+	vert := make([]bool, 0, (cols-1)*rows)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols-1; x++ {
+			vert = append(vert, ids[y][x] != ids[y][x+1])
+		}
+	}
+	horiz := make([]bool, 0, cols*(rows-1))
+	for y := 0; y < rows-1; y++ {
+		for x := 0; x < cols; x++ {
+			horiz = append(horiz, ids[y][x] != ids[y+1][x])
+		}
+	}
+	return encodeBits(vert) + encodeBits(horiz)
+}
+
+// decodeBits reads n bits, most significant first, from base-32
+// characters at the start of body.
+func decodeBits(body string, n int) ([]bool, string, error) {
+	chars := (n + 4) / 5
+	if len(body) < chars {
+		return nil, "", fmt.Errorf("puzzle URL body too short for %d borders", n)
+	}
+	out := make([]bool, 0, chars*5)
+	for i := 0; i < chars; i++ {
+		v, err := strconv.ParseInt(body[i:i+1], 32, 0)
+		if err != nil {
+			return nil, "", fmt.Errorf("bad border character %q in puzzle URL body", body[i])
+		}
+		for bit := 4; bit >= 0; bit-- {
+			out = append(out, v&(1<<bit) != 0)
+		}
+	}
+	return out[:n], body[chars:], nil
+}
+
+func encodeBits(bits []bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(bits); i += 5 {
+		v := 0
+		for j := i; j < i+5; j++ {
+			v <<= 1
+			if j < len(bits) && bits[j] {
+				v |= 1
+			}
+		}
+		sb.WriteString(strconv.FormatInt(int64(v), 32))
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package pzpr_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+
+	_ "github.com/bismuthsalamander/mutantcheckerboard/heyawake"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/hitori"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/kuromasu"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/nurikabe"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/ripple"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/starbattle"
+	_ "github.com/bismuthsalamander/mutantcheckerboard/towers"
+)
+
+var numberTests = []struct {
+	name string
+	nums []int
+}{
+	{"empty", []int{pzpr.Empty, pzpr.Empty, pzpr.Empty}},
+	{"digits", []int{0, 1, 9, 15}},
+	{"two digits", []int{16, 255}},
+	{"three digits", []int{256, 4095}},
+	{"question", []int{pzpr.Question, 3, pzpr.Question}},
+	{"long gap", []int{
+		pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty,
+		pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty,
+		pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty,
+		pzpr.Empty, pzpr.Empty, pzpr.Empty, pzpr.Empty, 7, pzpr.Empty,
+	}},
+}
+
+func TestNumber16RoundTrip(t *testing.T) {
+	for _, tt := range numberTests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := pzpr.EncodeNumber16(tt.nums)
+			got, rest, err := pzpr.DecodeNumber16(body+"/x", len(tt.nums))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.nums) || rest != "/x" {
+				t.Errorf("%q decodes as %v, rest %q; want %v, rest \"/x\"", body, got, rest, tt.nums)
+			}
+		})
+	}
+}
+
+func TestNumber36RoundTrip(t *testing.T) {
+	nums := []int{1, 9, 10, 35, 36, 1000, pzpr.Question}
+	body := pzpr.EncodeNumber36(nums)
+	got, rest, err := pzpr.DecodeNumber36(body, len(nums))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, nums) || rest != "" {
+		t.Errorf("%q decodes as %v, rest %q; want %v", body, got, rest, nums)
+	}
+}
+
+func TestBorderRoundTrip(t *testing.T) {
+	for _, ids := range [][][]int{
+		{{0, 0, 1, 1}, {0, 0, 1, 1}, {2, 2, 3, 3}, {2, 2, 3, 3}},
+		{{0, 1, 1, 1}, {0, 1, 2, 2}, {3, 3, 2, 2}, {3, 3, 3, 2}},
+		{{0, 0, 0}, {0, 1, 0}},
+	} {
+		body := pzpr.EncodeBorder(ids)
+		got, rest, err := pzpr.DecodeBorder(body, len(ids[0]), len(ids))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(got, ids, slices.Equal) || rest != "" {
+			t.Errorf("%q decodes as %v, rest %q; want %v", body, got, rest, ids)
+		}
+	}
+}
+
+// URLs of puzzles of every type with a URL format, as the types write them.
+var urlTests = []string{
+	"https://puzz.link/p?heyawake/4/4/94g1s00002",
+	"https://puzz.link/p?hitori/4/4/3144424112343424",
+	"https://puzz.link/p?kurodoko/4/4/3k5n2",
+	"https://puzz.link/p?nurikabe/4/4/m4m3",
+	"https://puzz.link/p?ripple/4/4/94g1s01s3g",
+	"https://puzz.link/p?starbattle/4/4/1/j487gg",
+	"https://puzz.link/p?skyscrapers/4/4/3214222132142221",
+}
+
+func TestURLRoundTrip(t *testing.T) {
+	for _, s := range urlTests {
+		u, err := pzpr.ParseURL(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(u.Type, func(t *testing.T) {
+			if u.String() != s {
+				t.Errorf("ParseURL(%q).String() = %q", s, u)
+			}
+			typ, ok := puzzle.LookupPzpr(u.Type)
+			if !ok {
+				t.Fatalf("no puzzle type reads %q URLs", u.Type)
+			}
+			p := typ.New()
+			if err := p.Parse([]string{s}); err != nil {
+				t.Fatal(err)
+			}
+			if got := p.(puzzle.Linker).URL(); got != s {
+				t.Errorf("read and written again as %q", got)
+			}
+		})
+	}
+}
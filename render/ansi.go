@@ -0,0 +1,258 @@
+package render
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// boxChars holds the box-drawing character for every combination of arms,
+// indexed by up*27 + right*9 + down*3 + left, where each arm is 0 for none,
+// 1 for a light line and 2 for a heavy one.
+var boxChars = []rune(" ╴╸╷┐┑╻┒┓╶─╾┌┬┭┎┰┱╺╼━┍┮┯┏┲┳╵┘┙│┤┥╽┧┪└┴┵├┼┽┟╁╅┕┶┷┝┾┿┢╆╈╹┚┛╿┦┩┃┨┫┖┸┹┞╀╃┠╂╉┗┺┻┡╄╇┣╊╋")
+
+// The styles of an ANSI drawing, as SGR parameters.
+const (
+	ansiPlain     = ""
+	ansiThin      = "2"
+	ansiClue      = "1"
+	ansiSolved    = "32"
+	ansiCandidate = "2"
+	ansiPainted   = "90"
+	ansiClear     = "36"
+	ansiHighlight = "43"
+)
+
+// IsTerminal reports whether f is a terminal, so that callers can fall back
+// to plain text when output goes to a file or a pipe.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+type styledRune struct {
+	r     rune
+	style string
+}
+
+// canvas is a grid of characters, each with its own style.
+type canvas [][]styledRune
+
+func newCanvas(w, h int) canvas {
+	c := make(canvas, h)
+	for y := range c {
+		c[y] = make([]styledRune, w)
+		for x := range c[y] {
+			c[y][x] = styledRune{' ', ansiPlain}
+		}
+	}
+	return c
+}
+
+// put writes s at (x, y), clipping at the right edge.
+func (c canvas) put(x, y int, s string, style string) {
+	for _, r := range s {
+		if x >= 0 && x < len(c[y]) {
+			c[y][x] = styledRune{r, style}
+		}
+		x++
+	}
+}
+
+// WriteANSI draws s to w with box-drawing characters and ANSI colors. Every
+// cell is wide and tall enough for the grid of its candidates, which empty
+// cells show; region borders and the edge of the grid are heavy lines.
+// Clues, solved values, painted cells and clear cells each have their own
+// color.
+func WriteANSI(w io.Writer, s *Scene) error {
+	side := s.CandidateSide()
+	cw, ch := side, side
+	for _, row := range s.Cells {
+		for _, cell := range row {
+			cw = max(cw, len(strconv.Itoa(cell.Value)))
+		}
+	}
+	for _, runs := range s.TopRuns {
+		for _, v := range runs {
+			cw = max(cw, len(strconv.Itoa(v)))
+		}
+	}
+	marginW := 0
+	for _, clues := range [][]int{s.Top, s.Bottom, s.Left, s.Right} {
+		for _, v := range clues {
+			marginW = max(marginW, len(strconv.Itoa(v))+1)
+		}
+	}
+	left, right, top, bottom := 0, 0, 0, 0
+	if s.Left != nil {
+		left = marginW
+	}
+	if s.Right != nil {
+		right = marginW
+	}
+	if s.Top != nil {
+		top = 1
+	}
+	for _, runs := range s.LeftRuns {
+		left = max(left, len(ansiRuns(runs))+1)
+	}
+	top = max(top, RunDepth(s.TopRuns))
+	if s.Bottom != nil {
+		bottom = 1
+	}
+	c := newCanvas(left+s.W*(cw+1)+1+right, top+s.H*(ch+1)+1+bottom)
+
+	// vert and horiz give the weight of the line left of and above a cell.
+	vert := func(x, y int) int {
+		if y < 0 || y >= s.H {
+			return 0
+		}
+		if x == 0 || x == s.W || s.RegionEdge(grid.Coord{X: x - 1, Y: y}, grid.RIGHT) {
+			return 2
+		}
+		return 1
+	}
+	horiz := func(x, y int) int {
+		if x < 0 || x >= s.W {
+			return 0
+		}
+		if y == 0 || y == s.H || s.RegionEdge(grid.Coord{X: x, Y: y - 1}, grid.DOWN) {
+			return 2
+		}
+		return 1
+	}
+	lineStyle := func(weight int) string {
+		if weight == 2 {
+			return ansiPlain
+		}
+		return ansiThin
+	}
+	for j := 0; j <= s.H; j++ {
+		for i := 0; i <= s.W; i++ {
+			px, py := left+i*(cw+1), top+j*(ch+1)
+			up, rt, dn, lt := vert(i, j-1), horiz(i, j), vert(i, j), horiz(i-1, j)
+			c.put(px, py, string(boxChars[up*27+rt*9+dn*3+lt]), lineStyle(max(up, rt, dn, lt)))
+			if i < s.W {
+				c.put(px+1, py, strings.Repeat(string(boxChars[rt*9+rt]), cw), lineStyle(rt))
+			}
+			if j < s.H {
+				for k := 1; k <= ch; k++ {
+					c.put(px, py+k, string(boxChars[dn*27+dn*3]), lineStyle(dn))
+				}
+			}
+		}
+	}
+
+	for y, row := range s.Cells {
+		for x, cell := range row {
+			px, py := left+x*(cw+1)+1, top+y*(ch+1)+1
+			bg := ""
+			if cell.Highlight {
+				bg = ansiHighlight
+			}
+			for k := 0; k < ch; k++ {
+				c.put(px, py+k, strings.Repeat(" ", cw), bg)
+			}
+			switch {
+			case cell.Shade == grid.PAINTED:
+				for k := 0; k < ch; k++ {
+					c.put(px, py+k, strings.Repeat("█", cw), joinStyle(ansiPainted, bg))
+				}
+			case cell.Value != 0 || cell.Clue:
+				style := ansiSolved
+				if cell.Clue {
+					style = ansiClue
+				}
+				v := strconv.Itoa(cell.Value)
+				c.put(px+(cw-len(v)+1)/2, py+(ch-1)/2, v, joinStyle(style, bg))
+			case cell.Shade == grid.CLEAR:
+				c.put(px+(cw-1)/2, py+(ch-1)/2, "·", joinStyle(ansiClear, bg))
+			default:
+				for _, v := range cell.Candidates {
+					col, line := candidateSlot(v, side)
+					c.put(px+col, py+line, strconv.Itoa(v), joinStyle(ansiCandidate, bg))
+				}
+			}
+		}
+	}
+
+	for x := 0; x < s.W; x++ {
+		px := left + x*(cw+1) + 1
+		if s.Top != nil && s.Top[x] != 0 {
+			v := strconv.Itoa(s.Top[x])
+			c.put(px+(cw-len(v)+1)/2, 0, v, ansiClue)
+		}
+		if s.Bottom != nil && s.Bottom[x] != 0 {
+			v := strconv.Itoa(s.Bottom[x])
+			c.put(px+(cw-len(v)+1)/2, len(c)-1, v, ansiClue)
+		}
+	}
+	for y := 0; y < s.H; y++ {
+		py := top + y*(ch+1) + 1 + (ch-1)/2
+		if s.Left != nil && s.Left[y] != 0 {
+			v := strconv.Itoa(s.Left[y])
+			c.put(left-1-len(v), py, v, ansiClue)
+		}
+		if s.Right != nil && s.Right[y] != 0 {
+			c.put(len(c[py])-right+1, py, strconv.Itoa(s.Right[y]), ansiClue)
+		}
+	}
+
+	for x, runs := range s.TopRuns {
+		px := left + x*(cw+1) + 1
+		for i, n := range runs {
+			v := strconv.Itoa(n)
+			c.put(px+(cw-len(v)+1)/2, top-len(runs)+i, v, ansiClue)
+		}
+	}
+	for y, runs := range s.LeftRuns {
+		py := top + y*(ch+1) + 1 + (ch-1)/2
+		v := ansiRuns(runs)
+		c.put(left-1-len(v), py, v, ansiClue)
+	}
+
+	var sb strings.Builder
+	for _, row := range c {
+		style := ansiPlain
+		for _, sr := range row {
+			if sr.style != style {
+				if style != ansiPlain {
+					sb.WriteString("\x1b[0m")
+				}
+				if sr.style != ansiPlain {
+					sb.WriteString("\x1b[" + sr.style + "m")
+				}
+				style = sr.style
+			}
+			sb.WriteRune(sr.r)
+		}
+		if style != ansiPlain {
+			sb.WriteString("\x1b[0m")
+		}
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ansiRuns writes a row's runs as one clue, separated by spaces.
+func ansiRuns(runs []int) string {
+	out := make([]string, len(runs))
+	for i, v := range runs {
+		out[i] = strconv.Itoa(v)
+	}
+	return strings.Join(out, " ")
+}
+
+// joinStyle combines two SGR parameter lists, either of which may be empty.
+func joinStyle(a, b string) string {
+	if a == "" {
+		return b
+	} else if b == "" {
+		return a
+	}
+	return a + ";" + b
+}
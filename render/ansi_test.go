@@ -0,0 +1,46 @@
+package render
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var sgr = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestWriteANSI(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteANSI(&out, testScene()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(sgr.ReplaceAllString(out.String(), ""), "\n"), "\n")
+	// Candidates up to 3 take two by two characters, so each cell is two
+	// by two inside its border; the clues take one line above and two
+	// columns left of the grid.
+	if len(lines) != 1+2*3+1 {
+		t.Fatalf("drawing has %d lines; want 8\n%s", len(lines), out.String())
+	}
+	for i, l := range lines {
+		if n := utf8.RuneCountInString(l); n != 2+3*3+1 {
+			t.Errorf("line %d is %d characters wide; want 12: %q", i, n, l)
+		}
+	}
+	// One border line above each row of cells and one below the last.
+	borders := 0
+	for _, l := range lines {
+		if strings.ContainsAny(l, "┏┣┗┠┡┢") {
+			borders++
+		}
+	}
+	if borders != 2+1 {
+		t.Errorf("found %d border lines; want 3\n%s", borders, strings.Join(lines, "\n"))
+	}
+	if !strings.Contains(lines[0], "1") || !strings.Contains(lines[0], "2") {
+		t.Errorf("top clues missing from %q", lines[0])
+	}
+	if !strings.Contains(out.String(), "█") {
+		t.Error("no painted cell")
+	}
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+)
+
+// digitFont is a 5 by 7 bitmap of each digit, one byte per row with the
+// leftmost pixel in bit 4.
+var digitFont = [10][7]byte{
+	{0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e}, // 0
+	{0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e}, // 1
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f}, // 2
+	{0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e}, // 3
+	{0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02}, // 4
+	{0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e}, // 5
+	{0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e}, // 6
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08}, // 7
+	{0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e}, // 8
+	{0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c}, // 9
+}
+
+const (
+	glyphW = 5
+	glyphH = 7
+)
+
+// drawNumber draws n, which must not be negative, centered on (cx, cy)
+// with digits about height pixels tall and never less than one pixel per
+// font pixel.
+func drawNumber(img draw.Image, cx, cy, height int, c color.Color, n int) {
+	digits := []byte(strconv.Itoa(n))
+	scale := max(height/glyphH, 1)
+	w := len(digits)*(glyphW+1)*scale - scale
+	x0 := cx - w/2
+	y0 := cy - glyphH*scale/2
+	src := image.NewUniform(c)
+	for i, d := range digits {
+		glyph := digitFont[d-'0']
+		gx := x0 + i*(glyphW+1)*scale
+		for row := 0; row < glyphH; row++ {
+			for col := 0; col < glyphW; col++ {
+				if glyph[row]&(1<<(glyphW-1-col)) == 0 {
+					continue
+				}
+				r := image.Rect(gx+col*scale, y0+row*scale, gx+(col+1)*scale, y0+(row+1)*scale)
+				draw.Draw(img, r, src, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
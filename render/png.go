@@ -0,0 +1,187 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// PNGOptions controls PNG output. CellSize is the side of a cell in
+// pixels; Candidates turns on the small-digit candidate layer.
+type PNGOptions struct {
+	CellSize   int
+	Candidates bool
+}
+
+// DefaultPNGOptions are the options WritePNG uses for a zero CellSize.
+var DefaultPNGOptions = PNGOptions{CellSize: 32}
+
+// The colors of a PNG drawing.
+var (
+	pngPaper     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	pngInk       = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	pngGridLine  = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	pngSolved    = color.RGBA{0x1f, 0x4e, 0x9c, 0xff}
+	pngCandidate = color.RGBA{0x66, 0x66, 0x66, 0xff}
+	pngHighlight = color.RGBA{0xe6, 0x95, 0x00, 0xff}
+)
+
+// WritePNG draws s to w as a PNG image, with the puzzle's author and
+// source in the standard Author and Source text chunks.
+func WritePNG(w io.Writer, s *Scene, opts PNGOptions) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, DrawImage(s, opts)); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	// The text chunks go right after the signature and the IHDR chunk,
+	// which always come first and always take 33 bytes.
+	var text []byte
+	text = appendTextChunk(text, "Author", s.Meta.Author)
+	text = appendTextChunk(text, "Source", s.Meta.Source)
+	if _, err := w.Write(data[:33]); err != nil {
+		return err
+	}
+	if _, err := w.Write(text); err != nil {
+		return err
+	}
+	_, err := w.Write(data[33:])
+	return err
+}
+
+// appendTextChunk appends a PNG tEXt chunk holding keyword and text to b,
+// or nothing if text is empty. tEXt is Latin-1, so other characters are
+// written as "?".
+func appendTextChunk(b []byte, keyword, text string) []byte {
+	if len(text) == 0 {
+		return b
+	}
+	body := []byte("tEXt" + keyword + "\x00")
+	for _, r := range text {
+		if r > 0xff {
+			r = '?'
+		}
+		body = append(body, byte(r))
+	}
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)-4))
+	b = append(b, body...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(body))
+}
+
+// DrawImage draws s as an image, for callers that want to compose or encode
+// it themselves.
+func DrawImage(s *Scene, opts PNGOptions) *image.RGBA {
+	if opts.CellSize <= 0 {
+		opts.CellSize = DefaultPNGOptions.CellSize
+	}
+	cs := opts.CellSize
+	pad := cs / 4
+	margin := 0
+	if s.HasMargin() {
+		margin = cs
+	}
+	ox, oy := pad+max(margin, cs*RunDepth(s.LeftRuns)), pad+max(margin, cs*RunDepth(s.TopRuns))
+	img := image.NewRGBA(image.Rect(0, 0, ox+s.W*cs+pad+margin, oy+s.H*cs+pad+margin))
+	fill(img, img.Bounds(), pngPaper)
+	line := max(cs/16, 1)
+	thick := max(cs/12, 2)
+
+	side := s.CandidateSide()
+	for y, row := range s.Cells {
+		for x, cell := range row {
+			r := image.Rect(ox+x*cs, oy+y*cs, ox+(x+1)*cs, oy+(y+1)*cs)
+			mid := image.Point{X: r.Min.X + cs/2, Y: r.Min.Y + cs/2}
+			switch {
+			case cell.Shade == grid.PAINTED:
+				fill(img, r, pngInk)
+			case cell.Value != 0 || cell.Clue:
+				ink := pngSolved
+				if cell.Clue {
+					ink = pngInk
+				}
+				drawNumber(img, mid.X, mid.Y, cs*3/5, ink, cell.Value)
+			case cell.Shade == grid.CLEAR:
+				dot := max(cs/10, 1)
+				fill(img, image.Rect(mid.X-dot, mid.Y-dot, mid.X+dot, mid.Y+dot), pngGridLine)
+			case opts.Candidates:
+				sub := cs / side
+				for _, v := range cell.Candidates {
+					col, crow := candidateSlot(v, side)
+					drawNumber(img, r.Min.X+col*sub+sub/2, r.Min.Y+crow*sub+sub/2, sub*3/4, pngCandidate, v)
+				}
+			}
+			if cell.Highlight {
+				// A frame rather than a fill, so that it shows on painted
+				// cells too.
+				w := max(cs/10, 2)
+				outline(img, r.Inset(w), w, pngHighlight)
+			}
+		}
+	}
+
+	for x := 1; x < s.W; x++ {
+		fill(img, image.Rect(ox+x*cs, oy, ox+x*cs+line, oy+s.H*cs), pngGridLine)
+	}
+	for y := 1; y < s.H; y++ {
+		fill(img, image.Rect(ox, oy+y*cs, ox+s.W*cs, oy+y*cs+line), pngGridLine)
+	}
+	for c := (grid.Coord{X: 0, Y: 0}); c.Y < s.H; c = nextCell(s, c) {
+		if s.RegionEdge(c, grid.RIGHT) {
+			x := ox + (c.X+1)*cs
+			fill(img, image.Rect(x-thick/2, oy+c.Y*cs-thick/2, x+thick-thick/2, oy+(c.Y+1)*cs+thick-thick/2), pngInk)
+		}
+		if s.RegionEdge(c, grid.DOWN) {
+			y := oy + (c.Y+1)*cs
+			fill(img, image.Rect(ox+c.X*cs-thick/2, y-thick/2, ox+(c.X+1)*cs+thick-thick/2, y+thick-thick/2), pngInk)
+		}
+	}
+	outline(img, image.Rect(ox, oy, ox+s.W*cs, oy+s.H*cs), thick, pngInk)
+
+	for x := 0; x < s.W; x++ {
+		pngClue(img, s.Top, x, ox+x*cs+cs/2, oy-cs/2, cs)
+		pngClue(img, s.Bottom, x, ox+x*cs+cs/2, oy+s.H*cs+cs/2, cs)
+	}
+	for y := 0; y < s.H; y++ {
+		pngClue(img, s.Left, y, ox-cs/2, oy+y*cs+cs/2, cs)
+		pngClue(img, s.Right, y, ox+s.W*cs+cs/2, oy+y*cs+cs/2, cs)
+	}
+	// Runs, one cell-sized band per clue, the last next to the grid.
+	for x, runs := range s.TopRuns {
+		for i, v := range runs {
+			drawNumber(img, ox+x*cs+cs/2, oy-(len(runs)-i)*cs+cs/2, cs*3/5, pngInk, v)
+		}
+	}
+	for y, runs := range s.LeftRuns {
+		for i, v := range runs {
+			drawNumber(img, ox-(len(runs)-i)*cs+cs/2, oy+y*cs+cs/2, cs*3/5, pngInk, v)
+		}
+	}
+	return img
+}
+
+func pngClue(img draw.Image, side []int, i, x, y, cs int) {
+	if side == nil || side[i] == 0 {
+		return
+	}
+	drawNumber(img, x, y, cs*3/5, pngInk, side[i])
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// outline draws a frame of the given width centered on the edges of r.
+func outline(img draw.Image, r image.Rectangle, width int, c color.Color) {
+	in, out := width/2, width-width/2
+	fill(img, image.Rect(r.Min.X-in, r.Min.Y-in, r.Max.X+out, r.Min.Y+out), c)
+	fill(img, image.Rect(r.Min.X-in, r.Max.Y-in, r.Max.X+out, r.Max.Y+out), c)
+	fill(img, image.Rect(r.Min.X-in, r.Min.Y-in, r.Min.X+out, r.Max.Y+out), c)
+	fill(img, image.Rect(r.Max.X-in, r.Min.Y-in, r.Max.X+out, r.Max.Y+out), c)
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestWritePNG(t *testing.T) {
+	var out bytes.Buffer
+	if err := WritePNG(&out, testScene(), PNGOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("tEXtAuthor\x00Tester")) {
+		t.Error("no Author text chunk")
+	}
+	img, err := png.Decode(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatalf("output does not decode: %v", err)
+	}
+	// Cells of 32, a padding of 8 and a margin of one cell all around.
+	if b := img.Bounds(); b.Dx() != 8+32+3*32+32+8 || b.Dy() != 8+32+2*32+32+8 {
+		t.Errorf("size is %dx%d; want 176x144", b.Dx(), b.Dy())
+	}
+	if r, g, b, _ := img.At(40+16, 40+16).RGBA(); r|g|b != 0 {
+		t.Errorf("middle of the painted cell is %v; want black", img.At(56, 56))
+	}
+	if r, g, b, _ := img.At(40+32+8, 40+8).RGBA(); r&g&b != 0xffff {
+		t.Errorf("corner of the clear cell is %v; want white", img.At(80, 48))
+	}
+}
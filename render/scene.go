@@ -0,0 +1,143 @@
+// Package render draws puzzles as pictures. A puzzle type describes its
+// board as a Scene, and each renderer here turns a Scene into one output
+// format, so renderers know nothing about the rules of any puzzle.
+package render
+
+import (
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Scene is everything a renderer draws: a grid of cells, the regions they
+// form, and the clues in the margin around the grid. Meta is not drawn, but
+// formats that can hold it write it alongside the picture.
+type Scene struct {
+	W     int
+	H     int
+	Meta  board.Meta
+	Cells [][]Cell
+	// Regions numbers the region of each cell; borders between two
+	// regions are drawn thick. Nil if the puzzle has no regions.
+	Regions [][]int
+	// Top and Bottom hold a clue for each column and Left and Right one
+	// for each row, with 0 for no clue. A side is nil if it has no clues.
+	Top    []int
+	Bottom []int
+	Left   []int
+	Right  []int
+	// TopRuns and LeftRuns hold a list of clues for each column and row,
+	// for puzzles whose lines have more than one. A column's list is
+	// stacked above it and a row's written out to its left, both ending
+	// next to the grid. Either is nil if the puzzle has no such clues.
+	TopRuns  [][]int
+	LeftRuns [][]int
+}
+
+// Cell is one cell of a Scene. Shade is PAINTED or CLEAR on painted/clear
+// boards and UNKNOWN otherwise. Value is the number in the cell, if any,
+// and Clue tells a number that is part of the puzzle from one the solver
+// filled in; a clue is drawn even if it is 0. Candidates are the values an
+// empty cell may still take, on boards that keep them. Highlight picks the
+// cell out with a background color, for example because the last solver
+// step changed it.
+type Cell struct {
+	Shade      grid.Cell
+	Value      int
+	Clue       bool
+	Candidates []int
+	Highlight  bool
+}
+
+// Scener is a puzzle that can describe itself as a Scene.
+type Scener interface {
+	Scene() *Scene
+}
+
+// NewScene returns a w by h scene of empty cells.
+func NewScene(w, h int) *Scene {
+	s := &Scene{W: w, H: h, Cells: make([][]Cell, h)}
+	for y := range s.Cells {
+		s.Cells[y] = make([]Cell, w)
+	}
+	return s
+}
+
+// At returns the cell at c.
+func (s *Scene) At(c grid.Coord) *Cell {
+	return &s.Cells[c.Y][c.X]
+}
+
+// HasMargin reports whether any side of the scene holds clues.
+func (s *Scene) HasMargin() bool {
+	return s.Top != nil || s.Bottom != nil || s.Left != nil || s.Right != nil || s.TopRuns != nil || s.LeftRuns != nil
+}
+
+// RunDepth returns the most clues any line of runs holds, which is how many
+// cells deep the margin of runs must be.
+func RunDepth(runs [][]int) int {
+	depth := 0
+	for _, r := range runs {
+		depth = max(depth, len(r))
+	}
+	return depth
+}
+
+// MarkClues marks as a Clue every value of s that orig, a scene of the same
+// puzzle before it was solved, already had. Numeric boards do not remember
+// which of their values were given, so this is how a renderer tells them
+// apart.
+func (s *Scene) MarkClues(orig *Scene) {
+	for y := range s.Cells {
+		for x := range s.Cells[y] {
+			if orig.Cells[y][x].Value != 0 && orig.Cells[y][x].Value == s.Cells[y][x].Value {
+				s.Cells[y][x].Clue = true
+			}
+		}
+	}
+}
+
+// HighlightStep highlights every cell that step marked or removed a
+// candidate from.
+func (s *Scene) HighlightStep(step board.Step) {
+	for _, m := range step.Marked {
+		s.At(m.At).Highlight = true
+	}
+	for _, r := range step.Removed {
+		s.At(r.At).Highlight = true
+	}
+}
+
+// RegionEdge reports whether a thick border runs between c and its
+// neighbor in direction d; that is, whether they lie in different regions.
+// The edges of the grid are not region edges.
+func (s *Scene) RegionEdge(c grid.Coord, d grid.Delta) bool {
+	n := c.Plus(d)
+	if s.Regions == nil || n.X < 0 || n.Y < 0 || n.X >= s.W || n.Y >= s.H {
+		return false
+	}
+	return s.Regions[c.Y][c.X] != s.Regions[n.Y][n.X]
+}
+
+// CandidateSide returns the number of candidates drawn across each cell:
+// the smallest square that holds the largest candidate in the scene.
+func (s *Scene) CandidateSide() int {
+	most := 0
+	for _, row := range s.Cells {
+		for _, cell := range row {
+			for _, v := range cell.Candidates {
+				most = max(most, v)
+			}
+		}
+	}
+	side := 1
+	for side*side < most {
+		side++
+	}
+	return side
+}
+
+// candidateSlot returns the column and row at which candidate v is drawn
+// in a cell holding side by side candidates.
+func candidateSlot(v, side int) (int, int) {
+	return (v - 1) % side, (v - 1) / side
+}
@@ -0,0 +1,157 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// SVGOptions controls SVG output. CellSize is the side of a cell in
+// pixels; Candidates turns on the small-digit candidate layer.
+type SVGOptions struct {
+	CellSize   int
+	Candidates bool
+}
+
+// DefaultSVGOptions are the options WriteSVG uses for a zero CellSize.
+var DefaultSVGOptions = SVGOptions{CellSize: 40}
+
+// The colors of an SVG drawing.
+const (
+	svgInk       = "#000000"
+	svgGridLine  = "#999999"
+	svgSolved    = "#1f4e9c"
+	svgCandidate = "#666666"
+	svgClearDot  = "#999999"
+	svgHighlight = "#e69500"
+)
+
+// WriteSVG draws s to w as a standalone SVG document. The puzzle's source,
+// if known, is the document's title and its author the description.
+func WriteSVG(w io.Writer, s *Scene, opts SVGOptions) error {
+	if opts.CellSize <= 0 {
+		opts.CellSize = DefaultSVGOptions.CellSize
+	}
+	cs := opts.CellSize
+	pad := cs / 4
+	margin := 0
+	if s.HasMargin() {
+		margin = cs
+	}
+	ox, oy := pad+max(margin, cs*RunDepth(s.LeftRuns)), pad+max(margin, cs*RunDepth(s.TopRuns))
+	width := ox + s.W*cs + pad + margin
+	height := oy + s.H*cs + pad + margin
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	if len(s.Meta.Source) > 0 {
+		fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(s.Meta.Source))
+	}
+	if len(s.Meta.Author) > 0 {
+		fmt.Fprintf(&sb, "<desc>by %s</desc>\n", html.EscapeString(s.Meta.Author))
+	}
+	fmt.Fprintf(&sb, "<rect width=\"%d\" height=\"%d\" fill=\"#ffffff\"/>\n", width, height)
+
+	// Cell contents, then the lines over them.
+	side := s.CandidateSide()
+	for y, row := range s.Cells {
+		for x, cell := range row {
+			cx, cy := ox+x*cs, oy+y*cs
+			switch {
+			case cell.Shade == grid.PAINTED:
+				fmt.Fprintf(&sb, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", cx, cy, cs, cs, svgInk)
+			case cell.Value != 0 || cell.Clue:
+				color, weight := svgSolved, "normal"
+				if cell.Clue {
+					color, weight = svgInk, "bold"
+				}
+				svgText(&sb, cx+cs/2, cy+cs/2, cs*3/5, color, weight, fmt.Sprint(cell.Value))
+			case cell.Shade == grid.CLEAR:
+				fmt.Fprintf(&sb, "<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"%s\"/>\n", cx+cs/2, cy+cs/2, max(cs/12, 1), svgClearDot)
+			case opts.Candidates:
+				sub := cs / side
+				for _, v := range cell.Candidates {
+					col, line := candidateSlot(v, side)
+					svgText(&sb, cx+col*sub+sub/2, cy+line*sub+sub/2, sub*3/4, svgCandidate, "normal", fmt.Sprint(v))
+				}
+			}
+			if cell.Highlight {
+				// A frame rather than a fill, so that it shows on painted
+				// cells too.
+				w := max(cs/10, 2)
+				fmt.Fprintf(&sb, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" stroke=\"%s\" stroke-width=\"%d\" fill=\"none\"/>\n", cx+w, cy+w, cs-2*w, cs-2*w, svgHighlight, w)
+			}
+		}
+	}
+
+	var thin, thick strings.Builder
+	for x := 1; x < s.W; x++ {
+		fmt.Fprintf(&thin, "M%d %dV%d", ox+x*cs, oy, oy+s.H*cs)
+	}
+	for y := 1; y < s.H; y++ {
+		fmt.Fprintf(&thin, "M%d %dH%d", ox, oy+y*cs, ox+s.W*cs)
+	}
+	for c := (grid.Coord{X: 0, Y: 0}); c.Y < s.H; c = nextCell(s, c) {
+		if s.RegionEdge(c, grid.RIGHT) {
+			fmt.Fprintf(&thick, "M%d %dV%d", ox+(c.X+1)*cs, oy+c.Y*cs, oy+(c.Y+1)*cs)
+		}
+		if s.RegionEdge(c, grid.DOWN) {
+			fmt.Fprintf(&thick, "M%d %dH%d", ox+c.X*cs, oy+(c.Y+1)*cs, ox+(c.X+1)*cs)
+		}
+	}
+	if thin.Len() > 0 {
+		fmt.Fprintf(&sb, "<path d=\"%s\" stroke=\"%s\" stroke-width=\"1\" fill=\"none\"/>\n", thin.String(), svgGridLine)
+	}
+	if thick.Len() > 0 {
+		fmt.Fprintf(&sb, "<path d=\"%s\" stroke=\"%s\" stroke-width=\"%d\" stroke-linecap=\"square\" fill=\"none\"/>\n", thick.String(), svgInk, max(cs/12, 2))
+	}
+	fmt.Fprintf(&sb, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" stroke=\"%s\" stroke-width=\"%d\" fill=\"none\"/>\n", ox, oy, s.W*cs, s.H*cs, svgInk, max(cs/12, 2))
+
+	// Margin clues, centered in the cell-sized band around the grid.
+	for x := 0; x < s.W; x++ {
+		svgClue(&sb, s.Top, x, ox+x*cs+cs/2, oy-cs/2, cs)
+		svgClue(&sb, s.Bottom, x, ox+x*cs+cs/2, oy+s.H*cs+cs/2, cs)
+	}
+	for y := 0; y < s.H; y++ {
+		svgClue(&sb, s.Left, y, ox-cs/2, oy+y*cs+cs/2, cs)
+		svgClue(&sb, s.Right, y, ox+s.W*cs+cs/2, oy+y*cs+cs/2, cs)
+	}
+	// Runs, one cell-sized band per clue, the last next to the grid.
+	for x, runs := range s.TopRuns {
+		for i, v := range runs {
+			svgText(&sb, ox+x*cs+cs/2, oy-(len(runs)-i)*cs+cs/2, cs*3/5, svgInk, "normal", fmt.Sprint(v))
+		}
+	}
+	for y, runs := range s.LeftRuns {
+		for i, v := range runs {
+			svgText(&sb, ox-(len(runs)-i)*cs+cs/2, oy+y*cs+cs/2, cs*3/5, svgInk, "normal", fmt.Sprint(v))
+		}
+	}
+	sb.WriteString("</svg>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// nextCell steps through the cells of s in reading order.
+func nextCell(s *Scene, c grid.Coord) grid.Coord {
+	c.X++
+	if c.X == s.W {
+		c.X = 0
+		c.Y++
+	}
+	return c
+}
+
+func svgClue(sb *strings.Builder, side []int, i, x, y, cs int) {
+	if side == nil || side[i] == 0 {
+		return
+	}
+	svgText(sb, x, y, cs*3/5, svgInk, "normal", fmt.Sprint(side[i]))
+}
+
+func svgText(sb *strings.Builder, x, y, size int, color, weight, text string) {
+	fmt.Fprintf(sb, "<text x=\"%d\" y=\"%d\" font-family=\"sans-serif\" font-size=\"%d\" font-weight=\"%s\" fill=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\">%s</text>\n", x, y, size, weight, color, text)
+}
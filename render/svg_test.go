@@ -0,0 +1,64 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// testScene returns a three by two scene with one of everything the
+// renderers draw: a painted cell, a clear one, a clue, a solved value,
+// candidates, two regions and clues above and left of the grid.
+func testScene() *Scene {
+	s := NewScene(3, 2)
+	s.Meta = board.Meta{Author: "Tester", Source: "render_test"}
+	s.Regions = [][]int{{0, 0, 1}, {0, 1, 1}}
+	s.Top = []int{1, 0, 2}
+	s.Left = []int{3, 0}
+	s.At(grid.Coord{X: 0, Y: 0}).Shade = grid.PAINTED
+	s.At(grid.Coord{X: 1, Y: 0}).Shade = grid.CLEAR
+	*s.At(grid.Coord{X: 2, Y: 0}) = Cell{Value: 2, Clue: true}
+	s.At(grid.Coord{X: 0, Y: 1}).Value = 1
+	s.At(grid.Coord{X: 1, Y: 1}).Candidates = []int{1, 2, 3}
+	s.At(grid.Coord{X: 2, Y: 1}).Highlight = true
+	return s
+}
+
+func TestWriteSVG(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteSVG(&out, testScene(), SVGOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"svg"`
+		Width   int      `xml:"width,attr"`
+		Height  int      `xml:"height,attr"`
+		Title   string   `xml:"title"`
+		Rects   []struct {
+			Fill string `xml:"fill,attr"`
+		} `xml:"rect"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("output does not parse: %v\n%s", err, out.String())
+	}
+	// Cells of 40, a padding of 10 and, since the scene has clues, a
+	// margin of one cell all around.
+	if doc.Width != 10+40+3*40+40+10 || doc.Height != 10+40+2*40+40+10 {
+		t.Errorf("size is %dx%d; want 220x180", doc.Width, doc.Height)
+	}
+	if doc.Title != "render_test" {
+		t.Errorf("title is %q; want the source", doc.Title)
+	}
+	painted := 0
+	for _, r := range doc.Rects {
+		if r.Fill == svgInk {
+			painted++
+		}
+	}
+	if painted != 1 {
+		t.Errorf("found %d painted cells; want 1", painted)
+	}
+}
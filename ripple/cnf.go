@@ -0,0 +1,84 @@
+package ripple
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable cell(x,y)=v is true when
+// that cell holds v, for v up to the size of its region; every cell holds
+// one value, every region holds each of its values once, and given cells
+// are unit clauses. Two cells in the same row or column that are v or fewer
+// cells apart cannot both hold v.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, vars := b.encode()
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for v := 1; v < len(vars[c.Y][c.X]); v++ {
+			if !m.Value(vars[c.Y][c.X][v]) {
+				continue
+			}
+			if _, err := b.Set(c, v); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// encode builds the formula CNF returns, along with the variables of every
+// cell, indexed by value from 1.
+func (b *Board) encode() (*cnf.Formula, [][][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d", TypeName, b.W, b.H)}
+	vars := make([][][]int, b.H)
+	for y := range vars {
+		vars[y] = make([][]int, b.W)
+		for x := range vars[y] {
+			size := len(*b.RegionGrid[y][x][0])
+			vars[y][x] = make([]int, size+1)
+			for v := 1; v <= size; v++ {
+				vars[y][x][v] = f.NewVar(fmt.Sprintf("cell(%d,%d)=%d", x, y, v))
+			}
+			f.ExactlyOne(vars[y][x][1:])
+			if v := b.Get(grid.Coord{X: x, Y: y}); v != grid.UNKNOWN {
+				if v >= len(vars[y][x]) {
+					f.Add()
+				} else {
+					f.Add(vars[y][x][v])
+				}
+			}
+		}
+	}
+	for _, r := range b.AllRegions {
+		for v := 1; v <= len(*r); v++ {
+			lits := make([]int, 0, len(*r))
+			for _, c := range *r {
+				lits = append(lits, vars[c.Y][c.X][v])
+			}
+			f.ExactlyOne(lits)
+		}
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for v := 1; v < len(vars[c.Y][c.X]); v++ {
+			for _, dir := range []grid.Delta{grid.RIGHT, grid.DOWN} {
+				for k := 1; k <= v; k++ {
+					n := c.Plus(dir.Times(k))
+					if b.IsValid(n) && v < len(vars[n.Y][n.X]) {
+						f.Add(-vars[c.Y][c.X][v], -vars[n.Y][n.X][v])
+					}
+				}
+			}
+		}
+	}
+	return f, vars
+}
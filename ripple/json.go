@@ -0,0 +1,72 @@
+package ripple
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// BoardFromDocument reads a puzzle from its regions and given cells, then
+// narrows the candidates to any the document holds.
+func BoardFromDocument(d *puzzle.Document) (*Board, error) {
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	if len(d.Regions) == 0 {
+		return nil, fmt.Errorf("document has no regions")
+	}
+	allRegions, regionGrid := board.IDsToRegionGrid(d.Regions)
+	numgrid := grid.MakeNumGrid(d.Width, d.Height)
+	for y, row := range d.Cells {
+		for x, v := range row {
+			if sz := len(*regionGrid[y][x][0]); v < 0 || v > sz {
+				return nil, fmt.Errorf("cell (%d,%d) has value %d; its region has %d cells", x, y, v, sz)
+			}
+		}
+		copy(numgrid[y], row)
+	}
+	b, err := newBoard(allRegions, regionGrid, numgrid)
+	if err != nil {
+		return nil, err
+	}
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	if len(d.Candidates) > 0 {
+		if err := b.RestrictCandidates(d.Candidates); err != nil {
+			return nil, err
+		}
+	}
+	b.Meta = board.Meta{Author: d.Author, Source: d.Source}
+	return b, nil
+}
+
+// Document returns the puzzle with its current cells and candidates.
+// Regions are numbered from 0 in reading order of their first cell.
+func (b *Board) Document() *puzzle.Document {
+	ids := make(map[*[]grid.Coord]int)
+	regions := grid.MakeNumGrid(b.W, b.H)
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		r := b.RegionGrid[c.Y][c.X][0]
+		id, ok := ids[r]
+		if !ok {
+			id = len(ids)
+			ids[r] = id
+		}
+		regions[c.Y][c.X] = id
+	}
+	d := &puzzle.Document{
+		Type:       TypeName,
+		Width:      b.W,
+		Height:     b.H,
+		Author:     b.Meta.Author,
+		Source:     b.Meta.Source,
+		Regions:    regions,
+		Cells:      b.Cells(),
+		Candidates: b.Candidates(),
+	}
+	d.Solved, _ = b.IsSolved()
+	return d
+}
@@ -0,0 +1,100 @@
+package ripple
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place:
+// a missing separator, region map lines of different widths or with blank
+// cells, regions in more than one piece, givens sections of the wrong
+// shape, givens too large for their regions, a number given twice in one
+// region, and two equal givens n no more than n cells apart in a row or
+// column. A puzz.link URL is left to BoardFromURL.
+func Lint(lines []string) []error {
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return nil
+	}
+	regions, givens, givensAt, ok := board.SplitSections(lines)
+	if !ok {
+		return []error{fmt.Errorf("must have a region map and a grid of givens, split by a line of dashes; have %d lines and no separator", len(lines))}
+	}
+	if len(regions) == 0 {
+		return []error{grid.LineErrorf(1, 0, "region map is empty")}
+	}
+	errs := make([]error, 0)
+	w := len([]rune(regions[0]))
+	for y, l := range regions {
+		row := []rune(l)
+		if len(row) != w {
+			errs = append(errs, grid.LineErrorf(y+1, 0, "region map line has %d cells; the first has %d", len(row), w))
+		}
+		for x, ch := range row {
+			if board.IsBlank(ch) {
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "region map cell %q names no region", ch))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		// Regions cannot be traced through a ragged or holed map.
+		return errs
+	}
+	allRegions, regionGrid := board.LinesToRegionGrid(regions)
+	for _, r := range allRegions {
+		if cut, from, ok := board.SplitRegion(*r, regionGrid); ok {
+			errs = append(errs, grid.LineErrorf(cut.Y+1, cut.X+1, "region %q is in more than one piece; this cell is cut off from line %d, column %d", []rune(regions[cut.Y])[cut.X], from.Y+1, from.X+1))
+		}
+	}
+
+	if len(givens) != len(regions) {
+		return append(errs, grid.LineErrorf(givensAt+1, 0, "givens have %d rows; the region map has %d (write an empty row as a line of dots)", len(givens), len(regions)))
+	}
+	nums, err := grid.LinesToNumGrid(givens)
+	if err != nil {
+		return append(errs, grid.ShiftLines(err, givensAt))
+	}
+	givenAt := func(x, y int) int {
+		if x < 0 || y < 0 || y >= len(nums) || x >= min(w, len(nums[y])) {
+			return 0
+		}
+		return nums[y][x]
+	}
+	seen := make(map[*[]grid.Coord]map[int]grid.Coord)
+	for y, row := range nums {
+		if len(row) > w {
+			errs = append(errs, grid.LineErrorf(givensAt+y+1, 0, "givens line has %d cells; the region map has %d", len(row), w))
+		}
+		for x := 0; x < min(w, len(row)); x++ {
+			v := row[x]
+			if v == 0 {
+				continue
+			}
+			r := regionGrid[y][x][0]
+			if size := largestValue(regionGrid[y][x]); v > size {
+				errs = append(errs, grid.LineErrorf(givensAt+y+1, x+1, "given %d does not fit its region of %d cells", v, size))
+			}
+			if seen[r] == nil {
+				seen[r] = make(map[int]grid.Coord)
+			}
+			if c, ok := seen[r][v]; ok {
+				errs = append(errs, grid.LineErrorf(givensAt+y+1, x+1, "given %d is already in this region at line %d, column %d", v, givensAt+c.Y+1, c.X+1))
+			} else {
+				seen[r][v] = grid.Coord{X: x, Y: y}
+			}
+			// Looking right and down only reports each pair once.
+			for _, dir := range []grid.Delta{grid.RIGHT, grid.DOWN} {
+				for d := 1; d <= v; d++ {
+					n := grid.Coord{X: x, Y: y}.Plus(dir.Times(d))
+					if givenAt(n.X, n.Y) == v {
+						errs = append(errs, grid.LineErrorf(givensAt+n.Y+1, n.X+1, "given %d is %d away from the %d at line %d, column %d; equal givens n must be more than n apart", v, d, v, givensAt+y+1, x+1))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
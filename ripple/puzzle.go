@@ -0,0 +1,96 @@
+package ripple
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// TypeName is the name the puzzle type is registered under.
+const TypeName = "regions"
+
+func init() {
+	puzzle.Register(puzzle.Type{
+		Name:        TypeName,
+		Description: "ripple effect: fill each region with 1 to its size, keeping equal numbers n at least n cells apart",
+		Pzpr:        "ripple",
+		New:         func() puzzle.Puzzle { return &puzzleAdapter{} },
+		Detect:      looksLike,
+	})
+}
+
+// puzzleAdapter adapts Board to the puzzle.Puzzle interface.
+type puzzleAdapter struct {
+	*Board
+}
+
+// Parse reads a puzzle in the text format of BoardFromLines, after an
+// optional puzzle.Header.
+func (p *puzzleAdapter) Parse(lines []string) (err error) {
+	p.Board, err = puzzle.ParseText(TypeName, lines, BoardFromLines)
+	return err
+}
+
+func (p *puzzleAdapter) ParseDocument(d *puzzle.Document) (err error) {
+	p.Board, err = BoardFromDocument(d)
+	return err
+}
+
+func (p *puzzleAdapter) Clone() puzzle.Puzzle {
+	return &puzzleAdapter{p.Board.Clone()}
+}
+
+func (p *puzzleAdapter) SearchContext(ctx context.Context) (bool, error) {
+	return board.SearchNum(ctx, p.Board)
+}
+
+func (p *puzzleAdapter) CountSolutionsContext(ctx context.Context, limit int) (int, []puzzle.Puzzle, error) {
+	n, witnesses, err := board.CountNum(ctx, p.Board, limit)
+	return n, puzzle.ToPuzzles(witnesses, func(b *Board) puzzle.Puzzle { return &puzzleAdapter{b} }), err
+}
+
+func (p *puzzleAdapter) ApplyState(lines []string) error {
+	return board.ApplyNumState(p.Board, lines)
+}
+
+func (p *puzzleAdapter) Hint() (*board.Hint, error) {
+	return board.HintNum(p.Board)
+}
+
+func (p *puzzleAdapter) RateContext(ctx context.Context) (*board.Rating, error) {
+	return board.RateNum(ctx, p.Board)
+}
+
+// Lint lists the problems in a text puzzle after an optional
+// puzzle.Header; see the package function Lint.
+func (p *puzzleAdapter) Lint(lines []string) []error {
+	return puzzle.LintText(lines, Lint)
+}
+
+// looksLike reports whether lines could be a ripple puzzle: a region map
+// of equal-length lines with no blanks, followed by a separator or by as
+// many lines of givens. Without a separator, the givens must have a blank
+// somewhere, or the lines could as well be a grid of numbers. A comma in
+// the region map makes it a nonogram's row runs instead.
+func looksLike(lines []string) bool {
+	regions, givens, _, ok := board.SplitSections(lines)
+	if !ok || len(regions) == 0 {
+		return false
+	}
+	for _, l := range regions {
+		if len(l) != len(regions[0]) || strings.ContainsAny(l, " _.,") {
+			return false
+		}
+	}
+	if len(regions)+len(givens) < len(lines) {
+		return true
+	}
+	for _, l := range givens {
+		if len(l) < len(regions[0]) || strings.ContainsAny(l, " _.") {
+			return true
+		}
+	}
+	return false
+}
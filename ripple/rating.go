@@ -0,0 +1,12 @@
+package ripple
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the ripple effect rules in increasing order of
+// difficulty.
+func (b *Board) Techniques() []board.Technique {
+	out := []board.Technique{
+		{Name: "MarkMandatory", Cost: 1, Apply: b.MarkMandatory},
+	}
+	return append(out, b.SetTechniques()...)
+}
@@ -0,0 +1,27 @@
+package ripple
+
+import (
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/render"
+)
+
+// Scene describes the board for the renderers: the values and candidates of
+// the cells, and the regions from AllRegions.
+func (b *Board) Scene() *render.Scene {
+	s := render.NewScene(b.W, b.H)
+	s.Meta = b.Meta
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		cell := s.At(c)
+		cell.Value = b.Get(c)
+		if cell.Value == grid.UNKNOWN {
+			cell.Candidates = b.Allowed[c.Y][c.X].Sorted()
+		}
+	}
+	s.Regions = grid.MakeNumGrid(b.W, b.H)
+	for i, r := range b.AllRegions {
+		for _, c := range *r {
+			s.Regions[c.Y][c.X] = i
+		}
+	}
+	return s
+}
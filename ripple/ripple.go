@@ -0,0 +1,261 @@
+// Package ripple solves ripple effect puzzles. Each region of n cells holds
+// the numbers 1 to n, and two equal numbers k in the same row or column must
+// have at least k cells between them.
+package ripple
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+	"github.com/bismuthsalamander/mutantcheckerboard/set"
+)
+
+// Board is a ripple effect puzzle.
+type Board struct {
+	board.RectNumBoard
+}
+
+// BoardFromLines reads a puzzle in two sections, split by a line of dashes
+// or an empty line. First comes the region map, in which every character
+// names the region its cell belongs to; then the givens, one character or
+// one token (see grid.IsTokenGrid) per cell, with anything that is not a
+// number an empty cell:
+//
+//	aabb
+//	aabb
+//	ccdd
+//	ccdd
+//	----
+//	1
+//	   2
+//	 3
+//	2
+//
+// Givens lines may stop short, but the sections must have the same number
+// of rows and the givens no more columns than the map. Every region must
+// be in one piece and every given must fit its region. Without a
+// separator, the first half of the lines is the map and the second half
+// the givens. A single line holding a puzz.link URL is read with
+// BoardFromURL. Every problem Lint finds is returned, joined with
+// errors.Join.
+func BoardFromLines(input []string) (*Board, error) {
+	if len(input) == 1 && pzpr.IsURL(input[0]) {
+		return BoardFromURL(input[0])
+	}
+	if errs := Lint(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	regions, givens, _, _ := board.SplitSections(input)
+	allRegions, regionGrid := board.LinesToRegionGrid(regions)
+	nums, err := grid.LinesToNumGrid(givens)
+	if err != nil {
+		return nil, err
+	}
+	numgrid := grid.MakeNumGrid(len([]rune(regions[0])), len(regions))
+	for y, row := range nums {
+		copy(numgrid[y], row)
+	}
+	return newBoard(allRegions, regionGrid, numgrid)
+}
+
+// Lines returns the puzzle's regions and filled cells in the text format
+// BoardFromLines reads. Every filled cell is written as a given, as in URL.
+func (b *Board) Lines() []string {
+	d := b.Document()
+	lines := board.IDsToLines(d.Regions)
+	lines = append(lines, strings.Repeat("-", b.W))
+	return append(lines, grid.NumGridToLines(d.Cells, grid.UNKNOWN)...)
+}
+
+// newBoard returns a board with the given regions and givens, with every
+// candidate that the givens leave open.
+func newBoard(allRegions []*[]grid.Coord, regionGrid [][][]*[]grid.Coord, numgrid [][]int) (*Board, error) {
+	rect := board.RectNumBoardFromNums(numgrid)
+	b := Board{
+		RectNumBoard: *rect,
+	}
+	b.AllRegions = allRegions
+	b.RegionGrid = regionGrid
+	b.Allowed = make([][]*set.Set[int], b.H)
+	for y := 0; y < b.H; y++ {
+		b.Allowed[y] = make([]*set.Set[int], b.W)
+		for x := 0; x < b.W; x++ {
+			b.Allowed[y][x] = set.NewNumSet(largestValue(b.RegionGrid[y][x]))
+		}
+	}
+	b.Inited = true
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if v := b.Get(c); v != grid.UNKNOWN {
+			b.AllowOnly(c, v)
+		}
+		if _, err := b.PostMark(c, b.Get(c)); err != nil {
+			return nil, err
+		}
+	}
+	return &b, nil
+}
+
+// largestValue returns the largest number a cell in the given regions can
+// hold: the size of the smallest of them.
+func largestValue(regions []*[]grid.Coord) int {
+	n := 0
+	for i, r := range regions {
+		if i == 0 || len(*r) < n {
+			n = len(*r)
+		}
+	}
+	return n
+}
+
+func (b *Board) PostMark(c grid.Coord, v int) (bool, error) {
+	if v == grid.UNKNOWN {
+		return false, nil
+	}
+	changed := false
+	for _, region := range b.RegionGrid[c.Y][c.X] {
+		for _, neighbor := range *region {
+			if neighbor == c || !b.Allowed[neighbor.Y][neighbor.X].Has(v) {
+				continue
+			}
+			if _, err := b.Eliminate(neighbor, v, "PostMark"); err != nil {
+				return changed, err
+			}
+			changed = true
+		}
+	}
+	for _, dir := range grid.DIRECTIONS {
+		for i := 1; i <= v; i++ {
+			n := c.Plus(dir.Times(i))
+			if !b.IsValid(n) {
+				continue
+			}
+			ok, err := b.Eliminate(n, v, "PostMark")
+			if err != nil {
+				return changed, err
+			}
+			if ok {
+				changed = true
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// Clone returns a copy of the board that can be solved or guessed on without
+// affecting the original.
+func (b *Board) Clone() *Board {
+	return &Board{
+		RectNumBoard: *b.RectNumBoard.Clone(),
+	}
+}
+
+// Validate returns an error if the board's current contents already break a
+// rule: a region contradiction, or two equal numbers n in the same row or
+// column with fewer than n cells between them.
+func (b *Board) Validate() error {
+	if err := b.RectNumBoard.Validate(); err != nil {
+		return err
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		v := b.Get(c)
+		if v == grid.UNKNOWN {
+			continue
+		}
+		for _, dir := range []grid.Delta{grid.RIGHT, grid.DOWN} {
+			for i := 1; i <= v; i++ {
+				n := c.Plus(dir.Times(i))
+				if b.IsValid(n) && b.Get(n) == v {
+					return board.Contradiction(n, "Validate", "cell holds %d, as does %s only %d cells away", v, c, i)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// String draws the grid, with 0 for empty cells. A region of more than 35
+// cells switches every cell to the wide grid.CellFormat.
+func (b *Board) String() string {
+	largest := 0
+	for _, r := range b.AllRegions {
+		largest = max(largest, len(*r))
+	}
+	f := grid.FormatFor(largest)
+	out := ""
+	for ri := 0; ri < b.H; ri++ {
+		for ci := 0; ci < b.W; ci++ {
+			out += f.Num(b.Get(grid.Coord{X: ci, Y: ri}))
+		}
+		out += "\n"
+	}
+	return out
+}
+
+// IsSolved returns true iff every cell is filled, every region holds 1..n
+// and no two equal numbers are too close.
+func (b *Board) IsSolved() (bool, error) {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Get(c) == grid.UNKNOWN {
+			return false, fmt.Errorf("cell %s is unknown", c)
+		}
+	}
+	for _, r := range b.AllRegions {
+		if !b.IsRegionSolved(*r) {
+			return false, fmt.Errorf("region %v unsatisfied", *r)
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Solve runs all implemented solving heuristics until the puzzle is solved
+// or we run out of improvements. Missing heuristics include the opposite of
+// naked sets (i.e., cells X and Y are the only possible locations for numbers
+// N and M, so X and Y can't have any other numbers) and pairwise permutation
+// consistency between rows or columns.
+//
+// Returns a ContradictionError if the board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	changed := true
+	for changed {
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		if done, _ := b.IsComplete(); done {
+			break
+		}
+		changed = false
+		for _, rule := range []func() (bool, error){
+			b.MarkMandatory,
+			func() (bool, error) { return b.TrimAllFoundGroupsContext(ctx) },
+			func() (bool, error) { return b.TrimAllNakedSetsContext(ctx) },
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			ok, err := rule()
+			if err != nil {
+				return err
+			}
+			if ok {
+				changed = true
+			}
+		}
+	}
+	return b.Validate()
+}
@@ -0,0 +1,88 @@
+package ripple
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+func TestBoardFromLines(t *testing.T) {
+	b, err := BoardFromLines([]string{"abb", "acb", "acb", "---", "...", "...", "..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Region b has four cells on a three by three board, so its cells may
+	// hold a 4.
+	for _, tt := range []struct {
+		at   grid.Coord
+		want []int
+	}{
+		{grid.Coord{X: 0, Y: 0}, []int{1, 2, 3}},
+		{grid.Coord{X: 1, Y: 0}, []int{1, 2, 3, 4}},
+		{grid.Coord{X: 2, Y: 2}, []int{1, 2, 3, 4}},
+		{grid.Coord{X: 1, Y: 2}, []int{1, 2}},
+	} {
+		if got := b.Allowed[tt.at.Y][tt.at.X].Sorted(); !slices.Equal(got, tt.want) {
+			t.Errorf("candidates of %s are %v; want %v", tt.at, got, tt.want)
+		}
+	}
+
+	b, err = BoardFromLines([]string{"abb", "acb", "acb", "---", ".4.", "...", "..."})
+	if err != nil {
+		t.Fatalf("a 4 in a region of four cells: %v", err)
+	}
+	if got := b.Get(grid.Coord{X: 1, Y: 0}); got != 4 {
+		t.Errorf("given reads as %d; want 4", got)
+	}
+	if got := b.Allowed[0][1].Sorted(); !slices.Equal(got, []int{4}) {
+		t.Errorf("candidates of the given are %v; want [4]", got)
+	}
+}
+
+func TestBoardFromLinesErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		lines     []string
+		line, col int
+	}{
+		{"too large for its region", []string{"abb", "acb", "acb", "---", "...", ".3.", "..."}, 6, 2},
+		{"too large for a column", []string{"ab", "ab", "---", "..", "3."}, 5, 1},
+		{"split region", []string{"aba", "bbb", "---", "...", "..."}, 1, 3},
+		{"repeated in region", []string{"aab", "aab", "---", "1..", ".1."}, 5, 2},
+		{"too close", []string{"abc", "abc", "---", "2.2", "..."}, 4, 3},
+		{"short givens", []string{"ab", "ab", "---", ".."}, 4, 0},
+		{"ragged map", []string{"ab", "abc", "---", "..", ".."}, 2, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := Lint(tt.lines); len(errs) == 0 {
+				t.Errorf("Lint found no problems")
+			}
+			_, err := BoardFromLines(tt.lines)
+			var le *grid.LineError
+			if !errors.As(err, &le) || le.Line != tt.line || le.Col != tt.col {
+				t.Errorf("BoardFromLines(%q) = %v; want an error at line %d, column %d", tt.lines, err, tt.line, tt.col)
+			}
+		})
+	}
+}
+
+// TestLintAgreesWithLoad checks that every puzzle Lint passes loads, since
+// Lint and newBoard must bound the givens the same way.
+func TestLintAgreesWithLoad(t *testing.T) {
+	for _, lines := range [][]string{
+		{"abb", "acb", "acb", "---", "...", "...", "..4"},
+		{"abb", "acb", "acb", "---", "...", "...", "3.."},
+		{"aaaa", "---", "4..1"},
+		{"a", "b", "c", "d", "---", ".", ".", ".", "."},
+	} {
+		if errs := Lint(lines); len(errs) > 0 {
+			t.Errorf("Lint(%q) = %v", lines, errs)
+			continue
+		}
+		if _, err := BoardFromLines(lines); err != nil {
+			t.Errorf("Lint passes %q, but BoardFromLines says %v", lines, err)
+		}
+	}
+}
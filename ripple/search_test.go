@@ -0,0 +1,39 @@
+package ripple
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckNum(t, BoardFromLines, []boardtest.Case{
+		{Name: "region larger than the board", Lines: []string{"abb", "acb", "acb", "---", "...", "...", "..."}},
+		{Name: "one region", Lines: []string{"aa", "aa", "--", "..", ".."}},
+		{Name: "rows", Lines: []string{"aaa", "bbb", "ccc", "---", "...", "...", "..."}},
+		{Name: "unique", Lines: []string{"aab", "acb", "ccb", "---", "1..", "...", "..."}},
+		{Name: "no solution", Lines: []string{"abc", "---", "..."}},
+	})
+}
+
+// TestCountSolutions pins the count for a region with more cells than the
+// board is wide, which used to lose its largest values, and checks that the
+// limit the unique mode uses stops at two distinct solutions.
+func TestCountSolutions(t *testing.T) {
+	b, err := BoardFromLines([]string{"abb", "acb", "acb", "---", "...", "...", "..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _, err := board.CountNum(context.Background(), b, 0); n != 18 || err != nil {
+		t.Errorf("found %d solutions (%v); want 18", n, err)
+	}
+	n, witnesses, err := board.CountNum(context.Background(), b, 2)
+	if n != 2 || err != nil {
+		t.Fatalf("found %d solutions with a limit of 2 (%v); want 2", n, err)
+	}
+	if witnesses[0].String() == witnesses[1].String() {
+		t.Errorf("both witnesses are\n%s", witnesses[0])
+	}
+}
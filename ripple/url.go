@@ -0,0 +1,67 @@
+package ripple
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// BoardFromURL reads a puzzle from a pzprjs "ripple" URL: the region
+// borders followed by the givens.
+func BoardFromURL(s string) (*Board, error) {
+	u, err := pzpr.ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "ripple" {
+		return nil, fmt.Errorf("puzzle URL is for %q, not ripple", u.Type)
+	}
+	ids, rest, err := pzpr.DecodeBorder(u.Body, u.Cols, u.Rows)
+	if err != nil {
+		return nil, err
+	}
+	nums, _, err := pzpr.DecodeNumber16(rest, u.Cols*u.Rows)
+	if err != nil {
+		return nil, err
+	}
+	allRegions, regionGrid := board.IDsToRegionGrid(ids)
+	numgrid := make([][]int, u.Rows)
+	for y := range numgrid {
+		numgrid[y] = make([]int, u.Cols)
+		for x := range numgrid[y] {
+			n := nums[y*u.Cols+x]
+			if n == pzpr.Question {
+				return nil, fmt.Errorf("cell (%d,%d) holds a question mark, which is not supported", x, y)
+			}
+			numgrid[y][x] = max(n, 0)
+		}
+	}
+	b, err := newBoard(allRegions, regionGrid, numgrid)
+	if err != nil {
+		return nil, err
+	}
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// URL returns the puzzle's regions and filled cells as a puzz.link URL.
+// Every filled cell is written as a given, so the URL of a board that has
+// been solved gives the solution away.
+func (b *Board) URL() string {
+	d := b.Document()
+	nums := make([]int, 0, b.W*b.H)
+	for _, row := range d.Cells {
+		for _, v := range row {
+			if v == grid.UNKNOWN {
+				v = pzpr.Empty
+			}
+			nums = append(nums, v)
+		}
+	}
+	u := pzpr.URL{Type: "ripple", Cols: b.W, Rows: b.H, Body: pzpr.EncodeBorder(d.Regions) + pzpr.EncodeNumber16(nums)}
+	return u.String()
+}
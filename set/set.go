@@ -1,14 +1,19 @@
-package main
+// Package set provides a generic set whose members can be listed in a fixed
+// order, so that anything driven by iterating a set is reproducible.
+package set
 
 import (
 	"fmt"
+	"slices"
 )
 
+// Set is a set of T. SortFunc orders the members for Sorted and String.
 type Set[T comparable] struct {
 	M        map[T]interface{}
 	SortFunc func(a, b T) int
 }
 
+// NewSet returns an empty set ordered by sortfunc.
 func NewSet[T comparable](sortfunc func(a, b T) int) *Set[T] {
 	return &Set[T]{
 		M:        make(map[T]interface{}),
@@ -16,21 +21,7 @@ func NewSet[T comparable](sortfunc func(a, b T) int) *Set[T] {
 	}
 }
 
-func NewCoordSet() *Set[Coord] {
-	return NewSet[Coord](func(a, b Coord) int {
-		if a.Y < b.Y {
-			return -1
-		} else if a.Y > b.Y {
-			return 1
-		} else if a.X < b.X {
-			return -1
-		} else if a.X > b.X {
-			return 1
-		}
-		return 0
-	})
-}
-
+// NewNumSet returns the set {1, ..., order}.
 func NewNumSet(order int) *Set[int] {
 	set := NewSet[int](func(a, b int) int {
 		if a < b {
@@ -53,7 +44,7 @@ func (s *Set[T]) String() string {
 		if idx > 0 {
 			out += " "
 		}
-		out += fmt.Sprintf("%s", t)
+		out += fmt.Sprintf("%v", t)
 		idx++
 	}
 	return out
@@ -63,6 +54,7 @@ func (s *Set[T]) Size() int {
 	return len(s.M)
 }
 
+// GetOne returns an arbitrary member. s must not be empty.
 func (s *Set[T]) GetOne() T {
 	for k := range s.M {
 		return k
@@ -70,6 +62,7 @@ func (s *Set[T]) GetOne() T {
 	panic(fmt.Errorf("trying to GetOne from empty set"))
 }
 
+// Add adds t and returns true iff it was not already a member.
 func (s *Set[T]) Add(t T) bool {
 	has := s.Has(t)
 	s.M[t] = struct{}{}
@@ -86,11 +79,22 @@ func (s *Set[T]) AddAll(o *Set[T]) bool {
 	return added
 }
 
+// Sorted returns the members of the set as a slice ordered by SortFunc.
+func (s *Set[T]) Sorted() []T {
+	out := make([]T, 0, len(s.M))
+	for k := range s.M {
+		out = append(out, k)
+	}
+	slices.SortFunc(out, s.SortFunc)
+	return out
+}
+
 func (s *Set[T]) Has(t T) bool {
 	_, ok := s.M[t]
 	return ok
 }
 
+// Del removes t and returns true iff it was a member.
 func (s *Set[T]) Del(t T) bool {
 	has := s.Has(t)
 	delete(s.M, t)
@@ -103,6 +107,7 @@ func (s *Set[T]) Clear() {
 	}
 }
 
+// Copy returns a set with the same members and order.
 func (s *Set[T]) Copy() *Set[T] {
 	out := NewSet[T](s.SortFunc)
 	out.AddAll(s)
@@ -0,0 +1,53 @@
+package starbattle
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable star(x,y) is true when
+// that cell holds a star, and cells already known are unit clauses.
+//
+// Every row, column and region holds exactly Stars stars, with a
+// sequential counter, and no two stars touch, even diagonally. The formula
+// grows with the number of cells times Stars.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, star := b.encode()
+	return b.SetFromModel(m, star)
+}
+
+// encode builds the formula CNF returns, along with the star variable of
+// every cell.
+func (b *Board) encode() (*cnf.Formula, [][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d, %d stars", TypeName, b.W, b.H, b.Stars)}
+	star := b.CellVars(f, "star")
+	s := func(c grid.Coord) int {
+		return star[c.Y][c.X]
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		// Each touching pair once: the neighbors later in reading order.
+		for _, n := range []grid.Coord{c.Plus(grid.RIGHT), c.Plus(grid.DOWN), c.Plus(grid.DOWN).Plus(grid.RIGHT), c.Plus(grid.DOWN).Plus(grid.LEFT)} {
+			if b.IsValid(n) {
+				f.Add(-s(c), -s(n))
+			}
+		}
+	}
+	for _, u := range b.AllRegions {
+		lits := make([]int, 0, len(*u))
+		for _, c := range *u {
+			lits = append(lits, s(c))
+		}
+		f.Exactly(lits, b.Stars)
+	}
+	return f, star
+}
@@ -0,0 +1,50 @@
+package starbattle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// Explain describes why step s made mark m, for board.HintBin.
+func (b *Board) Explain(s board.Step, m board.CellValue) string {
+	switch s.Rule {
+	case "ClearAroundStars":
+		if touching(m.At, s.Cells[0]) {
+			return fmt.Sprintf("it touches the star at %s", s.Cells[0])
+		}
+		return fmt.Sprintf("it shares a row, column or region with the star at %s, which has all its stars", s.Cells[0])
+	case "PlaceStars":
+		u := b.unitName(b.unitFrom(s.Cells[0], s.Cells[1]))
+		if grid.Cell(m.Value) == grid.PAINTED {
+			return fmt.Sprintf("every way to place the stars of %s puts one here", u)
+		}
+		return fmt.Sprintf("no way to place the stars of %s puts one here", u)
+	case "ConfineRows", "ConfineColumns":
+		lo, hi, at := s.Cells[0].Y, s.Cells[1].Y, m.At.Y
+		band := "rows"
+		if s.Rule == "ConfineColumns" {
+			lo, hi, at = s.Cells[0].X, s.Cells[1].X, m.At.X
+			band = "columns"
+		}
+		if lo == hi {
+			band = fmt.Sprintf("%s %d", strings.TrimSuffix(band, "s"), lo)
+		} else {
+			band = fmt.Sprintf("%s %d to %d", band, lo, hi)
+		}
+		n := hi - lo + 1
+		if at >= lo && at <= hi {
+			return fmt.Sprintf("%d regions lie wholly in %s, so their stars fill it", n, band)
+		}
+		return fmt.Sprintf("only %d regions reach %s, so all their stars are needed there", n, band)
+	case "ClearBlockers":
+		return fmt.Sprintf("a star here would leave no room for the stars of %s", b.unitName(b.unitFrom(s.Cells[0], s.Cells[1])))
+	}
+	cells := make([]string, 0, len(s.Cells))
+	for _, c := range s.Cells {
+		cells = append(cells, c.String())
+	}
+	return fmt.Sprintf("follows from %s at %s", s.Rule, strings.Join(cells, " "))
+}
@@ -0,0 +1,54 @@
+package starbattle
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// BoardFromDocument reads a puzzle from its regions and number of stars,
+// then fills in any given cells. Cells are taken as they are, without
+// running the rules.
+func BoardFromDocument(d *puzzle.Document) (*Board, error) {
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	if len(d.Regions) == 0 {
+		return nil, fmt.Errorf("document has no regions")
+	}
+	stars := d.Stars
+	if stars == 0 {
+		stars = 1
+	}
+	if stars < 0 {
+		return nil, fmt.Errorf("document has %d stars", stars)
+	}
+	allRegions, regionGrid := board.IDsToRegionGrid(d.Regions)
+	b := newBoard(stars, allRegions, regionGrid)
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	if err := d.SetBinCells(&b.RectBinBoard); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Document returns the puzzle with its current cells. Regions are
+// numbered from 0 in reading order of their first cell, as in RegionOf.
+func (b *Board) Document() *puzzle.Document {
+	d := &puzzle.Document{
+		Type:    TypeName,
+		Width:   b.W,
+		Height:  b.H,
+		Author:  b.Meta.Author,
+		Source:  b.Meta.Source,
+		Regions: grid.CopyNumGrid(b.RegionOf),
+		Stars:   b.Stars,
+		Cells:   b.Cells(),
+	}
+	d.Solved, _ = b.IsSolved()
+	return d
+}
@@ -0,0 +1,64 @@
+package starbattle
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place: a
+// bad number of stars, map lines of different widths or with blank cells,
+// a map that is not square or has a different number of regions than
+// rows, regions in more than one piece, and regions with no room for
+// their stars. A puzz.link URL is left to BoardFromURL.
+func Lint(lines []string) []error {
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return nil
+	}
+	stars, rows, skipped := splitStars(lines)
+	if len(rows) == 0 {
+		return []error{grid.LineErrorf(skipped+1, 0, "region map is empty")}
+	}
+	errs := make([]error, 0)
+	w := len([]rune(rows[0]))
+	if skipped > 0 && stars < 1 {
+		errs = append(errs, grid.LineErrorf(1, 1, "number of stars is %d; it must be at least 1", stars))
+	} else if 2*stars-1 > w {
+		errs = append(errs, grid.LineErrorf(1, 1, "%d stars that do not touch do not fit in a row of %d cells", stars, w))
+	}
+	for y, l := range rows {
+		row := []rune(l)
+		if len(row) != w {
+			errs = append(errs, grid.LineErrorf(skipped+y+1, 0, "region map line has %d cells; the first has %d", len(row), w))
+		}
+		for x, ch := range row {
+			if board.IsBlank(ch) {
+				errs = append(errs, grid.LineErrorf(skipped+y+1, x+1, "region map cell %q names no region", ch))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		// Regions cannot be traced through a ragged or holed map.
+		return errs
+	}
+	if w != len(rows) {
+		errs = append(errs, fmt.Errorf("region map is %dx%d; every row and column holds the same number of stars, so it must be square", w, len(rows)))
+	}
+	allRegions, regionGrid := board.LinesToRegionGrid(rows)
+	if len(allRegions) != len(rows) {
+		errs = append(errs, fmt.Errorf("region map has %d regions and %d rows; every region holds as many stars as a row, so they must match", len(allRegions), len(rows)))
+	}
+	for _, r := range allRegions {
+		name := []rune(rows[(*r)[0].Y])[(*r)[0].X]
+		if cut, from, ok := board.SplitRegion(*r, regionGrid); ok {
+			errs = append(errs, grid.LineErrorf(skipped+cut.Y+1, cut.X+1, "region %q is in more than one piece; this cell is cut off from line %d, column %d", name, skipped+from.Y+1, from.X+1))
+		} else if !canPlace(*r, stars) {
+			first := (*r)[0]
+			errs = append(errs, grid.LineErrorf(skipped+first.Y+1, first.X+1, "region %q has no room for %d stars that do not touch", name, stars))
+		}
+	}
+	return errs
+}
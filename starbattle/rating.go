@@ -0,0 +1,13 @@
+package starbattle
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the star battle rules in increasing order of difficulty.
+func (b *Board) Techniques() []board.Technique {
+	return []board.Technique{
+		board.DirtyTechnique(&b.RectBoard, "ClearAroundStars", 1, b.ClearAroundStars),
+		board.DirtyTechnique(&b.RectBoard, "PlaceStars", 2, b.PlaceStars),
+		board.DirtyTechnique(&b.RectBoard, "ConfineRegions", 3, b.ConfineRegions),
+		board.DirtyTechnique(&b.RectBoard, "ClearBlockers", 4, b.ClearBlockers),
+	}
+}
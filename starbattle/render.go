@@ -0,0 +1,15 @@
+package starbattle
+
+import "github.com/bismuthsalamander/mutantcheckerboard/render"
+
+// Scene describes the board for the renderers: each cell's shade, with
+// stars drawn as painted cells, and the regions.
+func (b *Board) Scene() *render.Scene {
+	s := render.NewScene(b.W, b.H)
+	s.Meta = b.Meta
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		s.At(c).Shade = b.Get(c)
+	}
+	s.Regions = b.RegionOf
+	return s
+}
@@ -0,0 +1,18 @@
+package starbattle
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckBin(t, BoardFromLines, []boardtest.Case{
+		{Name: "unique", Lines: []string{"abbb", "abcc", "ddcc", "dddc"}},
+		{Name: "quadrants", Lines: []string{"aabb", "aabb", "ccdd", "ccdd"}},
+		{Name: "rows", Lines: []string{"aaaa", "bbbb", "cccc", "dddd"}},
+		{Name: "star count", Lines: []string{"1", "aabb", "abbb", "cccd", "ccdd"}},
+		{Name: "two stars", Lines: []string{"2", "aaaa", "bbbb", "cccc", "dddd"}},
+		{Name: "no solution", Lines: []string{"aaab", "accb", "dccb", "dddb"}},
+	})
+}
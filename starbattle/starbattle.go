@@ -0,0 +1,643 @@
+// Package starbattle solves star battle. The grid is divided into regions,
+// and every row, column and region holds the same number of stars, one or
+// more. Stars may not touch, not even diagonally. A star is a painted
+// cell, and a cell known to hold no star is clear.
+package starbattle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Board is a star battle puzzle with Stars stars in every unit: every
+// region of the map, row and column. AllRegions holds the units: first the
+// NumRegions regions of the map, in reading order of their first cells,
+// then the rows and then the columns. RegionGrid holds the units each cell
+// is in, as in board.RectNumBoard, and RegionOf the index in AllRegions of
+// each cell's map region. None of them change as the puzzle is solved, so
+// clones share them.
+type Board struct {
+	board.RectBinBoard
+	Stars      int
+	AllRegions []*[]grid.Coord
+	RegionGrid [][][]*[]grid.Coord
+	RegionOf   [][]int
+	NumRegions int
+}
+
+// Regions returns the regions of the map, without the rows and columns.
+func (b *Board) Regions() []*[]grid.Coord {
+	return b.AllRegions[:b.NumRegions]
+}
+
+// around returns the cells on the board that touch c, diagonals included.
+func (b *Board) around(c grid.Coord) []grid.Coord {
+	out := make([]grid.Coord, 0, 8)
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			n := grid.Coord{X: c.X + dx, Y: c.Y + dy}
+			if n != c && b.IsValid(n) {
+				out = append(out, n)
+			}
+		}
+	}
+	return out
+}
+
+// touching reports whether a and b are different cells that touch,
+// diagonals included.
+func touching(a, b grid.Coord) bool {
+	return a != b && max(a.X-b.X, b.X-a.X) <= 1 && max(a.Y-b.Y, b.Y-a.Y) <= 1
+}
+
+// tally returns the number of stars and of unknown cells in unit.
+func (b *Board) tally(unit []grid.Coord) (stars int, unknown int) {
+	for _, c := range unit {
+		if b.IsPainted(c) {
+			stars++
+		} else if b.IsUnknown(c) {
+			unknown++
+		}
+	}
+	return stars, unknown
+}
+
+// unitName names unit i of AllRegions for messages.
+func (b *Board) unitName(i int) string {
+	first := (*b.AllRegions[i])[0]
+	switch {
+	case i < b.NumRegions:
+		return fmt.Sprintf("the region at %s", first)
+	case i < b.NumRegions+b.H:
+		return fmt.Sprintf("row %d", first.Y)
+	}
+	return fmt.Sprintf("column %d", first.X)
+}
+
+// unitFrom returns the index in AllRegions of the first unit that starts at
+// first and ends at last, as the steps of the unit rules name them, or -1.
+func (b *Board) unitFrom(first, last grid.Coord) int {
+	for i, u := range b.AllRegions {
+		if (*u)[0] == first && (*u)[len(*u)-1] == last {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsSolved reports whether every cell is known and every rule holds. The
+// error says what is still wrong.
+func (b *Board) IsSolved() (bool, error) {
+	res, coord := b.IsComplete()
+	if !res {
+		return false, fmt.Errorf("cell %s is unknown", coord)
+	}
+	for i, u := range b.AllRegions {
+		if stars, _ := b.tally(*u); stars != b.Stars {
+			return false, fmt.Errorf("%s has %d stars, not %d", b.unitName(i), stars, b.Stars)
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Validate returns an error if the board's current contents already break a
+// rule, even though some cells may still be unknown: two touching stars,
+// or a unit with more stars than Stars or too few cells left to reach it.
+// For a complete board, Validate returns nil exactly when IsSolved returns
+// true.
+func (b *Board) Validate() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsPainted(c) {
+			continue
+		}
+		for _, n := range b.around(c) {
+			if b.IsPainted(n) {
+				return board.Contradiction(c, "Validate", "star touches the star at %s", n)
+			}
+		}
+	}
+	for i, u := range b.AllRegions {
+		stars, unknown := b.tally(*u)
+		if stars > b.Stars {
+			return board.Contradiction((*u)[0], "Validate", "%s has %d stars, more than %d", b.unitName(i), stars, b.Stars)
+		}
+		if stars+unknown < b.Stars {
+			return board.Contradiction((*u)[0], "Validate", "%s has room for only %d stars, fewer than %d", b.unitName(i), stars+unknown, b.Stars)
+		}
+	}
+	return nil
+}
+
+// Clone returns a copy of the board whose cells can be changed without
+// affecting the original.
+func (b *Board) Clone() *Board {
+	return &Board{
+		RectBinBoard: *b.RectBinBoard.Clone(),
+		Stars:        b.Stars,
+		AllRegions:   b.AllRegions,
+		RegionGrid:   b.RegionGrid,
+		RegionOf:     b.RegionOf,
+		NumRegions:   b.NumRegions,
+	}
+}
+
+// Mark writes v to c and runs the rules that follow from it.
+func (b *Board) Mark(c grid.Coord, v grid.Cell) (bool, error) {
+	res, err := b.Set(c, v)
+	if !res {
+		return res, err
+	}
+	b.SetDirty()
+	return res, b.PostMark(c, v)
+}
+
+func (b *Board) MarkPainted(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.PAINTED)
+}
+
+func (b *Board) MarkClear(c grid.Coord) (bool, error) {
+	return b.Mark(c, grid.CLEAR)
+}
+
+// PostMark runs the rules that follow from v having just been written to c:
+// the cells around a star hold no star, and neither does the rest of a unit
+// that has all its stars.
+func (b *Board) PostMark(c grid.Coord, v grid.Cell) error {
+	if v != grid.PAINTED {
+		return nil
+	}
+	return b.clearAround(c)
+}
+
+// clearAround clears the unknown cells around the star at c and the
+// unknown cells of every unit of c that has all its stars.
+func (b *Board) clearAround(c grid.Coord) error {
+	b.BeginStep("ClearAroundStars", c)
+	defer b.EndStep()
+	for _, n := range b.around(c) {
+		if _, err := b.MarkClear(n); err != nil {
+			return err
+		}
+	}
+	for _, u := range b.RegionGrid[c.Y][c.X] {
+		if stars, _ := b.tally(*u); stars < b.Stars {
+			continue
+		}
+		for _, n := range *u {
+			if b.IsUnknown(n) {
+				if _, err := b.MarkClear(n); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// String draws the board in a frame, with X for stars and · for cells
+// without one.
+func (b *Board) String() string {
+	out := "+" + strings.Repeat("-", b.W) + "+\n"
+	for y := 0; y < b.H; y++ {
+		out += "|"
+		for x := 0; x < b.W; x++ {
+			out += string(b.Get(grid.Coord{X: x, Y: y}).Ch())
+		}
+		out += "|"
+		if y != b.H-1 {
+			out += "\n"
+		}
+	}
+	out += "\n+" + strings.Repeat("-", b.W) + "+"
+	return out
+}
+
+// ClearAroundStars clears every unknown cell around a star, and the rest of
+// every unit that has all its stars. PostMark already does this whenever a
+// star is marked, so this only finds work on boards whose cells were filled
+// in without it, such as a partially solved state loaded for a hint.
+func (b *Board) ClearAroundStars() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.IsPainted(c) {
+			if err := b.clearAround(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// placeBudget caps the number of partial placements PlaceStars and
+// ClearBlockers try for one unit before giving up on it.
+const placeBudget = 1 << 16
+
+// PlaceStars tries every way to place the rest of each unit's stars in its
+// unknown cells and marks the cells that hold a star in all of them, or in
+// none. Units with too many ways to try are left alone.
+func (b *Board) PlaceStars() error {
+	for i, u := range b.AllRegions {
+		stars, _ := b.tally(*u)
+		if stars >= b.Stars {
+			continue
+		}
+		open := b.openCells(*u)
+		ways, starred, ok := placements(open, b.Stars-stars)
+		if !ok {
+			continue
+		}
+		if ways == 0 {
+			return board.Contradiction((*u)[0], "PlaceStars", "%s has no room for %d more stars", b.unitName(i), b.Stars-stars)
+		}
+		for j, c := range open {
+			if !b.IsUnknown(c) || (starred[j] != 0 && starred[j] != ways) {
+				continue
+			}
+			b.BeginStep("PlaceStars", (*u)[0], (*u)[len(*u)-1])
+			var err error
+			if starred[j] == ways {
+				_, err = b.MarkPainted(c)
+			} else {
+				_, err = b.MarkClear(c)
+			}
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// openCells returns the unknown cells of unit that do not touch a star.
+func (b *Board) openCells(unit []grid.Coord) []grid.Coord {
+	open := make([]grid.Coord, 0, len(unit))
+	for _, c := range unit {
+		if b.IsUnknown(c) && !b.touchesStar(c) {
+			open = append(open, c)
+		}
+	}
+	return open
+}
+
+// touchesStar reports whether a cell around c holds a star.
+func (b *Board) touchesStar(c grid.Coord) bool {
+	for _, n := range b.around(c) {
+		if b.IsPainted(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// placements counts the ways to put need stars in the cells of open, no two
+// of them touching, and for each cell the number of ways that put a star
+// in it. ok is false if there are too many partial placements to try.
+func placements(open []grid.Coord, need int) (ways int, starred []int, ok bool) {
+	starred = make([]int, len(open))
+	chosen := make([]bool, len(open))
+	tries := 0
+	var walk func(i, left int) bool
+	walk = func(i, left int) bool {
+		tries++
+		if tries > placeBudget {
+			return false
+		}
+		if left == 0 {
+			ways++
+			for j, p := range chosen {
+				if p {
+					starred[j]++
+				}
+			}
+			return true
+		}
+		if len(open)-i < left {
+			return true
+		}
+		if fitsAfter(open, chosen, i) {
+			chosen[i] = true
+			if !walk(i+1, left-1) {
+				return false
+			}
+			chosen[i] = false
+		}
+		return walk(i+1, left)
+	}
+	ok = walk(0, need)
+	return ways, starred, ok
+}
+
+// fitsAfter reports whether open[i] touches none of the cells chosen
+// before it.
+func fitsAfter(open []grid.Coord, chosen []bool, i int) bool {
+	for j := 0; j < i; j++ {
+		if chosen[j] && touching(open[j], open[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// canPlace reports whether need stars fit in the cells of open, no two of
+// them touching. It also says yes when there are too many ways to try.
+func canPlace(open []grid.Coord, need int) bool {
+	if need <= 0 {
+		return need == 0
+	}
+	chosen := make([]bool, len(open))
+	tries := 0
+	var walk func(i, left int) bool
+	walk = func(i, left int) bool {
+		tries++
+		if left == 0 || tries > placeBudget {
+			return true
+		}
+		if len(open)-i < left {
+			return false
+		}
+		if fitsAfter(open, chosen, i) {
+			chosen[i] = true
+			if walk(i+1, left-1) {
+				return true
+			}
+			chosen[i] = false
+		}
+		return walk(i+1, left)
+	}
+	return walk(0, need)
+}
+
+// ClearBlockers looks for unknown cells where a star would leave some unit
+// without room for its own stars, and clears them. A star takes the cells
+// around it, so this catches a cell next to the only places left in a
+// neighboring row, column or region.
+func (b *Board) ClearBlockers() error {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsUnknown(c) || b.touchesStar(c) {
+			continue
+		}
+		taken := grid.NewCoordSet()
+		taken.Add(c)
+		for _, n := range b.around(c) {
+			taken.Add(n)
+		}
+		checked := make(map[*[]grid.Coord]bool)
+		for _, k := range taken.Sorted() {
+			for _, u := range b.RegionGrid[k.Y][k.X] {
+				if checked[u] {
+					continue
+				}
+				checked[u] = true
+				stars, _ := b.tally(*u)
+				need := b.Stars - stars
+				open := make([]grid.Coord, 0, len(*u))
+				for _, o := range b.openCells(*u) {
+					if o == c {
+						need--
+					} else if !taken.Has(o) {
+						open = append(open, o)
+					}
+				}
+				if canPlace(open, need) {
+					continue
+				}
+				b.BeginStep("ClearBlockers", (*u)[0], (*u)[len(*u)-1])
+				_, err := b.MarkClear(c)
+				b.EndStep()
+				if err != nil {
+					return err
+				}
+				break
+			}
+			if !b.IsUnknown(c) {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ConfineRegions looks for bands of k neighboring rows, or columns, that
+// wholly hold the cells left to k regions. Those regions put all their
+// stars in the band, which has room for no more, so the band's other cells
+// are clear. The converse holds too: if only k regions reach into a band of
+// k rows, the band's stars all come from them, and their cells outside the
+// band are clear.
+func (b *Board) ConfineRegions() error {
+	for _, rows := range []bool{true, false} {
+		n, rule := b.W, "ConfineColumns"
+		line := func(c grid.Coord) int { return c.X }
+		if rows {
+			n, rule = b.H, "ConfineRows"
+			line = func(c grid.Coord) int { return c.Y }
+		}
+		for lo := 0; lo < n; lo++ {
+			for hi := lo; hi < n-1 || (hi == n-1 && lo > 0); hi++ {
+				if err := b.confine(lo, hi, line, rule); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// confine runs ConfineRegions on the band of lines lo to hi, where line
+// gives the row or column of a cell.
+func (b *Board) confine(lo, hi int, line func(grid.Coord) int, rule string) error {
+	size := hi - lo + 1
+	inside := make(map[int]bool)
+	reaching := make(map[int]bool)
+	for r, region := range b.Regions() {
+		in, out := false, false
+		for _, c := range *region {
+			if b.IsClear(c) {
+				continue
+			}
+			if l := line(c); l >= lo && l <= hi {
+				in = true
+			} else {
+				out = true
+			}
+		}
+		if in {
+			reaching[r] = true
+			if !out {
+				inside[r] = true
+			}
+		}
+	}
+	from, to := b.bandEnds(lo, hi, rule)
+	if len(inside) > size {
+		return board.Contradiction(from, rule, "%d regions must put all their stars in %d lines", len(inside), size)
+	}
+	if len(reaching) < size {
+		return board.Contradiction(from, rule, "only %d regions can put stars in %d lines", len(reaching), size)
+	}
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if !b.IsUnknown(c) {
+			continue
+		}
+		r := b.RegionOf[c.Y][c.X]
+		inBand := line(c) >= lo && line(c) <= hi
+		if (len(inside) == size && inBand && !inside[r]) || (len(reaching) == size && !inBand && reaching[r]) {
+			b.BeginStep(rule, from, to)
+			_, err := b.MarkClear(c)
+			b.EndStep()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bandEnds returns the first and last cells of the band of rows or columns
+// lo to hi, for the steps of ConfineRegions.
+func (b *Board) bandEnds(lo, hi int, rule string) (grid.Coord, grid.Coord) {
+	if rule == "ConfineRows" {
+		return grid.Coord{X: 0, Y: lo}, grid.Coord{X: b.W - 1, Y: hi}
+	}
+	return grid.Coord{X: lo, Y: 0}, grid.Coord{X: hi, Y: b.H - 1}
+}
+
+// Solve applies the rules until none of them makes progress. Returns a
+// ContradictionError if the board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	b.SetDirty()
+	for b.IsDirty() {
+		b.ClearDirty()
+		for _, rule := range []func() error{
+			b.PlaceStars,
+			b.ConfineRegions,
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			if err := rule(); err != nil {
+				return err
+			}
+		}
+		if b.IsDirty() {
+			continue
+		}
+		if err := board.Stopped(ctx); err != nil {
+			return err
+		}
+		if err := b.ClearBlockers(); err != nil {
+			return err
+		}
+	}
+	return b.Validate()
+}
+
+// BoardFromLines reads a puzzle as a map of regions, in which every
+// character names the region its cell belongs to, as in
+// board.LinesToRegionGrid. The map may follow a line holding just the
+// number of stars in each row, column and region; without one, that
+// number is 1:
+//
+//	2
+//	aaabbb
+//	aaabbb
+//	...
+//
+// A single line holding a puzz.link URL is read with BoardFromURL. Every
+// problem Lint finds is returned, joined with errors.Join.
+func BoardFromLines(input []string) (*Board, error) {
+	if len(input) == 1 && pzpr.IsURL(input[0]) {
+		return BoardFromURL(input[0])
+	}
+	if errs := Lint(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	stars, rows, _ := splitStars(input)
+	allRegions, regionGrid := board.LinesToRegionGrid(rows)
+	return newBoard(stars, allRegions, regionGrid), nil
+}
+
+// Lines returns the puzzle's regions in the text format BoardFromLines
+// reads, after the number of stars if it is not 1.
+func (b *Board) Lines() []string {
+	lines := board.IDsToLines(b.RegionOf)
+	if b.Stars == 1 {
+		return lines
+	}
+	return append([]string{strconv.Itoa(b.Stars)}, lines...)
+}
+
+// splitStars splits the lines of a text puzzle into the number of stars
+// and the map, and returns how many lines come before the map. A first line
+// of digits alone, shorter than the line after it, is the number of stars.
+func splitStars(input []string) (int, []string, int) {
+	if len(input) < 2 || len(input[0]) >= len(input[1]) {
+		return 1, input, 0
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(input[0]))
+	if err != nil {
+		return 1, input, 0
+	}
+	return n, input[1:], 1
+}
+
+// newBoard returns an unsolved board with stars stars in every unit and
+// the given regions, as from board.LinesToRegionGrid, which must cover a
+// rectangle.
+func newBoard(stars int, allRegions []*[]grid.Coord, regionGrid [][][]*[]grid.Coord) *Board {
+	w, h := len(regionGrid[0]), len(regionGrid)
+	b := &Board{
+		RectBinBoard: *board.NewRectBinBoard(w, h),
+		Stars:        stars,
+		RegionGrid:   make([][][]*[]grid.Coord, h),
+		RegionOf:     grid.MakeNumGrid(w, h),
+		NumRegions:   len(allRegions),
+	}
+	for y := range b.RegionGrid {
+		b.RegionGrid[y] = make([][]*[]grid.Coord, w)
+	}
+	add := func(r []grid.Coord) {
+		b.AllRegions = append(b.AllRegions, &r)
+		for _, c := range r {
+			b.RegionGrid[c.Y][c.X] = append(b.RegionGrid[c.Y][c.X], &r)
+		}
+	}
+	for i, r := range allRegions {
+		add(*r)
+		for _, c := range *r {
+			b.RegionOf[c.Y][c.X] = i
+		}
+	}
+	for y := 0; y < h; y++ {
+		row := make([]grid.Coord, w)
+		for x := range row {
+			row[x] = grid.Coord{X: x, Y: y}
+		}
+		add(row)
+	}
+	for x := 0; x < w; x++ {
+		col := make([]grid.Coord, h)
+		for y := range col {
+			col[y] = grid.Coord{X: x, Y: y}
+		}
+		add(col)
+	}
+	b.Inited = true
+	return b
+}
@@ -0,0 +1,136 @@
+package starbattle
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+func TestStars(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		want  int
+	}{
+		{"default", []string{"aabb", "aabb", "ccdd", "ccdd"}, 1},
+		{"one", []string{"1", "aabb", "aabb", "ccdd", "ccdd"}, 1},
+		{"two", []string{"2", "aaabb", "cccbb", "cccbb", "ddddd", "eeeee"}, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.Stars != tt.want {
+				t.Errorf("Stars is %d; want %d", b.Stars, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlacements(t *testing.T) {
+	line := func(n int) []grid.Coord {
+		open := make([]grid.Coord, n)
+		for x := range open {
+			open[x] = grid.Coord{X: x}
+		}
+		return open
+	}
+	for _, tt := range []struct {
+		name    string
+		open    []grid.Coord
+		need    int
+		ways    int
+		starred []int
+	}{
+		{"one star in three cells", line(3), 1, 3, []int{1, 1, 1}},
+		{"two stars in three cells", line(3), 2, 1, []int{1, 0, 1}},
+		{"two stars in four cells", line(4), 2, 3, []int{2, 1, 1, 2}},
+		{"two stars in two cells", line(2), 2, 0, []int{0, 0}},
+		{"diagonal", []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 3, Y: 1}}, 2, 2, []int{1, 1, 2}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ways, starred, ok := placements(tt.open, tt.need)
+			if !ok {
+				t.Fatal("placements gave up")
+			}
+			if ways != tt.ways || !slices.Equal(starred, tt.starred) {
+				t.Errorf("placements(%v, %d) = %d, %v; want %d, %v", tt.open, tt.need, ways, starred, tt.ways, tt.starred)
+			}
+			if fits := canPlace(tt.open, tt.need); fits != (tt.ways > 0) {
+				t.Errorf("canPlace(%v, %d) = %v; placements finds %d ways", tt.open, tt.need, fits, tt.ways)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		paint []grid.Coord
+		clear []grid.Coord
+		rule  func(*Board) error
+		at    grid.Coord
+		want  grid.Cell
+	}{
+		{"star in a one cell region", []string{"abbb", "bbbb", "cccc", "dddd"}, nil, nil, (*Board).PlaceStars, grid.Coord{X: 0, Y: 0}, grid.PAINTED},
+		{"last cell of a row", []string{"aaaa", "bbbb", "cccc", "dddd"}, nil, []grid.Coord{{X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}}, (*Board).PlaceStars, grid.Coord{X: 0, Y: 2}, grid.PAINTED},
+		{"around a star", []string{"aaaa", "bbbb", "cccc", "dddd"}, []grid.Coord{{X: 1, Y: 1}}, nil, (*Board).ClearAroundStars, grid.Coord{X: 0, Y: 0}, grid.CLEAR},
+		{"rest of the row", []string{"aaaa", "bbbb", "cccc", "dddd"}, []grid.Coord{{X: 1, Y: 1}}, nil, (*Board).ClearAroundStars, grid.Coord{X: 3, Y: 1}, grid.CLEAR},
+		{"second star keeps the first's row", []string{"2", "aaabb", "cccbb", "cccbb", "ddddd", "eeeee"}, []grid.Coord{{X: 1, Y: 1}}, nil, (*Board).ClearAroundStars, grid.Coord{X: 4, Y: 1}, grid.UNKNOWN},
+		{"blocker", []string{"aaaa", "bbbb", "cccc", "dddd"}, nil, []grid.Coord{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}, (*Board).ClearBlockers, grid.Coord{X: 0, Y: 1}, grid.CLEAR},
+		{"region in one row", []string{"aaab", "bbbb", "cccc", "dddd"}, nil, nil, (*Board).ConfineRegions, grid.Coord{X: 3, Y: 0}, grid.CLEAR},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.paint {
+				b.Set(c, grid.PAINTED)
+			}
+			for _, c := range tt.clear {
+				b.Set(c, grid.CLEAR)
+			}
+			if err := tt.rule(b); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.Get(tt.at); got != tt.want {
+				t.Errorf("%s is %s; want %s\n%s", tt.at, grid.CellName(got), grid.CellName(tt.want), b)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		paint []grid.Coord
+		clear []grid.Coord
+	}{
+		{"touching stars", []string{"2", "aaabb", "cccbb", "cccbb", "ddddd", "eeeee"}, []grid.Coord{{X: 0, Y: 0}, {X: 1, Y: 1}}, nil},
+		{"too many in a row", []string{"aabb", "aabb", "ccdd", "ccdd"}, []grid.Coord{{X: 0, Y: 0}, {X: 2, Y: 0}}, nil},
+		{"too many in a region", []string{"abbb", "abcc", "ddcc", "dddc"}, []grid.Coord{{X: 2, Y: 1}, {X: 3, Y: 3}}, nil},
+		{"too few in a row", []string{"2", "aaabb", "cccbb", "cccbb", "ddddd", "eeeee"}, []grid.Coord{{X: 0, Y: 2}}, []grid.Coord{{X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}, {X: 4, Y: 2}}},
+		{"too few in a column", []string{"aabb", "aabb", "ccdd", "ccdd"}, nil, []grid.Coord{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := BoardFromLines(tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.paint {
+				b.Set(c, grid.PAINTED)
+			}
+			for _, c := range tt.clear {
+				b.Set(c, grid.CLEAR)
+			}
+			if err := b.Validate(); err == nil {
+				t.Errorf("Validate found nothing wrong with\n%s", b)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package starbattle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// BoardFromURL reads a puzzle from a pzprjs "starbattle" URL: the number
+// of stars, a slash, and the region borders.
+func BoardFromURL(s string) (*Board, error) {
+	u, err := pzpr.ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "starbattle" {
+		return nil, fmt.Errorf("puzzle URL is for %q, not starbattle", u.Type)
+	}
+	count, body, ok := strings.Cut(u.Body, "/")
+	if !ok {
+		return nil, fmt.Errorf("puzzle URL has no number of stars")
+	}
+	stars, err := strconv.Atoi(count)
+	if err != nil || stars < 1 {
+		return nil, fmt.Errorf("puzzle URL has bad number of stars %q", count)
+	}
+	ids, _, err := pzpr.DecodeBorder(body, u.Cols, u.Rows)
+	if err != nil {
+		return nil, err
+	}
+	allRegions, regionGrid := board.IDsToRegionGrid(ids)
+	b := newBoard(stars, allRegions, regionGrid)
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// URL returns the puzzle's number of stars and regions as a puzz.link URL.
+func (b *Board) URL() string {
+	u := pzpr.URL{Type: "starbattle", Cols: b.W, Rows: b.H, Body: strconv.Itoa(b.Stars) + "/" + pzpr.EncodeBorder(b.RegionOf)}
+	return u.String()
+}
@@ -0,0 +1,99 @@
+package towers
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/cnf"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+)
+
+// CNF encodes the puzzle as a SAT problem. Variable cell(x,y)=v is true when
+// that cell holds height v; every cell holds one height, every row and
+// column holds each height once, and given cells are unit clauses.
+//
+// For each observer, ge(i, v) says that one of the first i cells it looks
+// across is at least v tall. Cell i is visible when it holds some v with
+// ge(i-1, v) false, and a counter makes exactly Count cells visible.
+func (b *Board) CNF() *cnf.Formula {
+	f, _ := b.encode()
+	return f
+}
+
+// ApplyModel fills in every unknown cell from a model of the formula CNF
+// returns. No rules run; IsSolved says whether the result is a solution.
+func (b *Board) ApplyModel(m cnf.Model) error {
+	_, vars := b.encode()
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		for v := 1; v <= b.Order; v++ {
+			if !m.Value(vars[c.Y][c.X][v]) {
+				continue
+			}
+			if _, err := b.Set(c, v); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// encode builds the formula CNF returns, along with the variables of every
+// cell, indexed by height from 1.
+func (b *Board) encode() (*cnf.Formula, [][][]int) {
+	f := cnf.New()
+	f.Comments = []string{fmt.Sprintf("%s %dx%d order %d", TypeName, b.W, b.H, b.Order)}
+	vars := make([][][]int, b.H)
+	for y := range vars {
+		vars[y] = make([][]int, b.W)
+		for x := range vars[y] {
+			vars[y][x] = make([]int, b.Order+1)
+			for v := 1; v <= b.Order; v++ {
+				vars[y][x][v] = f.NewVar(fmt.Sprintf("cell(%d,%d)=%d", x, y, v))
+			}
+			f.ExactlyOne(vars[y][x][1:])
+			if v := b.Get(grid.Coord{X: x, Y: y}); v != grid.UNKNOWN {
+				f.Add(vars[y][x][v])
+			}
+		}
+	}
+	for v := 1; v <= b.Order; v++ {
+		for y := 0; y < b.H; y++ {
+			line := make([]int, 0, b.W)
+			for x := 0; x < b.W; x++ {
+				line = append(line, vars[y][x][v])
+			}
+			f.ExactlyOne(line)
+		}
+		for x := 0; x < b.W; x++ {
+			line := make([]int, 0, b.H)
+			for y := 0; y < b.H; y++ {
+				line = append(line, vars[y][x][v])
+			}
+			f.ExactlyOne(line)
+		}
+	}
+
+	for _, o := range b.Observers {
+		// ge[v] is ge(i-1, v) for the cell i being looked at.
+		ge := make([]int, b.Order+1)
+		for v := range ge {
+			ge[v] = -f.True()
+		}
+		visible := make([]int, 0, b.Order)
+		for c := o.Start; b.IsValid(c); c = c.Plus(o.Direction) {
+			cell := vars[c.Y][c.X]
+			seen := make([]int, 0, b.Order)
+			for v := 1; v <= b.Order; v++ {
+				seen = append(seen, f.And(cell[v], -ge[v]))
+			}
+			visible = append(visible, f.Or(seen...))
+			next := make([]int, b.Order+1)
+			for v := 1; v <= b.Order; v++ {
+				next[v] = f.Or(append([]int{ge[v]}, cell[v:]...)...)
+			}
+			ge = next
+		}
+		f.Exactly(visible, o.Count)
+	}
+	return f, vars
+}
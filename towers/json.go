@@ -0,0 +1,93 @@
+package towers
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+)
+
+// BoardFromDocument reads a puzzle from its order, observers and given
+// cells, then narrows the candidates to any the document holds. The order
+// defaults to the width.
+func BoardFromDocument(d *puzzle.Document) (*Board, error) {
+	if err := d.Check(); err != nil {
+		return nil, err
+	}
+	order := d.Order
+	if order == 0 {
+		order = d.Width
+	}
+	// Lay the document out the way BoardFromLines expects it: the order,
+	// then the grid inside a border of observers.
+	input := make([][]int, 0, d.Height+3)
+	input = append(input, []int{order})
+	for y := 0; y < d.Height+2; y++ {
+		input = append(input, make([]int, d.Width+2))
+	}
+	for y, row := range d.Cells {
+		for x, v := range row {
+			if v < 0 || v > order {
+				return nil, fmt.Errorf("cell (%d,%d) has value %d; want 0 to %d", x, y, v, order)
+			}
+		}
+		copy(input[y+2][1:], row)
+	}
+	for _, o := range d.Observers {
+		y, x, ok := obsSlot(o, d.Width, d.Height)
+		if !ok {
+			return nil, fmt.Errorf("observer at %s looking %s is not on the edge of the board", o.Start, o.Dir)
+		}
+		input[y][x] = o.Count
+	}
+	b, err := BoardFromLines(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	if len(d.Candidates) > 0 {
+		if err := b.RestrictCandidates(d.Candidates); err != nil {
+			return nil, err
+		}
+	}
+	b.Meta = board.Meta{Author: d.Author, Source: d.Source}
+	return b, nil
+}
+
+// obsSlot returns where BoardFromLines reads observer o from, as a row and
+// column of its input.
+func obsSlot(o puzzle.ObserverClue, w, h int) (int, int, bool) {
+	switch {
+	case o.Dir == grid.DOWN && o.Start.Y == 0 && o.Start.X >= 0 && o.Start.X < w:
+		return 1, o.Start.X + 1, true
+	case o.Dir == grid.UP && o.Start.Y == h-1 && o.Start.X >= 0 && o.Start.X < w:
+		return h + 2, o.Start.X + 1, true
+	case o.Dir == grid.RIGHT && o.Start.X == 0 && o.Start.Y >= 0 && o.Start.Y < h:
+		return o.Start.Y + 2, 0, true
+	case o.Dir == grid.LEFT && o.Start.X == w-1 && o.Start.Y >= 0 && o.Start.Y < h:
+		return o.Start.Y + 2, w + 1, true
+	}
+	return 0, 0, false
+}
+
+// Document returns the puzzle with its current cells and candidates.
+func (b *Board) Document() *puzzle.Document {
+	d := &puzzle.Document{
+		Type:       TypeName,
+		Width:      b.W,
+		Height:     b.H,
+		Author:     b.Meta.Author,
+		Source:     b.Meta.Source,
+		Order:      b.Order,
+		Cells:      b.Cells(),
+		Candidates: b.Candidates(),
+	}
+	for _, o := range b.Observers {
+		d.Observers = append(d.Observers, puzzle.ObserverClue{Start: o.Start, Dir: o.Direction, Count: o.Count})
+	}
+	d.Solved, _ = b.IsSolved()
+	return d
+}
@@ -0,0 +1,100 @@
+package towers
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// Lint returns every problem it finds in the lines of a text puzzle
+// without solving it, as grid.LineErrors where the problem has a place: a
+// missing or bad order line, the wrong number of lines, lines too long for
+// the order, heights and observer counts above the order, clues in the
+// corners, a height given twice in one row or column, and two observers
+// at the ends of one line that cannot both be right. A puzz.link URL is
+// left to BoardFromURL.
+func Lint(lines []string) []error {
+	if len(lines) == 0 {
+		return []error{fmt.Errorf("puzzle is empty")}
+	}
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return nil
+	}
+	input, err := linesToInput(lines)
+	if err != nil {
+		return []error{err}
+	}
+	if len(input[0]) != 1 || input[0][0] <= 0 {
+		msg := "first line must hold only the order"
+		// Without the order line, the lines are the border and the grid,
+		// which has two fewer rows.
+		if n := len(input); n >= 3 && len(input[0]) > 1 {
+			msg += fmt.Sprintf("; if it is missing, add a line holding %d", n-2)
+		}
+		return []error{grid.LineErrorf(1, 0, msg)}
+	}
+	errs := make([]error, 0)
+	order := input[0][0]
+	if len(input)-3 != order {
+		errs = append(errs, fmt.Errorf("order %d needs %d lines: the order, the top observers, %d rows and the bottom observers; have %d lines", order, order+3, order, len(input)))
+	}
+	last := len(input) - 1
+	at := func(y, x int) int {
+		if y < 1 || y > last || x >= len(input[y]) {
+			return 0
+		}
+		return input[y][x]
+	}
+	for y := 1; y <= last; y++ {
+		if len(input[y]) > order+2 {
+			errs = append(errs, grid.LineErrorf(y+1, 0, "%d cells; order %d allows %d with the observers", len(input[y]), order, order+2))
+		}
+		for x := 0; x < min(len(input[y]), order+2); x++ {
+			v := input[y][x]
+			edgeRow, edgeCol := y == 1 || y == last, x == 0 || x == order+1
+			switch {
+			case v == 0:
+			case edgeRow && edgeCol:
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "corner holds %d; corners have no observers", v))
+			case (edgeRow || edgeCol) && v > order:
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "observer sees %d towers; order %d allows at most %d", v, order, order))
+			case v > order:
+				errs = append(errs, grid.LineErrorf(y+1, x+1, "height %d is above the order, %d", v, order))
+			}
+		}
+	}
+
+	// Heights given twice, and observers facing each other across a line:
+	// the tallest tower is seen from both ends, so their counts add up to
+	// at most order+1, and only a line of one can have 1 at both ends.
+	type place struct{ y, x int }
+	for i := 1; i <= order; i++ {
+		inRow := make(map[int]place)
+		inCol := make(map[int]place)
+		for j := 1; j <= order; j++ {
+			if v := at(i+1, j); v != 0 && v <= order {
+				if p, ok := inRow[v]; ok {
+					errs = append(errs, grid.LineErrorf(i+2, j+1, "height %d is already in this row at column %d", v, p.x+1))
+				}
+				inRow[v] = place{i + 1, j}
+			}
+			if v := at(j+1, i); v != 0 && v <= order {
+				if p, ok := inCol[v]; ok {
+					errs = append(errs, grid.LineErrorf(j+2, i+1, "height %d is already in this column at line %d", v, p.y+1))
+				}
+				inCol[v] = place{j + 1, i}
+			}
+		}
+		for _, pair := range [][4]int{{i + 1, 0, i + 1, order + 1}, {1, i, last, i}} {
+			a, b := at(pair[0], pair[1]), at(pair[2], pair[3])
+			if a == 0 || b == 0 {
+				continue
+			}
+			if a+b > order+1 || (a == 1 && b == 1 && order > 1) {
+				errs = append(errs, grid.LineErrorf(pair[2]+1, pair[3]+1, "observers %d and %d at the two ends of this line cannot both be right", b, a))
+			}
+		}
+	}
+	return errs
+}
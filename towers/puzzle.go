@@ -0,0 +1,113 @@
+package towers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/puzzle"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// TypeName is the name the puzzle type is registered under.
+const TypeName = "towers"
+
+func init() {
+	puzzle.Register(puzzle.Type{
+		Name:        TypeName,
+		Description: "skyscrapers: fill a latin square so that every observer sees the given number of towers",
+		Pzpr:        "skyscrapers",
+		New:         func() puzzle.Puzzle { return &puzzleAdapter{} },
+		Detect:      looksLike,
+	})
+}
+
+// puzzleAdapter adapts Board to the puzzle.Puzzle interface.
+type puzzleAdapter struct {
+	*Board
+}
+
+// Parse reads a puzzle in the text format of boardFromText, after an
+// optional puzzle.Header.
+func (p *puzzleAdapter) Parse(lines []string) (err error) {
+	p.Board, err = puzzle.ParseText(TypeName, lines, boardFromText)
+	return err
+}
+
+// boardFromText reads a puzzle in the text format of BoardFromLines, one
+// character or one token (see grid.IsTokenGrid) per cell, or a single line
+// holding a puzz.link URL. In the tokenized format the corners of the
+// observer border need tokens too. Every problem Lint finds is returned,
+// joined with errors.Join.
+func boardFromText(lines []string) (*Board, error) {
+	if len(lines) == 1 && pzpr.IsURL(lines[0]) {
+		return BoardFromURL(lines[0])
+	}
+	if errs := Lint(lines); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	input, err := linesToInput(lines)
+	if err != nil {
+		return nil, err
+	}
+	return BoardFromLines(input)
+}
+
+// Lint lists the problems in a text puzzle after an optional
+// puzzle.Header; see the package function Lint.
+func (p *puzzleAdapter) Lint(lines []string) []error {
+	return puzzle.LintText(lines, Lint)
+}
+
+// linesToInput reads the text format of BoardFromLines into the numbers
+// BoardFromLines takes, by character or by token.
+func linesToInput(lines []string) ([][]int, error) {
+	if len(lines) > 1 && grid.IsTokenGrid(lines[1:]) {
+		return grid.TokensToIntGrid(lines)
+	}
+	return grid.LinesToIntGrid(lines)
+}
+
+// looksLike reports whether lines could be a towers puzzle: a lone number
+// on the first line, followed by that many rows and two lines of
+// observers.
+func looksLike(lines []string) bool {
+	if len(lines) < 4 {
+		return false
+	}
+	order, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	return err == nil && order == len(lines)-3
+}
+
+func (p *puzzleAdapter) ParseDocument(d *puzzle.Document) (err error) {
+	p.Board, err = BoardFromDocument(d)
+	return err
+}
+
+func (p *puzzleAdapter) Clone() puzzle.Puzzle {
+	return &puzzleAdapter{p.Board.Clone()}
+}
+
+func (p *puzzleAdapter) SearchContext(ctx context.Context) (bool, error) {
+	return board.SearchNum(ctx, p.Board)
+}
+
+func (p *puzzleAdapter) CountSolutionsContext(ctx context.Context, limit int) (int, []puzzle.Puzzle, error) {
+	n, witnesses, err := board.CountNum(ctx, p.Board, limit)
+	return n, puzzle.ToPuzzles(witnesses, func(b *Board) puzzle.Puzzle { return &puzzleAdapter{b} }), err
+}
+
+func (p *puzzleAdapter) ApplyState(lines []string) error {
+	return board.ApplyNumState(p.Board, lines)
+}
+
+func (p *puzzleAdapter) Hint() (*board.Hint, error) {
+	return board.HintNum(p.Board)
+}
+
+func (p *puzzleAdapter) RateContext(ctx context.Context) (*board.Rating, error) {
+	return board.RateNum(ctx, p.Board)
+}
@@ -0,0 +1,13 @@
+package towers
+
+import "github.com/bismuthsalamander/mutantcheckerboard/board"
+
+// Techniques lists the towers rules in increasing order of difficulty.
+func (b *Board) Techniques() []board.Technique {
+	out := []board.Technique{
+		{Name: "MarkMandatory", Cost: 1, Apply: b.MarkMandatory},
+		board.PlainTechnique("TrimAllowedFromPerms", 2, b.TrimAllowedFromPerms),
+		board.PlainTechnique("TrimPermsFromAllowed", 2, b.TrimPermsFromAllowed),
+	}
+	return append(out, b.SetTechniques()...)
+}
@@ -0,0 +1,37 @@
+package towers
+
+import (
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/render"
+)
+
+// Scene describes the board for the renderers: the values and candidates of
+// the cells, with the observers in the margin.
+func (b *Board) Scene() *render.Scene {
+	s := render.NewScene(b.W, b.H)
+	s.Meta = b.Meta
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		cell := s.At(c)
+		cell.Value = b.Get(c)
+		if cell.Value == grid.UNKNOWN {
+			cell.Candidates = b.Allowed[c.Y][c.X].Sorted()
+		}
+	}
+	s.Top = make([]int, b.W)
+	s.Bottom = make([]int, b.W)
+	s.Left = make([]int, b.H)
+	s.Right = make([]int, b.H)
+	for _, o := range b.Observers {
+		switch o.Direction {
+		case grid.DOWN:
+			s.Top[o.Start.X] = o.Count
+		case grid.UP:
+			s.Bottom[o.Start.X] = o.Count
+		case grid.RIGHT:
+			s.Left[o.Start.Y] = o.Count
+		case grid.LEFT:
+			s.Right[o.Start.Y] = o.Count
+		}
+	}
+	return s
+}
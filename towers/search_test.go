@@ -0,0 +1,27 @@
+package towers
+
+import (
+	"testing"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board/boardtest"
+)
+
+func load(lines []string) (*Board, error) {
+	input, err := linesToInput(lines)
+	if err != nil {
+		return nil, err
+	}
+	return BoardFromLines(input)
+}
+
+func TestSearch(t *testing.T) {
+	boardtest.CheckNum(t, load, []boardtest.Case{
+		{Name: "order 1", Lines: []string{"1", "", "", ""}},
+		{Name: "order 1 given", Lines: []string{"1", "   ", " 1 ", "   "}},
+		{Name: "latin squares", Lines: []string{"3", "", "", "", "", ""}},
+		{Name: "one observer", Lines: []string{"3", " 3", "", "", "", ""}},
+		{Name: "given", Lines: []string{"3", "", " 2", "", "", ""}},
+		{Name: "unique", Lines: []string{"4", " 3214 ", "323  2", "2 4 22", "14  32", "4  3 1", " 2221 "}},
+		{Name: "no solution", Lines: []string{"3", " 3 ", "", "", "", " 2 "}},
+	})
+}
@@ -0,0 +1,615 @@
+// Package towers solves skyscrapers puzzles. Every row and column of an
+// Order by Order grid holds each height from 1 to Order once, and an
+// observer outside the grid sees the number of towers that are not hidden
+// behind a taller one.
+package towers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/board"
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/perms"
+)
+
+// Board is a skyscrapers puzzle. RowPerms and ColPerms hold the
+// permutations of each line that are still possible.
+type Board struct {
+	board.RectNumBoard
+	Order     int
+	Observers []*Observer
+	ObsSorted []*Observer
+	Perms     [][]int
+	RowPerms  []*[]int
+	ColPerms  []*[]int
+}
+
+// Observer is a clue outside the grid that looks from Start in Direction
+// and sees Count towers.
+type Observer struct {
+	Start     grid.Coord
+	Direction grid.Delta
+	Count     int
+}
+
+func (o Observer) IsRow() bool {
+	return o.Direction.X != 0
+}
+
+func (o Observer) IsCol() bool {
+	return o.Direction.Y != 0
+}
+
+func (o Observer) IsBackwards() bool {
+	return o.Direction.X < 0 || o.Direction.Y < 0
+}
+
+func (o Observer) IsForwards() bool {
+	return !o.IsBackwards()
+}
+
+// BoardFromLines reads a puzzle from its order on the first line followed
+// by the grid with a border of observers around it. Lines may stop short,
+// as when an editor trims trailing blanks; what is missing is empty. The
+// grid must be Order cells on a side.
+func BoardFromLines(input [][]int) (*Board, error) {
+	if len(input) < 1 || len(input[0]) != 1 {
+		return nil, grid.LineErrorf(1, 0, "first line must hold only the order")
+	}
+	order := input[0][0]
+	if order <= 0 {
+		return nil, fmt.Errorf("order must be >= 1; got %d", order)
+	}
+	if len(input)-3 != order {
+		return nil, fmt.Errorf("order %d needs %d lines: the order, the top observers, %d rows and the bottom observers; have %d lines", order, order+3, order, len(input))
+	}
+	for idx, line := range input[1:] {
+		if len(line) > order+2 {
+			return nil, grid.LineErrorf(idx+2, 0, "%d cells; order %d allows %d with the observers", len(line), order, order+2)
+		}
+	}
+	// Pad every line to the full width so that missing observers read as 0.
+	padded := make([][]int, len(input))
+	padded[0] = input[0]
+	for idx, line := range input[1:] {
+		padded[idx+1] = make([]int, order+2)
+		copy(padded[idx+1], line)
+	}
+	input = padded
+	boardLines := make([][]int, 0, order)
+	for _, line := range input[2 : len(input)-1] {
+		boardLines = append(boardLines, line[1:len(line)-1])
+	}
+	rect := board.RectNumBoardFromNums(boardLines)
+	allObservers := make([]*Observer, 0, 2*(rect.W+rect.H))
+	obsSorted := make([]*Observer, 2*(rect.W+rect.H))
+	b := Board{
+		RectNumBoard: *rect,
+		Order:        order,
+		Observers:    allObservers,
+		ObsSorted:    obsSorted,
+		Perms:        perms.PermuteN(order),
+		RowPerms:     make([]*[]int, rect.H),
+		ColPerms:     make([]*[]int, rect.W),
+	}
+	b.Allowed = board.MakeAllowedSets(rect.W, rect.H, order)
+	// row obs
+	for ri := 0; ri < b.H; ri++ {
+		b.AddObs(grid.Coord{X: 0, Y: ri}, grid.Delta{X: 1, Y: 0}, input[ri+2][0])
+		b.AddObs(grid.Coord{X: b.W - 1, Y: ri}, grid.Delta{X: -1, Y: 0}, input[ri+2][b.W+1])
+	}
+	// col obs
+	for ci := 0; ci < b.W; ci++ {
+		b.AddObs(grid.Coord{X: ci, Y: 0}, grid.Delta{X: 0, Y: 1}, input[1][ci+1])
+		b.AddObs(grid.Coord{X: ci, Y: b.H - 1}, grid.Delta{X: 0, Y: -1}, input[b.H+2][ci+1])
+	}
+	// init perms
+	b.PopulateRowColPerms()
+	b.Inited = true
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if _, err := b.PostMark(c, b.Get(c)); err != nil {
+			return nil, err
+		}
+	}
+	return &b, nil
+}
+
+// Lines returns the puzzle in the text format of BoardFromLines, as
+// linesToInput reads it: the order, then the filled cells inside a border
+// of observers. Every filled cell is written as a given, as in URL.
+func (b *Board) Lines() []string {
+	rows := grid.MakeNumGrid(b.W+2, b.H+2)
+	for x := 0; x < b.W; x++ {
+		rows[0][x+1] = max(b.obsCount(grid.Coord{X: x, Y: 0}, grid.DOWN), 0)
+		rows[b.H+1][x+1] = max(b.obsCount(grid.Coord{X: x, Y: b.H - 1}, grid.UP), 0)
+	}
+	for y := 0; y < b.H; y++ {
+		rows[y+1][0] = max(b.obsCount(grid.Coord{X: 0, Y: y}, grid.RIGHT), 0)
+		rows[y+1][b.W+1] = max(b.obsCount(grid.Coord{X: b.W - 1, Y: y}, grid.LEFT), 0)
+		for x := 0; x < b.W; x++ {
+			rows[y+1][x+1] = b.Get(grid.Coord{X: x, Y: y})
+		}
+	}
+	return append([]string{strconv.Itoa(b.Order)}, grid.NumGridToLines(rows, grid.UNKNOWN)...)
+}
+
+func (b *Board) PostMark(c grid.Coord, v int) (bool, error) {
+	if v == grid.UNKNOWN {
+		return false, nil
+	}
+	changed := false
+	for _, region := range b.RegionGrid[c.Y][c.X] {
+		for _, neighbor := range *region {
+			if neighbor == c || !b.Allowed[neighbor.Y][neighbor.X].Has(v) {
+				continue
+			}
+			if _, err := b.Eliminate(neighbor, v, "PostMark"); err != nil {
+				return changed, err
+			}
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func (b *Board) PopulateRowColPerms() {
+	pi := 0
+	for ri := 0; ri < b.H; ri++ {
+		b.RowPerms[ri] = b.PermsForObs(b.ObsSorted[pi], b.ObsSorted[pi+1])
+		pi += 2
+	}
+	for ci := 0; ci < b.W; ci++ {
+		b.ColPerms[ci] = b.PermsForObs(b.ObsSorted[pi], b.ObsSorted[pi+1])
+		pi += 2
+	}
+}
+
+func (b *Board) AddObs(start grid.Coord, d grid.Delta, ct int) *Observer {
+	idx := b.ObsIndex(start, d)
+	if ct == 0 {
+		return nil
+	}
+	o := Observer{
+		Start:     start,
+		Direction: d,
+		Count:     ct,
+	}
+
+	b.ObsSorted[idx] = &o
+	b.Observers = append(b.Observers, &o)
+	return &o
+}
+
+func (b *Board) ObsIndex(start grid.Coord, d grid.Delta) int {
+	// Order is row 0 fwd, row 0 bwd, ... row n-1 fwd, row n-1 bwd
+	// Then col 0 fwd, col 0 bwd, ...
+	var idx int
+	if d.Y == 0 { // column
+		idx = start.Y * 2
+		if d.X == -1 {
+			idx++
+		}
+	} else { // row
+		idx = (start.X + b.H) * 2
+		if d.Y == -1 {
+			idx++
+		}
+	}
+	return idx
+}
+
+// PermsForObs generates a slice of the permutation indexes that fit both
+// observers. If both are nil, returns nil. Must be called after b.Perms has
+// been initialized.
+func (b *Board) PermsForObs(fwd, bwd *Observer) *[]int {
+	if fwd == nil && bwd == nil {
+		return nil
+	}
+	out := make([]int, 0)
+	for i := 0; i < len(b.Perms); i++ {
+		if PermFitsObs(b.Perms[i], fwd, bwd) {
+			out = append(out, i)
+		}
+	}
+	return &out
+}
+
+// PermFitsObs checks whether a given row or column is consistent with both
+// observers. Nil inputs are ignored, so PermFitsObs(_, nil, nil) always
+// returns true.
+func PermFitsObs(p []int, fwd, bwd *Observer) bool {
+	if fwd != nil {
+		vis := 0
+		highest := 0
+		for i := 0; i < len(p); i++ {
+			if p[i] > highest {
+				highest = p[i]
+				vis++
+				if vis > fwd.Count {
+					return false
+				}
+			}
+		}
+		if vis != fwd.Count {
+			return false
+		}
+	}
+	if bwd != nil {
+		vis := 0
+		highest := 0
+		for i := len(p) - 1; i >= 0; i-- {
+			if p[i] > highest {
+				highest = p[i]
+				vis++
+				if vis > bwd.Count {
+					return false
+				}
+			}
+		}
+		if vis != bwd.Count {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the board that can be solved or guessed on without
+// affecting the original. Observers and the master permutation list never
+// change after loading, so they are shared; the per-line permutation lists
+// are copied because the trimming rules replace them.
+func (b *Board) Clone() *Board {
+	return &Board{
+		RectNumBoard: *b.RectNumBoard.Clone(),
+		Order:        b.Order,
+		Observers:    b.Observers,
+		ObsSorted:    b.ObsSorted,
+		Perms:        b.Perms,
+		RowPerms:     copyPermLists(b.RowPerms),
+		ColPerms:     copyPermLists(b.ColPerms),
+	}
+}
+
+// setPerms replaces lists[i], one of RowPerms or ColPerms, with perms and
+// records how to undo it. The old list is left untouched, so Clone and
+// Rollback never see a list that changed under them.
+func (b *Board) setPerms(lists []*[]int, i int, perms *[]int) {
+	old := lists[i]
+	b.OnRollback(func() { lists[i] = old })
+	lists[i] = perms
+}
+
+func copyPermLists(lists []*[]int) []*[]int {
+	out := make([]*[]int, len(lists))
+	for i, l := range lists {
+		if l == nil {
+			continue
+		}
+		tmp := append([]int(nil), (*l)...)
+		out[i] = &tmp
+	}
+	return out
+}
+
+// Validate returns an error if the board's current contents already rule out
+// every solution: a region contradiction, or a row or column with no
+// remaining permutation that fits its observers.
+func (b *Board) Validate() error {
+	if err := b.RectNumBoard.Validate(); err != nil {
+		return err
+	}
+	for ri, rp := range b.RowPerms {
+		if rp != nil && len(*rp) == 0 {
+			return board.Contradiction(grid.Coord{X: 0, Y: ri}, "Validate", "row %d has no permutation left that fits its observers", ri)
+		}
+	}
+	for ci, cp := range b.ColPerms {
+		if cp != nil && len(*cp) == 0 {
+			return board.Contradiction(grid.Coord{X: ci, Y: 0}, "Validate", "column %d has no permutation left that fits its observers", ci)
+		}
+	}
+	return nil
+}
+
+// ObsChar is a helper function that locates the observer specified by the
+// t(ype), index and direction parameters, then returns a string to be
+// displayed in the board string.
+func (b *Board) ObsChar(start grid.Coord, d grid.Delta) string {
+	return b.obsCell(grid.CellFormat{}, start, d)
+}
+
+// obsCell is ObsChar in the cell format f.
+func (b *Board) obsCell(f grid.CellFormat, start grid.Coord, d grid.Delta) string {
+	idx := b.ObsIndex(start, d)
+	o := b.ObsSorted[idx]
+	if o == nil {
+		return blankCell(f)
+	}
+	return f.Num(o.Count)
+}
+
+// blankCell is an empty cell in format f: a space, or a dot when the wide
+// format needs something to keep the columns apart.
+func blankCell(f grid.CellFormat) string {
+	if f.Wide() {
+		return f.Mark('.')
+	}
+	return f.Mark(' ')
+}
+
+// String draws the grid with its observers around it. An order or an
+// observer above 35 switches every cell to the wide grid.CellFormat.
+func (b *Board) String() string {
+	largest := b.Order
+	for _, o := range b.Observers {
+		largest = max(largest, o.Count)
+	}
+	f := grid.FormatFor(largest)
+	out := blankCell(f)
+	for ci := 0; ci < b.W; ci++ {
+		out += b.obsCell(f, grid.Coord{X: ci, Y: 0}, grid.Delta{X: 0, Y: 1})
+	}
+	if f.Wide() {
+		out += blankCell(f)
+	}
+	out += "\n"
+	for ri := 0; ri < b.H; ri++ {
+		out += b.obsCell(f, grid.Coord{X: 0, Y: ri}, grid.Delta{X: 1, Y: 0})
+		for ci := 0; ci < b.W; ci++ {
+			out += f.Num(b.Get(grid.Coord{X: ci, Y: ri}))
+		}
+		out += b.obsCell(f, grid.Coord{X: b.W - 1, Y: ri}, grid.Delta{X: -1, Y: 0})
+		out += "\n"
+	}
+	out += blankCell(f)
+	for ci := 0; ci < b.W; ci++ {
+		out += b.obsCell(f, grid.Coord{X: ci, Y: b.H - 1}, grid.Delta{X: 0, Y: -1})
+	}
+	if f.Wide() {
+		out += blankCell(f)
+	}
+	return out
+}
+
+// Solved returns true iff all observers are satisfied and all cells are
+// filled. As of now, it does not confirm that the sudoku rule is satisfied.
+func (b *Board) IsSolved() (bool, error) {
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if b.Get(c) == grid.UNKNOWN {
+			return false, fmt.Errorf("cell %s is unknown", c)
+		}
+	}
+	for _, o := range b.Observers {
+		if ok, num := b.ObserverSatisfied(o); !ok {
+			return false, fmt.Errorf("observer %v unsatisfied (want %d, see %d)", o, o.Count, num)
+		}
+	}
+	for _, r := range b.AllRegions {
+		if !b.IsRegionSolved(*r) {
+			return false, fmt.Errorf("region %v unsatisfied", *r)
+		}
+	}
+	return true, nil
+}
+
+// ObserverSatisfied returns false if the grid's contents are consistent with
+// the constraint for the specified observer. This function will treat empty
+// cells as a zero (meaning that such cells are never visible and never
+// obstruct other cells), so the return value may be misleading if called when
+// the relevant row or column is incomplete.
+func (b *Board) ObserverSatisfied(o *Observer) (bool, int) {
+	vis := 0
+	highest := 0
+	for c := o.Start; b.IsValid(c); c = c.Plus(o.Direction) {
+		val := b.Get(c)
+		if val > highest {
+			vis++
+			highest = val
+		}
+	}
+	return vis == o.Count, vis
+}
+
+// MarkMandatory searches for cells with only one entry in Allowed and marks
+// the appropriate value. Returns true iff a change was made. The redo flag
+// repeats the loop if marking a mandatory cell eliminated entries in Allowed
+// for other cells in the same row or column. If, for example, cell (a, b) is
+// the last empty cell in its row and column, then marking (a, b) will not
+// eliminate any possibilities from other cells, and repeating the loop is not
+// necessary. Returns a ContradictionError if marking a cell leaves another
+// cell with no candidates.
+func (b *Board) MarkMandatory() (bool, error) {
+	changed := false
+	redo := false
+	var markErr error
+	b.EachCell(func(c grid.Coord, v int) bool {
+		allowed := b.Allowed[c.Y][c.X]
+		if len(allowed.M) != 1 || b.Get(c) != grid.UNKNOWN {
+			return false
+		}
+		k := allowed.GetOne()
+		b.BeginStep("MarkMandatory", c)
+		res, err := b.Mark(c, k)
+		b.EndStep()
+		if err != nil {
+			markErr = err
+			return true
+		}
+		if res {
+			changed = true
+		}
+		return false
+	})
+	if markErr != nil {
+		return changed, markErr
+	}
+	if redo {
+		return b.MarkMandatory()
+	}
+	return changed, nil
+}
+
+// TrimPermsFromAllowed removes entries in RowPerns and ColPerms that are not
+// possible because they would violate the Allowed maps. Returns true iff any
+// changes were made.
+func (b *Board) TrimPermsFromAllowed() bool {
+	changed := false
+	for ri, rp := range b.RowPerms {
+		if rp == nil {
+			continue
+		}
+		newPerms := make([]int, 0, len(*rp))
+		for _, pi := range *rp {
+			isPermOk := true
+			for ci := 0; ci < b.W; ci++ {
+				if !b.IsAllowed(grid.Coord{X: ci, Y: ri}, b.Perms[pi][ci]) {
+					isPermOk = false
+					break
+				}
+			}
+			if isPermOk {
+				newPerms = append(newPerms, pi)
+			}
+		}
+		if len(*rp) != len(newPerms) {
+			b.BeginStep("TrimPermsFromAllowed", b.lineCells(true, ri)...)
+			b.setPerms(b.RowPerms, ri, &newPerms)
+			b.StepPerms(true, ri, len(newPerms))
+			b.EndStep()
+			changed = true
+		}
+	}
+	for ci, cp := range b.ColPerms {
+		if cp == nil {
+			continue
+		}
+		newPerms := make([]int, 0, len(*cp))
+		for _, pi := range *cp {
+			isPermOk := true
+			for ri := 0; ri < b.H; ri++ {
+				if !b.IsAllowed(grid.Coord{X: ci, Y: ri}, b.Perms[pi][ri]) {
+					isPermOk = false
+					break
+				}
+			}
+			if isPermOk {
+				newPerms = append(newPerms, pi)
+			}
+		}
+		if len(*cp) != len(newPerms) {
+			b.BeginStep("TrimPermsFromAllowed", b.lineCells(false, ci)...)
+			b.setPerms(b.ColPerms, ci, &newPerms)
+			b.StepPerms(false, ci, len(newPerms))
+			b.EndStep()
+			changed = true
+		}
+	}
+	return changed
+}
+
+// lineCells returns the cells of row or column idx, in order.
+func (b *Board) lineCells(row bool, idx int) []grid.Coord {
+	out := make([]grid.Coord, 0, max(b.W, b.H))
+	if row {
+		for c := (grid.Coord{X: 0, Y: idx}); b.IsValid(c); c.X++ {
+			out = append(out, c)
+		}
+	} else {
+		for c := (grid.Coord{X: idx, Y: 0}); b.IsValid(c); c.Y++ {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// TrimAllowedFromPerms will eliminate a permutation from RowPerms or ColPerms
+// if it is inconsistent with any cell's Allowed list. Returns true iff at
+// least one permutation was eliminated.
+func (b *Board) TrimAllowedFromPerms() bool {
+	changed := false
+	for ri := 0; ri < b.H; ri++ {
+		for ci := 0; ci < b.W; ci++ {
+			b.BeginStep("TrimAllowedFromPerms", grid.Coord{X: ci, Y: ri})
+			for _, n := range b.Allowed[ri][ci].Sorted() {
+				//Is n allowed in slot ci in a perm for row ri?
+				found := b.RowPerms[ri] == nil
+				if b.RowPerms[ri] != nil {
+					for _, permI := range *b.RowPerms[ri] {
+						if b.Perms[permI][ci] == n {
+							found = true
+							break
+						}
+					}
+				}
+				if !found {
+					b.Disallow(grid.Coord{X: ci, Y: ri}, n)
+					changed = true
+					continue
+				}
+				//Is n allowed in slot ri in a perm for col ci?
+				found = false
+				if b.ColPerms[ci] != nil {
+					for _, permI := range *b.ColPerms[ci] {
+						if b.Perms[permI][ri] == n {
+							found = true
+							break
+						}
+					}
+					if !found {
+						b.Disallow(grid.Coord{X: ci, Y: ri}, n)
+						changed = true
+					}
+				}
+			}
+			b.EndStep()
+		}
+	}
+	return changed
+}
+
+// AutoSolve runs all implemented solving heuristics until the puzzle is solved
+// or we run out of improvements. Missing heuristics include the opposite of
+// naked sets (i.e., cells X and Y are the only possible locations for numbers
+// N and M, so X and Y can't have any other numbers) and pairwise permutation
+// consistency between rows or columns.
+//
+// Returns a ContradictionError if the board turns out to have no solution.
+func (b *Board) Solve() error {
+	return b.SolveContext(context.Background())
+}
+
+// SolveContext is Solve, but gives up once ctx is done, checking between one
+// rule and the next. It then returns an error wrapping board.ErrTimedOut and
+// leaves the board holding every deduction made so far.
+func (b *Board) SolveContext(ctx context.Context) error {
+	changed := true
+	for changed {
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		if done, _ := b.IsComplete(); done {
+			break
+		}
+		changed = false
+		for _, rule := range []func() (bool, error){
+			b.MarkMandatory,
+			func() (bool, error) { return b.TrimAllowedFromPerms(), nil },
+			func() (bool, error) { return b.TrimPermsFromAllowed(), nil },
+			func() (bool, error) { return b.TrimAllFoundGroupsContext(ctx) },
+			func() (bool, error) { return b.TrimAllNakedSetsContext(ctx) },
+		} {
+			if err := board.Stopped(ctx); err != nil {
+				return err
+			}
+			ok, err := rule()
+			if err != nil {
+				return err
+			}
+			if ok {
+				changed = true
+			}
+		}
+	}
+	return b.Validate()
+}
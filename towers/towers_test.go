@@ -0,0 +1,23 @@
+package towers
+
+import "testing"
+
+// TestLintAgreesWithLoad checks that every puzzle Lint passes loads, since
+// LintFile only loads a text puzzle once Lint finds nothing wrong with it.
+func TestLintAgreesWithLoad(t *testing.T) {
+	for _, lines := range [][]string{
+		{"1", "", "", ""},
+		{"1", "   ", " 1 ", "   "},
+		{"1", " 1 ", "1 1", " 1 "},
+		{"3", "", "", "", "", ""},
+		{"4", " 3214 ", "323  2", "2 4 22", "14  32", "4  3 1", " 2221 "},
+	} {
+		if errs := Lint(lines); len(errs) > 0 {
+			t.Errorf("Lint(%q) = %v", lines, errs)
+			continue
+		}
+		if _, err := load(lines); err != nil {
+			t.Errorf("Lint passes %q, but the load says %v", lines, err)
+		}
+	}
+}
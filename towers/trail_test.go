@@ -0,0 +1,63 @@
+package towers
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRollback checks that rolling back nested checkpoints restores the
+// cells, the candidates and the permutations left for every row and
+// column.
+func TestRollback(t *testing.T) {
+	b, err := load([]string{"4", " 3214 ", "323  2", "2 4 22", "14  32", "4  3 1", " 2221 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := b.Clone()
+
+	b.Checkpoint()
+	b.TrimPermsFromAllowed()
+	b.TrimAllowedFromPerms()
+	outer := b.Clone()
+	if sameState(b, before) {
+		t.Fatal("the trims changed nothing, so the test proves nothing")
+	}
+
+	b.Checkpoint()
+	if err := b.Solve(); err != nil {
+		t.Fatal(err)
+	}
+	if solved, err := b.IsSolved(); !solved {
+		t.Fatalf("not solved: %v\n%s", err, b)
+	}
+	b.Rollback()
+	if !sameState(b, outer) {
+		t.Errorf("inner rollback left\n%s\nwant\n%s", b, outer)
+	}
+
+	b.Rollback()
+	if !sameState(b, before) {
+		t.Errorf("outer rollback left\n%s\nwant\n%s", b, before)
+	}
+}
+
+func sameState(a, b *Board) bool {
+	if !reflect.DeepEqual(a.Grid, b.Grid) || !reflect.DeepEqual(a.Guess, b.Guess) {
+		return false
+	}
+	for c := a.TopLeft(); a.IsValid(c); c = a.Next(c) {
+		if !reflect.DeepEqual(a.Allowed[c.Y][c.X].Sorted(), b.Allowed[c.Y][c.X].Sorted()) {
+			return false
+		}
+	}
+	return samePerms(a.RowPerms, b.RowPerms) && samePerms(a.ColPerms, b.ColPerms)
+}
+
+func samePerms(a, b []*[]int) bool {
+	for i := range a {
+		if !reflect.DeepEqual(*a[i], *b[i]) {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
@@ -0,0 +1,104 @@
+package towers
+
+import (
+	"fmt"
+
+	"github.com/bismuthsalamander/mutantcheckerboard/grid"
+	"github.com/bismuthsalamander/mutantcheckerboard/pzpr"
+)
+
+// BoardFromURL reads a puzzle from a pzprjs "skyscrapers" URL: the
+// observers above, below, left of and right of the grid, then any givens.
+func BoardFromURL(s string) (*Board, error) {
+	u, err := pzpr.ParseURL(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Type != "skyscrapers" {
+		return nil, fmt.Errorf("puzzle URL is for %q, not skyscrapers", u.Type)
+	}
+	w, h := u.Cols, u.Rows
+	obs, rest, err := pzpr.DecodeNumber16(u.Body, 2*w+2*h)
+	if err != nil {
+		return nil, err
+	}
+	cells, _, err := pzpr.DecodeNumber16(rest, w*h)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range append(obs, cells...) {
+		if n == pzpr.Question {
+			return nil, fmt.Errorf("question marks are not supported")
+		}
+	}
+	// Lay the clues out the way BoardFromLines expects them: the order,
+	// then the grid inside a border of observers.
+	input := make([][]int, 0, h+3)
+	input = append(input, []int{max(w, h)})
+	for y := 0; y < h+2; y++ {
+		input = append(input, make([]int, w+2))
+	}
+	for x := 0; x < w; x++ {
+		input[1][x+1] = max(obs[x], 0)
+		input[h+2][x+1] = max(obs[w+x], 0)
+	}
+	for y := 0; y < h; y++ {
+		input[y+2][0] = max(obs[2*w+y], 0)
+		input[y+2][w+1] = max(obs[2*w+h+y], 0)
+		for x := 0; x < w; x++ {
+			input[y+2][x+1] = max(cells[y*w+x], 0)
+		}
+	}
+	b, err := BoardFromLines(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := grid.LintLines(Lint, b.Lines()); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// URL returns the puzzle's observers and filled cells as a puzz.link URL.
+// Every filled cell is written as a given, so the URL of a board that has
+// been solved gives the solution away.
+func (b *Board) URL() string {
+	obs := make([]int, 0, 2*b.W+2*b.H)
+	for x := 0; x < b.W; x++ {
+		obs = append(obs, b.obsCount(grid.Coord{X: x, Y: 0}, grid.DOWN))
+	}
+	for x := 0; x < b.W; x++ {
+		obs = append(obs, b.obsCount(grid.Coord{X: x, Y: b.H - 1}, grid.UP))
+	}
+	for y := 0; y < b.H; y++ {
+		obs = append(obs, b.obsCount(grid.Coord{X: 0, Y: y}, grid.RIGHT))
+	}
+	for y := 0; y < b.H; y++ {
+		obs = append(obs, b.obsCount(grid.Coord{X: b.W - 1, Y: y}, grid.LEFT))
+	}
+	body := pzpr.EncodeNumber16(obs)
+	cells := make([]int, 0, b.W*b.H)
+	given := false
+	for c := b.TopLeft(); b.IsValid(c); c = b.Next(c) {
+		if v := b.Get(c); v != grid.UNKNOWN {
+			cells = append(cells, v)
+			given = true
+		} else {
+			cells = append(cells, pzpr.Empty)
+		}
+	}
+	if given {
+		body += pzpr.EncodeNumber16(cells)
+	}
+	u := pzpr.URL{Type: "skyscrapers", Cols: b.W, Rows: b.H, Body: body}
+	return u.String()
+}
+
+// obsCount returns the count of the observer at start looking in d, or
+// pzpr.Empty if there is none.
+func (b *Board) obsCount(start grid.Coord, d grid.Delta) int {
+	if o := b.ObsSorted[b.ObsIndex(start, d)]; o != nil {
+		return o.Count
+	}
+	return pzpr.Empty
+}